@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// runExport writes every entry matching the flags as CSV or JSON Lines.
+func runExport(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("export", "[flags]", stderr)
+	var (
+		conn    connFlags
+		params  nfo.QueryParams
+		success boolFlag
+		level   levelFlag
+	)
+	conn.register(fs)
+	fs.StringVar(&params.Cmd, "cmd", "", "only entries for this command")
+	fs.StringVar(&params.Env, "env", "", "only entries from this environment")
+	fs.Var(&success, "success", "only successful (true) or failed (false) entries")
+	fs.Var(&level, "level", "only entries at this level")
+	fs.StringVar(&params.CorrelationID, "correlation-id", "", "only entries of this job or request")
+	since := fs.String("since", "", "only entries after this time (1h, or RFC 3339)")
+	until := fs.String("until", "", "only entries before this time (1h, or RFC 3339)")
+	fs.IntVar(&params.Limit, "page-size", nfo.DefaultPageSize, "entries fetched per request")
+	formatName := fs.String("format", "csv", "output format: csv or jsonl")
+	output := fs.String("o", "", "write to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	format, ok := nfo.ExportFormatByName(*formatName)
+	if !ok {
+		fmt.Fprintf(stderr, "nfo export: unknown format %q, want csv or jsonl\n", *formatName)
+		return 2
+	}
+	now := time.Now()
+	var err error
+	if params.Since, err = parseTime(*since, now); err == nil {
+		params.Until, err = parseTime(*until, now)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo export: %v\n", err)
+		return 2
+	}
+	params.Success = success.v
+	params.Level = level.Level
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "nfo export: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+	n, err := conn.client().Export(ctx, params, w, format)
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo export: %v (after %d entries)\n", err, n)
+		return 1
+	}
+	if *output != "" {
+		fmt.Fprintf(stderr, "exported %d entries to %s\n", n, *output)
+	}
+	return 0
+}
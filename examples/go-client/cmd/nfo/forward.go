@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfoserver"
+)
+
+// runForward accepts entries on a local address and relays them to the
+// nfo-service at --url until interrupted, for edge hosts and sidecars:
+//
+//	nfo forward --listen :8080 --url https://nfo.example.com --spill-dir /var/lib/nfo
+func runForward(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("forward", "[flags]", stderr)
+	var conn connFlags
+	conn.register(fs)
+	listen := fs.String("listen", ":8080", "address to accept entries on")
+	spillDir := fs.String("spill-dir", "", "keep entries on disk while upstream is unreachable")
+	keep := fs.Int("keep", 0, "also keep this many recent entries for local queries")
+	interval := fs.Duration("flush-interval", time.Second, "how often entries are sent upstream")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	f, err := nfoserver.NewForwarder(conn.client(nfo.WithoutMetadata()), nfoserver.ForwarderConfig{
+		FlushInterval: *interval,
+		SpillDir:      *spillDir,
+		ErrorHandler:  func(err error) { fmt.Fprintf(stderr, "nfo forward: %v\n", err) },
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo forward: %v\n", err)
+		return 1
+	}
+	cfg := nfoserver.Config{Forwarder: f, Version: "nfo-forward"}
+	if *keep > 0 {
+		cfg.Store = nfoserver.NewMemoryStore(*keep)
+	}
+
+	ln, err := net.Listen("tcp", *listen)
+	if err != nil {
+		f.Close(ctx)
+		fmt.Fprintf(stderr, "nfo forward: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "nfo forward: %s -> %s\n", ln.Addr(), conn.url)
+	srv := &http.Server{Handler: nfoserver.NewHandler(cfg), ReadHeaderTimeout: 10 * time.Second}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+
+	code := 0
+	select {
+	case <-ctx.Done():
+	case err := <-served:
+		fmt.Fprintf(stderr, "nfo forward: %v\n", err)
+		code = 1
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), conn.timeout)
+	defer cancel()
+	srv.Shutdown(shutdown)
+	report, err := f.Close(shutdown)
+	if err != nil && !errors.Is(err, nfo.ErrClosed) {
+		fmt.Fprintf(stderr, "nfo forward: %v\n", err)
+		code = 1
+	}
+	if report.Spilled > 0 || report.Dropped > 0 {
+		fmt.Fprintf(stderr, "nfo forward: %d entries spilled, %d dropped on shutdown\n", report.Spilled, report.Dropped)
+	}
+	return code
+}
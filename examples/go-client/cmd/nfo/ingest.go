@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfotail"
+)
+
+// runIngest follows log files and ships each new line as an entry until
+// interrupted, for programs that only write files:
+//
+//	nfo ingest --format logfmt --env prod '/var/log/app/*.log'
+func runIngest(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("ingest", "[flags] FILE|GLOB...", stderr)
+	var (
+		conn  connFlags
+		field listFlag
+	)
+	conn.register(fs)
+	env := fs.String("env", getEnv("NFO_ENV", "prod"), "environment")
+	fs.Var(&field, "field", "structured field as key=value; repeat for several")
+	format := fs.String("format", "plain", "line format: plain, json, logfmt or regex")
+	pattern := fs.String("pattern", "", "regular expression with named groups, for --format regex")
+	fromStart := fs.Bool("from-start", false, "read existing lines too, not only new ones")
+	poll := fs.Duration("poll", 250*time.Millisecond, "how often files are checked")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "nfo ingest: missing files")
+		fs.Usage()
+		return 2
+	}
+	fields, err := field.fields()
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo ingest: %v\n", err)
+		return 2
+	}
+	var parser nfotail.Parser
+	switch *format {
+	case "plain":
+		parser = nfotail.Plain
+	case "json":
+		parser = nfotail.JSON
+	case "logfmt":
+		parser = nfotail.Logfmt
+	case "regex":
+		parser, err = nfotail.Regex(*pattern)
+	default:
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo ingest: %v\n", err)
+		return 2
+	}
+
+	report := func(err error) { fmt.Fprintf(stderr, "nfo ingest: %v\n", err) }
+	client := nfo.NewAsyncClient(conn.client(nfo.WithEnv(*env), nfo.WithFields(fields)), nfo.AsyncConfig{
+		Overflow:     nfo.Block,
+		ErrorHandler: report,
+	})
+	tailer, err := nfotail.New(client, nfotail.Config{
+		Paths:        fs.Args(),
+		Parser:       parser,
+		FromStart:    *fromStart,
+		PollInterval: *poll,
+		OnError:      report,
+	})
+	if err != nil {
+		client.Close()
+		fmt.Fprintf(stderr, "nfo ingest: %v\n", err)
+		return 2
+	}
+	tailer.Run(ctx)
+
+	shutdown, cancel := context.WithTimeout(context.Background(), conn.timeout)
+	defer cancel()
+	if _, err := client.CloseContext(shutdown); err != nil {
+		fmt.Fprintf(stderr, "nfo ingest: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,228 @@
+// Command nfo sends and queries nfo-service logs from shell scripts and CI
+// without writing Go.
+//
+// Usage:
+//
+//	nfo send  --cmd build --args v1.2.3 [--success=false --error "..."]
+//	nfo wrap  [--field k=v] -- make test
+//	nfo query --env prod --since 1h [--correlation-id ID] [--json]
+//	nfo tail  --env prod --level warn [--json]
+//	nfo export --env prod --since 24h [--format csv|jsonl] [-o FILE]
+//	nfo forward --listen :8080 [--spill-dir DIR]
+//	nfo ingest --format logfmt [--from-start] '/var/log/app/*.log'
+//	nfo ping
+//
+// Every command accepts --url (default $NFO_URL or http://localhost:8080),
+// --token (default $NFO_TOKEN, sent as a bearer token), --timeout and
+// --retries.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// command runs one subcommand and returns the process exit code.
+type command func(ctx context.Context, args []string, stdout, stderr io.Writer) int
+
+var commands = map[string]command{
+	"send":    runSend,
+	"wrap":    runWrap,
+	"query":   runQuery,
+	"tail":    runTail,
+	"export":  runExport,
+	"forward": runForward,
+	"ingest":  runIngest,
+	"ping":    runPing,
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "nfo: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+	return cmd(ctx, args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: nfo <command> [flags]
+
+Commands:
+  send    log one entry
+  wrap    run a command, stream its output and log the run
+  query   print stored entries
+  tail    follow new entries as they arrive
+  export  write matching entries as CSV or JSON Lines
+  forward accept entries locally and relay them to --url
+  ingest  follow log files and send their new lines as entries
+  ping    check that nfo-service is up and report its version
+
+Run "nfo <command> -h" for the flags of a command.
+`)
+}
+
+// connFlags are the connection flags shared by every command.
+type connFlags struct {
+	url     string
+	token   string
+	timeout time.Duration
+	retries int
+}
+
+func (c *connFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.url, "url", getEnv("NFO_URL", "http://localhost:8080"), "nfo-service URL")
+	fs.StringVar(&c.token, "token", os.Getenv("NFO_TOKEN"), "bearer token")
+	fs.DurationVar(&c.timeout, "timeout", 10*time.Second, "per-request timeout")
+	fs.IntVar(&c.retries, "retries", 3, "attempts per request")
+}
+
+// client builds a client from the flags plus opts.
+func (c *connFlags) client(opts ...nfo.Option) *nfo.NfoClient {
+	opts = append([]nfo.Option{
+		nfo.WithTimeout(c.timeout),
+		nfo.WithRetry(c.retries, 200*time.Millisecond),
+		nfo.WithUserAgent("nfo-cli"),
+	}, opts...)
+	if c.token != "" {
+		token := c.token
+		opts = append(opts, nfo.WithBearerToken(func() (string, error) { return token, nil }))
+	}
+	return nfo.NewClient(c.url, opts...)
+}
+
+// newFlagSet returns a FlagSet for command name that reports errors to
+// stderr instead of exiting.
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: nfo %s %s\n\nFlags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// listFlag collects every value of a repeated flag.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// fields parses key=value pairs.
+func (l listFlag) fields() (map[string]any, error) {
+	if len(l) == 0 {
+		return nil, nil
+	}
+	fields := make(map[string]any, len(l))
+	for _, kv := range l {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid field %q, want key=value", kv)
+		}
+		fields[k] = v
+	}
+	return fields, nil
+}
+
+// boolFlag is a bool flag that remembers whether it was given.
+type boolFlag struct{ v *bool }
+
+func (b *boolFlag) IsBoolFlag() bool { return true }
+
+func (b *boolFlag) String() string {
+	if b.v == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b.v)
+}
+
+func (b *boolFlag) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	b.v = &v
+	return nil
+}
+
+// levelFlag parses a level name; the zero value means unset.
+type levelFlag struct{ nfo.Level }
+
+func (l *levelFlag) String() string {
+	if l.Level == 0 {
+		return ""
+	}
+	return l.Level.String()
+}
+
+func (l *levelFlag) Set(s string) error {
+	level, err := nfo.ParseLevel(s)
+	l.Level = level
+	return err
+}
+
+// correlationFlag registers --correlation-id, defaulting to
+// $NFO_CORRELATION_ID so that every step of a CI job can share one ID.
+func correlationFlag(fs *flag.FlagSet, id *string) {
+	fs.StringVar(id, "correlation-id", os.Getenv("NFO_CORRELATION_ID"), "ID grouping the entries of one job")
+}
+
+// parseTime accepts a duration before now ("1h") or an RFC 3339 time.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, want a duration like 1h or RFC 3339", s)
+	}
+	return t, nil
+}
+
+// sortedKeys returns the keys of m in order, for stable output.
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func getEnv(key, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return fallback
+}
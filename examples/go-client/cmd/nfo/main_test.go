@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfotest"
+)
+
+func runCLI(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestSend(t *testing.T) {
+	srv := nfotest.NewServer(t)
+	code, _, stderr := runCLI(t, "send", "--url", srv.URL, "--cmd", "build",
+		"--args", "v1.2.3", "--success=false", "--error", "boom", "--level", "warn",
+		"--duration", "1500ms", "--field", "job=42", "--env", "ci", "--correlation-id", "run-9",
+		"--", "--release")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+
+	got := srv.Entries()
+	if len(got) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(got))
+	}
+	e := got[0]
+	if e.Cmd != "build" || strings.Join(e.Args, " ") != "v1.2.3 --release" || e.Env != "ci" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if e.Success == nil || *e.Success || e.Error != "boom" || e.Level != nfo.LevelWarn {
+		t.Fatalf("unexpected outcome: %+v", e)
+	}
+	if *e.DurationMs != 1500 || e.Fields["job"] != "42" || e.Language != "shell" || e.CorrelationID != "run-9" {
+		t.Fatalf("unexpected details: %+v", e)
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	if code, _, stderr := runCLI(t, "send"); code != 2 || !strings.Contains(stderr, "--cmd is required") {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+
+	srv := nfotest.NewServer(t)
+	srv.SetStatus("/log", http.StatusBadRequest)
+	if code, _, stderr := runCLI(t, "send", "--url", srv.URL, "--cmd", "x"); code != 1 || stderr == "" {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	if code, _, _ := runCLI(t, "bogus"); code != 2 {
+		t.Fatalf("unknown command: exit %d", code)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	srv := nfotest.NewServer(t)
+	code, stdout, _ := runCLI(t, "wrap", "--url", srv.URL, "--field", "stage=test",
+		"--", "sh", "-c", "echo hello; exit 3")
+	if code != 3 {
+		t.Fatalf("exit %d, want the command's 3", code)
+	}
+	if stdout != "hello\n" {
+		t.Fatalf("command output not streamed: %q", stdout)
+	}
+
+	got := srv.Entries()
+	if len(got) != 1 || got[0].Cmd != "sh" || *got[0].Success || got[0].Fields["stage"] != "test" {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+
+	// The exit status passes through when logging it fails as well.
+	srv.SetStatus("/log", http.StatusBadRequest)
+	code, _, stderr := runCLI(t, "wrap", "--url", srv.URL, "--", "sh", "-c", "exit 4")
+	if code != 4 || !strings.Contains(stderr, "nfo wrap:") {
+		t.Fatalf("exit %d, want the command's 4; stderr %q", code, stderr)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	srv := nfotest.NewServer(t)
+	client := nfo.NewClient(srv.URL, nfo.WithEnv("prod"))
+	client.Log(nfo.LogEntry{Cmd: "deploy", Args: []string{"api"}})
+	client.Log(nfo.LogEntry{Cmd: "migrate"})
+
+	code, stdout, stderr := runCLI(t, "query", "--url", srv.URL, "--cmd", "deploy", "--since", "1h", "--json")
+	if code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+	var e nfo.LogEntry
+	if err := json.Unmarshal([]byte(stdout), &e); err != nil || e.Cmd != "deploy" {
+		t.Fatalf("unexpected output %q: %v", stdout, err)
+	}
+
+	code, stdout, _ = runCLI(t, "query", "--url", srv.URL)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if code != 0 || len(lines) != 3 || !strings.HasPrefix(lines[0], "TIME") ||
+		!strings.Contains(lines[1], "deploy") {
+		t.Fatalf("unexpected table (exit %d):\n%s", code, stdout)
+	}
+
+	if code, _, _ := runCLI(t, "query", "--url", srv.URL, "--since", "yesterday"); code != 2 {
+		t.Fatalf("bad --since: exit %d", code)
+	}
+}
+
+func TestExport(t *testing.T) {
+	srv := nfotest.NewServer(t)
+	client := nfo.NewClient(srv.URL, nfo.WithEnv("prod"))
+	for _, cmd := range []string{"deploy", "migrate", "deploy"} {
+		client.Log(nfo.LogEntry{Cmd: cmd})
+	}
+
+	code, stdout, stderr := runCLI(t, "export", "--url", srv.URL, "--cmd", "deploy", "--page-size", "1")
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if code != 0 || len(lines) != 3 || !strings.HasPrefix(lines[0], "timestamp,level,env,cmd") ||
+		!strings.Contains(lines[2], ",prod,deploy,") {
+		t.Fatalf("unexpected CSV (exit %d):\n%s%s", code, stdout, stderr)
+	}
+
+	out := filepath.Join(t.TempDir(), "logs.jsonl")
+	code, _, stderr = runCLI(t, "export", "--url", srv.URL, "--format", "jsonl", "-o", out)
+	data, err := os.ReadFile(out)
+	if code != 0 || err != nil || strings.Count(string(data), "\n") != 3 || !strings.Contains(stderr, "exported 3 entries") {
+		t.Fatalf("exit %d, %v: %q %s", code, err, data, stderr)
+	}
+
+	if code, _, _ := runCLI(t, "export", "--url", srv.URL, "--format", "xml"); code != 2 {
+		t.Fatalf("bad --format: exit %d", code)
+	}
+}
+
+func TestForward(t *testing.T) {
+	upstream := nfotest.NewServer(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	var stderr bytes.Buffer
+	go func() {
+		done <- run(ctx, []string{"forward", "--url", upstream.URL, "--listen", addr, "--keep", "10", "--flush-interval", "1h"}, io.Discard, &stderr)
+	}()
+
+	edge := nfo.NewClient("http://"+addr, nfo.WithRetry(20, 10*time.Millisecond))
+	if err := edge.Log(nfo.LogEntry{Cmd: "scan"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if got, err := edge.Query(context.Background(), nfo.QueryParams{}); err != nil || len(got) != 1 {
+		t.Fatalf("local query = %+v, %v", got, err)
+	}
+	cancel()
+	if code := <-done; code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if got := upstream.Entries(); len(got) != 1 || got[0].Cmd != "scan" {
+		t.Fatalf("upstream entries = %+v", got)
+	}
+}
+
+func TestIngest(t *testing.T) {
+	srv := nfotest.NewServer(t)
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("level=error msg=\"disk full\"\nnot logfmt=\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan int)
+	var stderr bytes.Buffer
+	go func() {
+		done <- run(ctx, []string{"ingest", "--url", srv.URL, "--format", "logfmt", "--from-start", "--env", "ci", path}, io.Discard, &stderr)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	if code := <-done; code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	got := srv.Entries()
+	if len(got) != 1 || got[0].Cmd != "disk full" || got[0].Env != "ci" || got[0].Level != nfo.LevelError {
+		t.Fatalf("entries = %+v", got)
+	}
+	if !strings.Contains(stderr.String(), "app.log") {
+		t.Fatalf("expected the unparsable line to be reported: %s", stderr.String())
+	}
+
+	if code, _, stderr := runCLI(t, "ingest", "--format", "xml", path); code != 2 || !strings.Contains(stderr, "unknown format") {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+}
+
+func TestPing(t *testing.T) {
+	srv := nfotest.NewServer(t)
+	code, stdout, stderr := runCLI(t, "ping", "--url", srv.URL)
+	if code != 0 || !strings.HasPrefix(stdout, srv.URL+": ok (version nfotest, ") {
+		t.Fatalf("exit %d: %q %s", code, stdout, stderr)
+	}
+
+	srv.SetStatus("/health", http.StatusServiceUnavailable)
+	if code, _, stderr := runCLI(t, "ping", "--url", srv.URL, "--retries", "1"); code != 1 || !strings.Contains(stderr, "503") {
+		t.Fatalf("exit %d: %s", code, stderr)
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got, _ := parseTime("90m", now); !got.Equal(now.Add(-90 * time.Minute)) {
+		t.Fatalf("90m = %v", got)
+	}
+	if got, _ := parseTime("2026-04-30T00:00:00Z", now); got.Day() != 30 {
+		t.Fatalf("RFC 3339 = %v", got)
+	}
+}
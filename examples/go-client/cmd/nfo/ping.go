@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// runPing checks that nfo-service is up, for readiness gates in scripts:
+//
+//	nfo ping || exit 1
+func runPing(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("ping", "[flags]", stderr)
+	var conn connFlags
+	conn.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	h, err := conn.client().Ping(ctx)
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo ping: %s: %v\n", conn.url, err)
+		return 1
+	}
+	version := h.Version
+	if version == "" {
+		version = "unknown"
+	}
+	fmt.Fprintf(stdout, "%s: %s (version %s, %s)\n", h.Endpoint, h.Status, version, h.Latency.Round(time.Millisecond/10))
+	return 0
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// runQuery prints the stored entries matching the flags.
+func runQuery(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("query", "[flags]", stderr)
+	var (
+		conn    connFlags
+		params  nfo.QueryParams
+		success boolFlag
+		level   levelFlag
+	)
+	conn.register(fs)
+	fs.StringVar(&params.Cmd, "cmd", "", "only entries for this command")
+	fs.StringVar(&params.Env, "env", "", "only entries from this environment")
+	fs.Var(&success, "success", "only successful (true) or failed (false) entries")
+	fs.Var(&level, "level", "only entries at this level")
+	fs.StringVar(&params.CorrelationID, "correlation-id", "", "only entries of this job or request")
+	since := fs.String("since", "", "only entries after this time (1h, or RFC 3339)")
+	until := fs.String("until", "", "only entries before this time (1h, or RFC 3339)")
+	fs.IntVar(&params.Limit, "limit", 50, "maximum number of entries")
+	fs.IntVar(&params.Offset, "offset", 0, "entries to skip")
+	format := formatFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	now := time.Now()
+	var err error
+	if params.Since, err = parseTime(*since, now); err == nil {
+		params.Until, err = parseTime(*until, now)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo query: %v\n", err)
+		return 2
+	}
+	params.Success = success.v
+	params.Level = level.Level
+
+	entries, err := conn.client().Query(ctx, params)
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo query: %v\n", err)
+		return 1
+	}
+	p, err := format(stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo query: %v\n", err)
+		return 2
+	}
+	for _, entry := range entries {
+		p.print(entry)
+	}
+	p.flush()
+	return 0
+}
+
+// runTail prints new entries until interrupted.
+func runTail(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("tail", "[flags]", stderr)
+	var (
+		conn    connFlags
+		filter  nfo.TailFilter
+		success boolFlag
+		level   levelFlag
+	)
+	conn.register(fs)
+	fs.StringVar(&filter.Cmd, "cmd", "", "only entries for this command")
+	fs.StringVar(&filter.Env, "env", "", "only entries from this environment")
+	fs.Var(&success, "success", "only successful (true) or failed (false) entries")
+	fs.Var(&level, "level", "only entries at this level or above")
+	fs.StringVar(&filter.Cursor, "cursor", "", "resume after this stream position")
+	format := formatFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	p, err := format(stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo tail: %v\n", err)
+		return 2
+	}
+	filter.Success = success.v
+	filter.MinLevel = level.Level
+	filter.OnError = func(err error) { fmt.Fprintf(stderr, "nfo tail: %v\n", err) }
+
+	entries, err := conn.client().TailLogs(ctx, filter)
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo tail: %v\n", err)
+		return 1
+	}
+	for entry := range entries {
+		p.print(entry)
+		p.flush()
+	}
+	if ctx.Err() == nil {
+		return 1 // the service ended the stream for good
+	}
+	return 0
+}
+
+// formatFlags registers --format and --json and returns a constructor for
+// the selected printer.
+func formatFlags(fs *flag.FlagSet) func(io.Writer) (printer, error) {
+	format := fs.String("format", "table", "output format: table or json")
+	asJSON := fs.Bool("json", false, "shorthand for --format json")
+	return func(w io.Writer) (printer, error) {
+		if *asJSON {
+			*format = "json"
+		}
+		switch *format {
+		case "table":
+			tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(tw, "TIME\tLEVEL\tENV\tCMD\tARGS\tSTATUS\tDURATION\tDETAIL")
+			return &tablePrinter{tw: tw}, nil
+		case "json":
+			return &jsonPrinter{enc: json.NewEncoder(w)}, nil
+		}
+		return nil, fmt.Errorf("unknown format %q, want table or json", *format)
+	}
+}
+
+// printer writes entries in one output format.
+type printer interface {
+	print(entry nfo.LogEntry)
+	flush()
+}
+
+// jsonPrinter writes one JSON object per line.
+type jsonPrinter struct {
+	enc *json.Encoder
+}
+
+func (p *jsonPrinter) print(entry nfo.LogEntry) { p.enc.Encode(entry) }
+
+func (p *jsonPrinter) flush() {}
+
+// tablePrinter aligns entries in columns.
+type tablePrinter struct {
+	tw *tabwriter.Writer
+}
+
+func (p *tablePrinter) print(entry nfo.LogEntry) {
+	ts, level, status, duration := "-", "-", "-", "-"
+	if entry.Timestamp != nil {
+		ts = entry.Timestamp.Local().Format(time.DateTime)
+	}
+	if entry.Level != 0 {
+		level = entry.Level.String()
+	}
+	if entry.Success != nil {
+		status = "ok"
+		if !*entry.Success {
+			status = "failed"
+		}
+	}
+	if entry.DurationMs != nil {
+		duration = fmt.Sprintf("%.0fms", *entry.DurationMs)
+	}
+	fmt.Fprintf(p.tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", ts, level, entry.Env, entry.Cmd,
+		strings.Join(entry.Args, " "), status, duration, detail(entry))
+}
+
+func (p *tablePrinter) flush() { p.tw.Flush() }
+
+// detail is the error, or else the output and fields, on one line.
+func detail(entry nfo.LogEntry) string {
+	var parts []string
+	if entry.Error != "" {
+		parts = append(parts, entry.Error)
+	} else if entry.Output != "" {
+		parts = append(parts, entry.Output)
+	}
+	for _, k := range sortedKeys(entry.Fields) {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, entry.Fields[k]))
+	}
+	return strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// runSend logs one entry built from flags. Positional arguments are
+// appended to --args.
+func runSend(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("send", "--cmd NAME [flags] [ARG...]", stderr)
+	var (
+		conn             connFlags
+		entryArgs, field listFlag
+		success          boolFlag
+		level            levelFlag
+		entry            nfo.LogEntry
+	)
+	conn.register(fs)
+	fs.StringVar(&entry.Cmd, "cmd", "", "command or operation name (required)")
+	fs.Var(&entryArgs, "args", "argument; repeat for several")
+	fs.StringVar(&entry.Language, "language", "shell", "language or tool that produced the entry")
+	fs.StringVar(&entry.Env, "env", getEnv("NFO_ENV", "prod"), "environment")
+	fs.Var(&success, "success", "whether the operation succeeded")
+	fs.Var(&level, "level", "debug, info, warn or error")
+	duration := fs.Duration("duration", 0, "how long the operation took")
+	fs.StringVar(&entry.Output, "output", "", "output to record")
+	fs.StringVar(&entry.Error, "error", "", "error message to record")
+	fs.Var(&field, "field", "structured field as key=value; repeat for several")
+	correlationFlag(fs, &entry.CorrelationID)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if entry.Cmd == "" {
+		fmt.Fprintln(stderr, "nfo send: --cmd is required")
+		fs.Usage()
+		return 2
+	}
+	fields, err := field.fields()
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo send: %v\n", err)
+		return 2
+	}
+
+	entry.Args = append(entryArgs, fs.Args()...)
+	entry.Success = success.v
+	entry.Level = level.Level
+	entry.Fields = fields
+	if *duration > 0 {
+		ms := float64(duration.Milliseconds())
+		entry.DurationMs = &ms
+	}
+	if err := conn.client().LogContext(ctx, entry); err != nil {
+		fmt.Fprintf(stderr, "nfo send: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// runWrap runs the command after "--", streams its output, logs the run
+// and exits with the command's exit code.
+func runWrap(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("wrap", "[flags] -- COMMAND [ARG...]", stderr)
+	var (
+		conn  connFlags
+		field listFlag
+	)
+	conn.register(fs)
+	env := fs.String("env", getEnv("NFO_ENV", "prod"), "environment")
+	fs.Var(&field, "field", "structured field as key=value; repeat for several")
+	maxOutput := fs.Int("max-output", nfo.DefaultMaxOutput, "bytes of each stream to keep")
+	var correlationID string
+	correlationFlag(fs, &correlationID)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "nfo wrap: missing command")
+		fs.Usage()
+		return 2
+	}
+	fields, err := field.fields()
+	if err != nil {
+		fmt.Fprintf(stderr, "nfo wrap: %v\n", err)
+		return 2
+	}
+
+	if correlationID != "" {
+		ctx = nfo.WithCorrelationID(ctx, correlationID)
+	}
+	client := conn.client(nfo.WithEnv(*env), nfo.WithFields(fields))
+	opts := nfo.RunOptions{MaxOutput: *maxOutput, Stdout: stdout, Stderr: stderr}
+	result, err := client.RunCommandWith(ctx, opts, fs.Arg(0), fs.Args()[1:]...)
+
+	// A non-zero exit is the command's own result and passes through, even
+	// when logging it failed too; anything else, such as a missing binary
+	// or a logging failure, is reported.
+	var exitErr *exec.ExitError
+	exited := errors.As(err, &exitErr)
+	if err != nil && (!exited || err != error(exitErr)) {
+		fmt.Fprintf(stderr, "nfo wrap: %v\n", err)
+	}
+	if exited {
+		return exitErr.ExitCode()
+	}
+	if result.ExitCode < 0 {
+		return 1
+	}
+	return result.ExitCode
+}
@@ -0,0 +1,3 @@
+module github.com/wronai/lg/examples/go-client
+
+go 1.23
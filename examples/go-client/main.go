@@ -1,10 +1,10 @@
 // nfo example — Go HTTP client for nfo centralized logging service.
 //
 // Sends log entries to nfo-service via HTTP POST.
-// Pair with examples/http_service.py.
+// Pair with examples/http-service/main.py.
 //
 // Usage:
-//   go run examples/go_client.go
+//   cd examples/go-client && go run .
 //
 // Environment:
 //   NFO_URL — nfo-service URL (default: http://localhost:8080)
@@ -12,85 +12,13 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
-)
-
-// LogEntry matches the nfo-service API schema.
-type LogEntry struct {
-	Cmd        string   `json:"cmd"`
-	Args       []string `json:"args"`
-	Language   string   `json:"language"`
-	Env        string   `json:"env"`
-	Success    *bool    `json:"success,omitempty"`
-	DurationMs *float64 `json:"duration_ms,omitempty"`
-	Output     string   `json:"output,omitempty"`
-	Error      string   `json:"error,omitempty"`
-}
-
-// NfoClient sends log entries to the nfo HTTP service.
-type NfoClient struct {
-	BaseURL    string
-	HTTPClient *http.Client
-}
-
-// NewNfoClient creates a client pointing at the given nfo-service URL.
-func NewNfoClient(baseURL string) *NfoClient {
-	return &NfoClient{
-		BaseURL:    baseURL,
-		HTTPClient: &http.Client{Timeout: 5 * time.Second},
-	}
-}
-
-// Log sends a single log entry to nfo-service.
-func (c *NfoClient) Log(entry LogEntry) error {
-	data, err := json.Marshal(entry)
-	if err != nil {
-		return fmt.Errorf("marshal: %w", err)
-	}
-
-	resp, err := c.HTTPClient.Post(
-		c.BaseURL+"/log",
-		"application/json",
-		bytes.NewBuffer(data),
-	)
-	if err != nil {
-		return fmt.Errorf("post: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("nfo-service returned %d", resp.StatusCode)
-	}
-	return nil
-}
-
-// LogCall wraps a function execution with nfo logging.
-func (c *NfoClient) LogCall(cmd string, args []string, fn func() (string, error)) error {
-	start := time.Now()
-	output, err := fn()
-	duration := float64(time.Since(start).Milliseconds())
-
-	success := err == nil
-	entry := LogEntry{
-		Cmd:        cmd,
-		Args:       args,
-		Language:   "go",
-		Env:        getEnv("NFO_ENV", "prod"),
-		Success:    &success,
-		DurationMs: &duration,
-		Output:     output,
-	}
-	if err != nil {
-		entry.Error = err.Error()
-	}
 
-	return c.Log(entry)
-}
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
 
 func getEnv(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
@@ -101,12 +29,16 @@ func getEnv(key, fallback string) string {
 
 func main() {
 	nfoURL := getEnv("NFO_URL", "http://localhost:8080")
-	client := NewNfoClient(nfoURL)
+	client := nfo.NewClient(nfoURL,
+		nfo.WithTimeout(5*time.Second),
+		nfo.WithRetry(3, 100*time.Millisecond),
+		nfo.WithEnv(getEnv("NFO_ENV", "prod")),
+	)
 
 	fmt.Printf("nfo Go Client — sending to %s\n\n", nfoURL)
 
 	// Simple log entry
-	err := client.Log(LogEntry{
+	err := client.Log(nfo.LogEntry{
 		Cmd:      "build",
 		Args:     []string{"v1.2.3", "--release"},
 		Language: "go",
@@ -139,5 +71,43 @@ func main() {
 		fmt.Println("Sent: validate bad_input (error logged)")
 	}
 
+	// Several entries in one request
+	err = client.LogBatch([]nfo.LogEntry{
+		{Cmd: "migrate", Args: []string{"up"}, Language: "go", Env: "prod"},
+		{Cmd: "seed", Args: []string{"users"}, Language: "go", Env: "prod"},
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	} else {
+		fmt.Println("Sent: migrate + seed (one batch request)")
+	}
+
+	// Buffered logging off the hot path
+	async := nfo.NewAsyncClient(client, nfo.AsyncConfig{
+		QueueSize:     256,
+		FlushInterval: 500 * time.Millisecond,
+		Overflow:      nfo.DropOldest,
+	})
+	for i := 0; i < 10; i++ {
+		async.Log(nfo.LogEntry{Cmd: "tick", Args: []string{fmt.Sprint(i)}, Language: "go", Env: "prod"})
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	report, err := async.CloseContext(shutdown)
+	cancel()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	} else {
+		fmt.Printf("Sent: %d x tick (async, drained on Close)\n", report.Sent)
+	}
+
+	// Read back what was logged
+	ctx := context.Background()
+	recent, err := client.Query(ctx, nfo.QueryParams{Env: "prod", Limit: 5})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+	} else {
+		fmt.Printf("Queried: %d recent prod entries\n", len(recent))
+	}
+
 	fmt.Println("\nDone. Query logs: curl", nfoURL+"/logs")
 }
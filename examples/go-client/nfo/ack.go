@@ -0,0 +1,282 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ErrNotAcknowledged is returned in acknowledged mode for entries that
+// nfo-service did not confirm as stored, even after they were re-sent.
+var ErrNotAcknowledged = errors.New("nfo: entries not acknowledged")
+
+// Acks is the response body of an nfo-service that supports
+// acknowledgements: POST /log and /logs/batch list the IDs of the request's
+// entries it has stored, and POST /logs/ack, given a list of IDs, answers
+// with the ones it holds.
+type Acks struct {
+	IDs []string `json:"ids"`
+}
+
+// WithAcknowledgements switches the client to acknowledged, at-least-once
+// delivery, for logs that must provably reach storage, such as audit
+// trails. A 200 response alone no longer counts as delivered:
+//
+//   - every entry gets an ID (a ULID, see LogEntry.ID) when it is logged,
+//     which the service uses as an idempotency key, so re-sending an entry
+//     never stores it twice;
+//   - the service must list the IDs it stored in its response (see Acks),
+//     and entries it leaves out are re-sent, up to the retry attempts;
+//   - entries still unacknowledged fail with ErrNotAcknowledged, and an
+//     AsyncClient spills or drops them like any failed delivery.
+//
+// AsyncClient.WaitForDelivery waits until entries are acknowledged, and
+// Confirm asks the service about entries later. Acknowledgements apply to
+// HTTP only; with WithNegotiation, a service that does not report
+// Capabilities.Acknowledgements fails log requests with ErrUnsupported.
+func WithAcknowledgements() Option {
+	return func(cfg *clientConfig) {
+		cfg.client.acks = true
+	}
+}
+
+// Confirm asks nfo-service which of the entries with the given IDs it has
+// stored, and returns those IDs.
+func (c *NfoClient) Confirm(ctx context.Context, ids []string) ([]string, error) {
+	if err := c.negotiate(ctx).require(func(caps Capabilities) bool { return caps.Acknowledgements }, "acknowledgements"); err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(Acks{IDs: ids})
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.do(ctx, http.MethodPost, "/logs/ack", ContentTypeJSON, body)
+	if err != nil {
+		return nil, err
+	}
+	var acks Acks
+	if err := json.Unmarshal(data, &acks); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	return acks.IDs, nil
+}
+
+// Confirm asks nfo-service which of the entries with the given IDs it has
+// stored; see NfoClient.Confirm.
+func (a *AsyncClient) Confirm(ctx context.Context, ids []string) ([]string, error) {
+	return a.client.Confirm(ctx, ids)
+}
+
+// requireAcks fails with ErrUnsupported if the client is in acknowledged
+// mode and negotiation found a service without acknowledgements.
+func (c *NfoClient) requireAcks(n *negotiated) error {
+	if !c.acks || c.transport != nil {
+		return nil
+	}
+	return n.require(func(caps Capabilities) bool { return caps.Acknowledgements }, "acknowledgements")
+}
+
+// awaitAcks checks the response data to a log request for the IDs of
+// entries, and re-sends the encoded entries the service did not
+// acknowledge to path, up to the retry attempts. It returns the indexes of
+// the entries that remain unacknowledged.
+func (c *NfoClient) awaitAcks(ctx context.Context, path string, codec Codec, entries []LogEntry, encoded [][]byte, data []byte) ([]int, error) {
+	missing := make([]int, len(entries))
+	for i := range missing {
+		missing[i] = i
+	}
+	for attempt := 1; ; attempt++ {
+		var acks Acks
+		if err := json.Unmarshal(data, &acks); err == nil {
+			missing = slices.DeleteFunc(missing, func(i int) bool { return slices.Contains(acks.IDs, entries[i].ID) })
+		}
+		if len(missing) == 0 {
+			return nil, nil
+		}
+		if attempt >= c.retry.attempts {
+			return missing, fmt.Errorf("%w: %d of %d entries", ErrNotAcknowledged, len(missing), len(entries))
+		}
+		select {
+		case <-ctx.Done():
+			return missing, ctx.Err()
+		case <-time.After(c.retry.delay(attempt)):
+		}
+		c.metrics.RequestRetried()
+		body := encoded[missing[0]]
+		if path != "/log" {
+			resend := make([][]byte, len(missing))
+			for j, i := range missing {
+				resend[j] = encoded[i]
+			}
+			body = codec.Batch(resend)
+		}
+		var err error
+		if data, err = c.do(ctx, http.MethodPost, path, codec.ContentType(), body); err != nil {
+			return missing, err
+		}
+	}
+}
+
+// entriesAt returns the entries at indexes.
+func entriesAt(entries []LogEntry, indexes []int) []LogEntry {
+	picked := make([]LogEntry, len(indexes))
+	for j, i := range indexes {
+		picked[j] = entries[i]
+	}
+	return picked
+}
+
+// ackTracker follows the entries an AsyncClient has queued until they are
+// acknowledged or dropped, for WaitForDelivery.
+type ackTracker struct {
+	mu      sync.Mutex
+	pending map[string]bool
+	waits   []*ackWait
+}
+
+// ackWait is one WaitForDelivery call: the IDs it still waits for, and how
+// many of its entries were lost.
+type ackWait struct {
+	ids  map[string]bool
+	lost int
+	done chan struct{}
+}
+
+func newAckTracker() *ackTracker {
+	return &ackTracker{pending: make(map[string]bool)}
+}
+
+// add starts tracking the entry with id.
+func (t *ackTracker) add(id string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending[id] = true
+}
+
+// delivered resolves the entries of a log request as acknowledged, except
+// those that failed.
+func (t *ackTracker) delivered(entries, failed []LogEntry) {
+	lost := make(map[string]bool, len(failed))
+	for _, entry := range failed {
+		lost[entry.ID] = true
+	}
+	t.resolve(true, slices.DeleteFunc(slices.Clone(entries), func(e LogEntry) bool { return lost[e.ID] })...)
+}
+
+// resolve stops tracking entries, which were acknowledged if acked is set
+// and lost otherwise.
+func (t *ackTracker) resolve(acked bool, entries ...LogEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, entry := range entries {
+		if !t.pending[entry.ID] {
+			continue
+		}
+		delete(t.pending, entry.ID)
+		for _, w := range t.waits {
+			if !w.ids[entry.ID] {
+				continue
+			}
+			delete(w.ids, entry.ID)
+			if !acked {
+				w.lost++
+			}
+			if len(w.ids) == 0 {
+				close(w.done)
+			}
+		}
+	}
+	t.waits = slices.DeleteFunc(t.waits, func(w *ackWait) bool { return len(w.ids) == 0 })
+}
+
+// wait registers a wait for the entries pending now, or returns nil if
+// there are none.
+func (t *ackTracker) wait() *ackWait {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) == 0 {
+		return nil
+	}
+	w := &ackWait{ids: make(map[string]bool, len(t.pending)), done: make(chan struct{})}
+	for id := range t.pending {
+		w.ids[id] = true
+	}
+	t.waits = append(t.waits, w)
+	return w
+}
+
+// err reports the outcome of w once it is done.
+func (w *ackWait) err() error {
+	if w.lost > 0 {
+		return fmt.Errorf("%w: %d entries dropped", ErrNotAcknowledged, w.lost)
+	}
+	return nil
+}
+
+// cancel abandons w.
+func (t *ackTracker) cancel(w *ackWait) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.waits = slices.DeleteFunc(t.waits, func(x *ackWait) bool { return x == w })
+}
+
+// len returns the number of entries not yet acknowledged.
+func (t *ackTracker) len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending)
+}
+
+// WaitForDelivery flushes the queue and waits until nfo-service has
+// acknowledged every entry logged before the call, flushing again every
+// FlushInterval while some are spilled. It returns nil once all are
+// acknowledged, an error wrapping ErrNotAcknowledged if some were dropped
+// instead, or ctx's error. It requires WithAcknowledgements on the client.
+//
+//	async.LogContext(ctx, nfo.LogEntry{Cmd: "transfer", Args: []string{from, to, amount}})
+//	if err := async.WaitForDelivery(ctx); err != nil {
+//		return fmt.Errorf("audit trail: %w", err)
+//	}
+func (a *AsyncClient) WaitForDelivery(ctx context.Context) error {
+	if a.acks == nil {
+		return fmt.Errorf("%w: WaitForDelivery requires WithAcknowledgements", ErrInvalidConfig)
+	}
+	w := a.acks.wait()
+	if w == nil {
+		return nil
+	}
+	defer a.acks.cancel(w)
+	ticker := time.NewTicker(a.cfg.FlushInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := a.FlushContext(ctx); errors.Is(err, ErrClosed) {
+			select {
+			case <-w.done:
+				return w.err()
+			default:
+				return err
+			}
+		}
+		select {
+		case <-w.done:
+			return w.err()
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Unacknowledged reports how many entries logged in acknowledged mode are
+// queued, in flight or spilled without an acknowledgement yet.
+func (a *AsyncClient) Unacknowledged() int {
+	if a.acks == nil {
+		return 0
+	}
+	return a.acks.len()
+}
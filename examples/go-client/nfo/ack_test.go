@@ -0,0 +1,201 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ackServer stores entries by ID and acknowledges them, except that it
+// leaves the first unacked responses out.
+type ackServer struct {
+	mu       sync.Mutex
+	stored   map[string]LogEntry
+	requests int
+	unacked  int
+	down     bool
+}
+
+func newAckServer(t *testing.T) (*ackServer, *httptest.Server) {
+	s := &ackServer{stored: make(map[string]LogEntry)}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *ackServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	if r.URL.Path == "/logs/ack" {
+		var req Acks
+		json.Unmarshal(body, &req)
+		var acks Acks
+		for _, id := range req.IDs {
+			if _, ok := s.stored[id]; ok {
+				acks.IDs = append(acks.IDs, id)
+			}
+		}
+		json.NewEncoder(w).Encode(acks)
+		return
+	}
+	s.requests++
+	if s.down {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+		return
+	}
+	codec, _ := CodecFor(r.Header.Get("Content-Type"))
+	entries, err := codec.Unmarshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	acks := Acks{IDs: []string{}}
+	for _, e := range entries {
+		s.stored[e.ID] = e
+		if s.unacked > 0 {
+			s.unacked--
+			continue
+		}
+		acks.IDs = append(acks.IDs, e.ID)
+	}
+	json.NewEncoder(w).Encode(acks)
+}
+
+func (s *ackServer) set(f func(s *ackServer)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(s)
+}
+
+func (s *ackServer) stats() (stored, requests int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.stored), s.requests
+}
+
+func TestAcknowledgementsResendUnacked(t *testing.T) {
+	s, srv := newAckServer(t)
+	s.set(func(s *ackServer) { s.unacked = 2 })
+	client := NewClient(srv.URL, WithAcknowledgements(), WithRetry(3, time.Millisecond))
+
+	if err := client.LogBatch([]LogEntry{{Cmd: "a"}, {Cmd: "b"}, {Cmd: "c"}}); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+	stored, requests := s.stats()
+	if stored != 3 || requests != 2 {
+		t.Fatalf("stored %d entries in %d requests, want 3 in 2", stored, requests)
+	}
+	for id, e := range s.stored {
+		if len(id) != 26 {
+			t.Fatalf("entry %s has ID %q, want a ULID", e.Cmd, id)
+		}
+	}
+}
+
+func TestAcknowledgementsGiveUp(t *testing.T) {
+	s, srv := newAckServer(t)
+	s.set(func(s *ackServer) { s.unacked = 10 })
+	client := NewClient(srv.URL, WithAcknowledgements(), WithRetry(2, time.Millisecond))
+
+	if err := client.Log(LogEntry{Cmd: "a"}); !errors.Is(err, ErrNotAcknowledged) {
+		t.Fatalf("Log = %v, want ErrNotAcknowledged", err)
+	}
+	if _, requests := s.stats(); requests != 2 {
+		t.Fatalf("requests = %d, want 2", requests)
+	}
+
+	failed, err := client.logBatch(context.Background(), []LogEntry{{Cmd: "b", ID: "1"}, {Cmd: "c", ID: "2"}})
+	if !errors.Is(err, ErrNotAcknowledged) || len(failed) != 2 {
+		t.Fatalf("logBatch = %d failed, %v", len(failed), err)
+	}
+}
+
+func TestAcknowledgementsKeepID(t *testing.T) {
+	s, srv := newAckServer(t)
+	client := NewClient(srv.URL, WithAcknowledgements())
+
+	if err := client.Log(LogEntry{Cmd: "a", ID: "audit-1"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	got, err := client.Confirm(context.Background(), []string{"audit-1", "audit-2"})
+	if err != nil || len(got) != 1 || got[0] != "audit-1" {
+		t.Fatalf("Confirm = %v, %v", got, err)
+	}
+	if _, ok := s.stored["audit-1"]; !ok {
+		t.Fatal("entry was not stored under its own ID")
+	}
+}
+
+func TestAcknowledgementsNegotiation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/capabilities" {
+			json.NewEncoder(w).Encode(Capabilities{APIVersion: "1", Batch: true})
+			return
+		}
+		t.Errorf("unexpected request %s", r.URL.Path)
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL, WithAcknowledgements(), WithNegotiation(time.Minute))
+
+	if err := client.Log(LogEntry{Cmd: "a"}); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Log = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestAsyncClientWaitForDelivery(t *testing.T) {
+	s, srv := newAckServer(t)
+	s.set(func(s *ackServer) { s.down = true })
+	spill, err := OpenSpill(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(srv.URL, WithAcknowledgements())
+	async := NewAsyncClient(client, AsyncConfig{FlushInterval: 10 * time.Millisecond, Spill: spill})
+	defer async.Close()
+
+	async.Log(LogEntry{Cmd: "a"})
+	async.Log(LogEntry{Cmd: "b"})
+	if n := async.Unacknowledged(); n != 2 {
+		t.Fatalf("Unacknowledged = %d, want 2", n)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := async.WaitForDelivery(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitForDelivery while down = %v", err)
+	}
+
+	s.set(func(s *ackServer) { s.down = false })
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := async.WaitForDelivery(ctx); err != nil {
+		t.Fatalf("WaitForDelivery: %v", err)
+	}
+	if stored, _ := s.stats(); stored != 2 || async.Unacknowledged() != 0 {
+		t.Fatalf("stored %d, %d unacknowledged", stored, async.Unacknowledged())
+	}
+}
+
+func TestAsyncClientWaitForDeliveryDropped(t *testing.T) {
+	s, srv := newAckServer(t)
+	s.set(func(s *ackServer) { s.unacked = 1 })
+	async := NewAsyncClient(NewClient(srv.URL, WithAcknowledgements()), AsyncConfig{})
+	defer async.Close()
+
+	async.Log(LogEntry{Cmd: "a"})
+	if err := async.WaitForDelivery(context.Background()); !errors.Is(err, ErrNotAcknowledged) {
+		t.Fatalf("WaitForDelivery = %v, want ErrNotAcknowledged", err)
+	}
+
+	plain := NewAsyncClient(NewClient(srv.URL), AsyncConfig{})
+	defer plain.Close()
+	if err := plain.WaitForDelivery(context.Background()); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("WaitForDelivery without acknowledgements = %v", err)
+	}
+}
@@ -0,0 +1,440 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned by AsyncClient.Log when the queue is full
+	// and the entry gives way to queued ones under the overflow policy.
+	ErrQueueFull = errors.New("nfo: queue full")
+	// ErrClosed is returned when logging through a closed client.
+	ErrClosed = errors.New("nfo: client closed")
+)
+
+// OverflowPolicy decides what AsyncClient does when its queue is full.
+//
+// The dropping policies shed the entries of lowest Priority first: an
+// entry only displaces queued entries of lower priority, or, with
+// DropOldest, of the same priority, and PriorityCritical entries are never
+// dropped but wait for room as with Block.
+type OverflowPolicy int
+
+const (
+	// DropNewest discards the entry being enqueued, unless entries of
+	// lower priority are queued; then the newest of those is evicted.
+	DropNewest OverflowPolicy = iota
+	// DropOldest evicts the oldest queued entry of the lowest priority to
+	// make room, if it is not above the priority of the entry being
+	// enqueued.
+	DropOldest
+	// Block waits until the background flusher frees a slot.
+	Block
+)
+
+// AsyncConfig configures an AsyncClient. Zero values select the defaults.
+type AsyncConfig struct {
+	// QueueSize bounds the number of buffered entries (default 1024).
+	QueueSize int
+	// FlushInterval is how often the queue is drained (default 1s).
+	FlushInterval time.Duration
+	// Overflow selects the behaviour when the queue is full.
+	Overflow OverflowPolicy
+	// ErrorHandler, if set, receives errors from background flushes.
+	ErrorHandler func(error)
+	// Spill, if set, receives entries whose delivery failed after all
+	// retries. They are replayed on later flushes once sending succeeds.
+	Spill *Spill
+}
+
+// AsyncClient buffers log entries in memory and ships them to nfo-service
+// from a background goroutine, so Log never blocks on the network.
+type AsyncClient struct {
+	client *NfoClient
+	cfg    AsyncConfig
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	queue   []LogEntry
+	closed  bool
+	closing bool // CloseContext has started
+	dropped atomic.Uint64
+	// droppedBy counts dropped entries by Priority.
+	droppedBy [PriorityCritical + 1]atomic.Uint64
+	// acks tracks unacknowledged entries with WithAcknowledgements.
+	acks *ackTracker
+
+	sendMu  sync.Mutex
+	wake    chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	// runCtx bounds the background flushes; CloseContext cancels it when
+	// its own ctx expires, so an in-flight send does not outlive Close.
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+}
+
+// NewAsyncClient starts a buffered client that sends through client.
+// Call Close on shutdown to drain the queue.
+func NewAsyncClient(client *NfoClient, cfg AsyncConfig) *AsyncClient {
+	a := newAsyncClient(client, cfg)
+	go a.run()
+	return a
+}
+
+func newAsyncClient(client *NfoClient, cfg AsyncConfig) *AsyncClient {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	a := &AsyncClient{
+		client:  client,
+		cfg:     cfg,
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	a.runCtx, a.cancelRun = context.WithCancel(context.Background())
+	a.notFull = sync.NewCond(&a.mu)
+	if client.acks {
+		a.acks = newAckTracker()
+	}
+	return a
+}
+
+// Log enqueues entry for background delivery.
+func (a *AsyncClient) Log(entry LogEntry) error {
+	return a.LogContext(context.Background(), entry)
+}
+
+// LogContext enqueues entry after linking it to the trace span and
+// correlation ID in ctx.
+// Sampling, rate limiting and redaction configured on the client apply
+// here, so rejected entries never take a queue slot and secrets never
+// reach the queue or a Spill.
+func (a *AsyncClient) LogContext(ctx context.Context, entry LogEntry) error {
+	// Checked before accept, so that a closing client opens no dedup
+	// windows whose rollups would bypass the queue.
+	a.mu.Lock()
+	closed := a.closed || a.closing
+	a.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+	entry, ok, err := a.client.accept(ctx, entry)
+	if !ok {
+		return err
+	}
+	return a.enqueue(a.client.stampContext(ctx, entry))
+}
+
+// enqueue adds entry to the queue, applying the overflow policy.
+func (a *AsyncClient) enqueue(entry LogEntry) error {
+	return a.enqueueContext(context.Background(), entry)
+}
+
+// enqueueContext is enqueue that stops waiting for room, with ctx's error,
+// once ctx ends.
+func (a *AsyncClient) enqueueContext(ctx context.Context, entry LogEntry) error {
+	var evicted []LogEntry
+	defer func() { a.drop(DropQueueFull, evicted...) }() // after unlocking
+	if ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, func() {
+			a.mu.Lock()
+			a.notFull.Broadcast()
+			a.mu.Unlock()
+		})
+		defer stop()
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	p := priorityOf(entry)
+	for len(a.queue) >= a.cfg.QueueSize {
+		if a.closed {
+			return ErrClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if a.cfg.Overflow != Block {
+			if i := a.victim(p); i >= 0 {
+				evicted = append(evicted, a.queue[i])
+				a.queue = slices.Delete(a.queue, i, i+1)
+				continue
+			}
+			if p < PriorityCritical {
+				evicted = append(evicted, entry)
+				return ErrQueueFull
+			}
+		}
+		a.signal()
+		a.notFull.Wait()
+	}
+	if a.closed {
+		return ErrClosed
+	}
+
+	if a.acks != nil {
+		a.acks.add(entry.ID)
+	}
+	a.queue = append(a.queue, entry)
+	a.client.metrics.QueueDepth(len(a.queue))
+	if len(a.queue) >= a.cfg.QueueSize || a.batchReady() {
+		a.signal()
+	}
+	return nil
+}
+
+// victim returns the index of the queued entry to evict for an entry of
+// priority p, or -1 if the new entry should give way instead. Callers hold
+// a.mu.
+func (a *AsyncClient) victim(p Priority) int {
+	// Find the oldest, or for DropNewest the newest, entry of the lowest
+	// priority below PriorityCritical.
+	lowest, at := PriorityCritical, -1
+	for i, queued := range a.queue {
+		q := priorityOf(queued)
+		if q < lowest || q == lowest && at >= 0 && a.cfg.Overflow == DropNewest {
+			lowest, at = q, i
+		}
+	}
+	if at >= 0 && (lowest < p || lowest == p && a.cfg.Overflow == DropOldest) {
+		return at
+	}
+	return -1
+}
+
+// LogCall wraps a function execution and enqueues the resulting entry.
+func (a *AsyncClient) LogCall(cmd string, args []string, fn func() (string, error)) error {
+	return a.Log(callEntry(cmd, args, fn))
+}
+
+// Dropped reports how many entries were discarded due to overflow or
+// failed delivery without a Spill.
+func (a *AsyncClient) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// DroppedByPriority reports how many of the entries counted by Dropped had
+// priority p.
+func (a *AsyncClient) DroppedByPriority(p Priority) uint64 {
+	if p < PriorityLow || p > PriorityCritical {
+		return 0
+	}
+	return a.droppedBy[p].Load()
+}
+
+// Len reports the number of entries waiting to be sent.
+func (a *AsyncClient) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.queue)
+}
+
+// drop records discarded entries. Callers must not hold a.mu.
+func (a *AsyncClient) drop(reason DropReason, entries ...LogEntry) {
+	a.dropped.Add(uint64(len(entries)))
+	countByPriority(entries, func(n int, p Priority) { a.droppedBy[p].Add(uint64(n)) })
+	if a.acks != nil {
+		a.acks.resolve(false, entries...)
+	}
+	a.client.discard(reason, entries...)
+}
+
+// batchReady reports whether a full batch is waiting. Callers hold a.mu.
+func (a *AsyncClient) batchReady() bool {
+	return a.client.MaxBatchSize > 0 && len(a.queue) >= a.client.MaxBatchSize
+}
+
+// FlushReport describes the outcome of FlushContext or CloseContext.
+type FlushReport struct {
+	// Sent counts entries delivered, including ones replayed from a Spill.
+	Sent int
+	// Spilled counts entries written to the Spill after delivery failed.
+	Spilled int
+	// Dropped counts entries lost: failed without a Spill, rejected by the
+	// Spill's size cap, or still queued when the deadline expired.
+	Dropped int
+}
+
+// Flush synchronously sends every queued entry, coalesced into batches.
+// With a Spill configured, entries that cannot be delivered are written to
+// disk instead, and previously spilled entries are replayed once the
+// service accepts requests again.
+func (a *AsyncClient) Flush() error {
+	_, err := a.FlushContext(context.Background())
+	return err
+}
+
+// FlushContext is Flush bounded by ctx: requests still in flight when ctx
+// expires fail and their entries are spilled or dropped. It returns
+// ErrClosed once the client is closed.
+func (a *AsyncClient) FlushContext(ctx context.Context) (FlushReport, error) {
+	a.mu.Lock()
+	closed := a.closed
+	a.mu.Unlock()
+	if closed {
+		return FlushReport{}, ErrClosed
+	}
+	return a.flush(ctx)
+}
+
+func (a *AsyncClient) flush(ctx context.Context) (FlushReport, error) {
+	a.sendMu.Lock()
+	defer a.sendMu.Unlock()
+
+	a.mu.Lock()
+	pending := a.queue
+	a.queue = nil
+	a.notFull.Broadcast()
+	a.client.metrics.QueueDepth(0)
+	a.mu.Unlock()
+
+	// Send the most important entries first, so that they go out in the
+	// first batches and are the last to be cut off by a deadline.
+	slices.SortStableFunc(pending, func(x, y LogEntry) int {
+		return int(priorityOf(y) - priorityOf(x))
+	})
+
+	var report FlushReport
+	if len(pending) > 0 {
+		failed, err := a.client.logBatch(ctx, pending)
+		if a.acks != nil {
+			a.acks.delivered(pending, failed)
+		}
+		report.Sent = len(pending) - len(failed)
+		if len(failed) > 0 && a.cfg.Spill != nil {
+			dropped, err := a.spill(failed)
+			report.Spilled = len(failed) - dropped
+			report.Dropped = dropped
+			return report, err
+		}
+		if err != nil {
+			a.drop(dropReason(err), failed...)
+			report.Dropped = len(failed)
+			return report, err
+		}
+	}
+	if a.cfg.Spill != nil && a.cfg.Spill.Len() > 0 && a.client.BreakerState() != BreakerOpen {
+		before := a.cfg.Spill.Len()
+		err := a.cfg.Spill.Replay(func(entries []LogEntry) ([]LogEntry, error) {
+			failed, err := a.client.logBatch(ctx, entries)
+			if a.acks != nil {
+				a.acks.delivered(entries, failed)
+			}
+			return failed, err
+		})
+		report.Sent += before - a.cfg.Spill.Len()
+		return report, err
+	}
+	return report, nil
+}
+
+// spill writes failed entries to disk and returns how many the size cap
+// rejected.
+func (a *AsyncClient) spill(failed []LogEntry) (int, error) {
+	before := a.cfg.Spill.Dropped()
+	err := a.cfg.Spill.Append(failed)
+	n := int(a.cfg.Spill.Dropped() - before)
+	a.drop(DropSpillFull, failed[len(failed)-n:]...)
+	return n, err
+}
+
+// Close stops the background flusher and drains the queue.
+// Logging after Close returns ErrClosed.
+func (a *AsyncClient) Close() error {
+	_, err := a.CloseContext(context.Background())
+	return err
+}
+
+// CloseContext is Close bounded by ctx. If the background flusher does not
+// stop before ctx expires, its in-flight send is cancelled, the entries
+// still queued are dropped and ctx's error is returned. Calling it again,
+// or Flush, Log or Close after it, returns ErrClosed without doing
+// anything.
+func (a *AsyncClient) CloseContext(ctx context.Context) (FlushReport, error) {
+	a.mu.Lock()
+	if a.closed || a.closing {
+		a.mu.Unlock()
+		return FlushReport{}, ErrClosed
+	}
+	a.closing = true
+	a.mu.Unlock()
+
+	// From here on a send stuck past ctx is cancelled, including while
+	// rollups wait for room in the queue.
+	stop := context.AfterFunc(ctx, a.cancelRun)
+	defer stop()
+
+	// Open dedup windows are queued as rollups before the queue closes.
+	// Rollups that do not fit before ctx ends are dropped like any other
+	// entry.
+	lostRollups := 0
+	if d := a.client.dedup; d != nil {
+		for _, rollup := range d.drain() {
+			if err := a.enqueueContext(ctx, a.client.finish(ctx, rollup)); err != nil {
+				if !errors.Is(err, ErrQueueFull) { // already dropped by enqueue
+					a.drop(DropShutdown, rollup)
+				}
+				lostRollups++
+			}
+		}
+	}
+
+	a.mu.Lock()
+	a.closed = true
+	a.notFull.Broadcast()
+	a.mu.Unlock()
+
+	close(a.done)
+	select {
+	case <-a.stopped:
+	case <-ctx.Done():
+		a.mu.Lock()
+		lost := a.queue
+		a.queue = nil
+		a.client.metrics.QueueDepth(0)
+		a.mu.Unlock()
+		a.drop(DropShutdown, lost...)
+		return FlushReport{Dropped: lostRollups + len(lost)}, ctx.Err()
+	}
+	report, err := a.flush(ctx)
+	report.Dropped += lostRollups
+	return report, err
+}
+
+// signal wakes the flusher without blocking. Callers hold a.mu.
+func (a *AsyncClient) signal() {
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (a *AsyncClient) run() {
+	defer close(a.stopped)
+	defer a.cancelRun()
+
+	ticker := time.NewTicker(a.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.done:
+			return
+		case <-ticker.C:
+		case <-a.wake:
+		}
+		if _, err := a.flush(a.runCtx); err != nil && a.cfg.ErrorHandler != nil {
+			a.cfg.ErrorHandler(err)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAsyncClientCloseDrains(t *testing.T) {
+	rec, srv := newRecorder(t)
+	async := NewAsyncClient(NewNfoClient(srv.URL), AsyncConfig{FlushInterval: time.Hour})
+
+	for _, cmd := range []string{"a", "b", "c"} {
+		if err := async.Log(LogEntry{Cmd: cmd}); err != nil {
+			t.Fatalf("Log(%s): %v", cmd, err)
+		}
+	}
+	if err := async.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := rec.Entries(); len(got) != 3 {
+		t.Fatalf("expected 3 entries after Close, got %d", len(got))
+	}
+	if rec.Requests() != 1 {
+		t.Fatalf("expected entries coalesced into 1 batch, got %d requests", rec.Requests())
+	}
+	if err := async.Log(LogEntry{Cmd: "late"}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestAsyncClientBackgroundFlush(t *testing.T) {
+	rec, srv := newRecorder(t)
+	async := NewAsyncClient(NewNfoClient(srv.URL), AsyncConfig{FlushInterval: 10 * time.Millisecond})
+	defer async.Close()
+
+	if err := async.Log(LogEntry{Cmd: "tick"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.Entries()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("entry was not flushed in the background")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAsyncClientDropNewest(t *testing.T) {
+	async := newAsyncClient(NewNfoClient("http://unused"), AsyncConfig{QueueSize: 2, Overflow: DropNewest})
+
+	async.Log(LogEntry{Cmd: "1"})
+	async.Log(LogEntry{Cmd: "2"})
+	if err := async.Log(LogEntry{Cmd: "3"}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if async.Dropped() != 1 || async.queue[1].Cmd != "2" {
+		t.Fatalf("unexpected state: dropped=%d queue=%+v", async.Dropped(), async.queue)
+	}
+}
+
+func TestAsyncClientDropOldest(t *testing.T) {
+	async := newAsyncClient(NewNfoClient("http://unused"), AsyncConfig{QueueSize: 2, Overflow: DropOldest})
+
+	for _, cmd := range []string{"1", "2", "3"} {
+		if err := async.Log(LogEntry{Cmd: cmd}); err != nil {
+			t.Fatalf("Log(%s): %v", cmd, err)
+		}
+	}
+	if async.Dropped() != 1 || async.queue[0].Cmd != "2" || async.queue[1].Cmd != "3" {
+		t.Fatalf("unexpected state: dropped=%d queue=%+v", async.Dropped(), async.queue)
+	}
+}
+
+func TestAsyncClientBlock(t *testing.T) {
+	rec, srv := newRecorder(t)
+	async := NewAsyncClient(NewNfoClient(srv.URL), AsyncConfig{
+		QueueSize:     1,
+		FlushInterval: time.Hour,
+		Overflow:      Block,
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		async.Log(LogEntry{Cmd: "1"})
+		done <- async.Log(LogEntry{Cmd: "2"})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("blocked Log was never released by the flusher")
+	}
+	if err := async.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got := rec.Entries(); len(got) != 2 || async.Dropped() != 0 {
+		t.Fatalf("expected 2 delivered, 0 dropped; got %d, %d", len(got), async.Dropped())
+	}
+}
+
+func TestAsyncClientFlushContextReport(t *testing.T) {
+	rec, srv := newRecorder(t)
+	async := newAsyncClient(NewNfoClient(srv.URL), AsyncConfig{})
+
+	async.Log(LogEntry{Cmd: "a"})
+	async.Log(LogEntry{Cmd: "b"})
+	report, err := async.FlushContext(context.Background())
+	if err != nil || report != (FlushReport{Sent: 2}) {
+		t.Fatalf("FlushContext = %+v, %v", report, err)
+	}
+
+	rec.SetStatus(http.StatusInternalServerError)
+	async.Log(LogEntry{Cmd: "c"})
+	report, err = async.FlushContext(context.Background())
+	if err == nil || report != (FlushReport{Dropped: 1}) {
+		t.Fatalf("FlushContext = %+v, %v", report, err)
+	}
+}
+
+// stuckTransport blocks every Send until release is closed.
+type stuckTransport struct {
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *stuckTransport) Send(context.Context, []LogEntry) error {
+	s.once.Do(func() { close(s.started) })
+	<-s.release
+	return nil
+}
+
+func TestAsyncClientCloseContextDeadline(t *testing.T) {
+	tr := &stuckTransport{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(tr.release)
+	async := NewAsyncClient(NewClient("http://unused", WithTransport(tr)),
+		AsyncConfig{FlushInterval: time.Millisecond})
+
+	async.Log(LogEntry{Cmd: "in-flight"})
+	<-tr.started
+	async.Log(LogEntry{Cmd: "a"})
+	async.Log(LogEntry{Cmd: "b"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	report, err := async.CloseContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || report.Dropped != 2 {
+		t.Fatalf("CloseContext = %+v, %v", report, err)
+	}
+	if async.Len() != 0 || async.Dropped() != 2 {
+		t.Fatalf("len=%d dropped=%d", async.Len(), async.Dropped())
+	}
+}
+
+// ctxTransport blocks each Send until its context ends.
+type ctxTransport struct {
+	started chan struct{}
+	once    sync.Once
+}
+
+func (s *ctxTransport) Send(ctx context.Context, _ []LogEntry) error {
+	s.once.Do(func() { close(s.started) })
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestAsyncClientCloseContextCancelsFlush(t *testing.T) {
+	tr := &ctxTransport{started: make(chan struct{})}
+	var handled atomic.Int32
+	async := NewAsyncClient(NewClient("http://unused", WithTransport(tr)),
+		AsyncConfig{FlushInterval: time.Millisecond, ErrorHandler: func(error) { handled.Add(1) }})
+
+	async.Log(LogEntry{Cmd: "in-flight"})
+	<-tr.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := async.CloseContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CloseContext: %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("CloseContext took %v", d)
+	}
+	select {
+	case <-async.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("in-flight flush was not cancelled")
+	}
+	if handled.Load() != 1 || async.Dropped() != 1 {
+		t.Fatalf("handled=%d dropped=%d", handled.Load(), async.Dropped())
+	}
+}
+
+func TestAsyncClientAfterClose(t *testing.T) {
+	_, srv := newRecorder(t)
+	async := NewAsyncClient(NewNfoClient(srv.URL), AsyncConfig{FlushInterval: time.Hour})
+
+	async.Log(LogEntry{Cmd: "a"})
+	report, err := async.CloseContext(context.Background())
+	if err != nil || report.Sent != 1 {
+		t.Fatalf("CloseContext = %+v, %v", report, err)
+	}
+	if _, err := async.FlushContext(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("FlushContext after close: %v", err)
+	}
+	if _, err := async.CloseContext(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("CloseContext after close: %v", err)
+	}
+	if err := async.Close(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Close after close: %v", err)
+	}
+}
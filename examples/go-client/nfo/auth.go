@@ -0,0 +1,49 @@
+package nfo
+
+import (
+	"errors"
+	"net/http"
+)
+
+// authFunc decorates an outgoing request with credentials.
+type authFunc func(req *http.Request) error
+
+// WithAPIKey sends value in header on every request, e.g.
+// WithAPIKey("X-API-Key", os.Getenv("NFO_API_KEY")).
+func WithAPIKey(header, value string) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.auth = append(cfg.client.auth, func(req *http.Request) error {
+			req.Header.Set(header, value)
+			return nil
+		})
+	}
+}
+
+// WithBearerToken sends "Authorization: Bearer <token>" on every request.
+// token is called per request, so it may return a rotating credential; it
+// should cache the value itself if fetching is expensive.
+func WithBearerToken(token func() (string, error)) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.auth = append(cfg.client.auth, func(req *http.Request) error {
+			t, err := token()
+			if err != nil {
+				return err
+			}
+			if t == "" {
+				return errors.New("empty bearer token")
+			}
+			req.Header.Set("Authorization", "Bearer "+t)
+			return nil
+		})
+	}
+}
+
+// WithBasicAuth sends HTTP basic credentials on every request.
+func WithBasicAuth(username, password string) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.auth = append(cfg.client.auth, func(req *http.Request) error {
+			req.SetBasicAuth(username, password)
+			return nil
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestWithAPIKey(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithAPIKey("X-API-Key", "secret"))
+
+	client.LogBatch([]LogEntry{{Cmd: "a"}, {Cmd: "b"}})
+	if got := rec.Header().Get("X-API-Key"); got != "secret" {
+		t.Fatalf("X-API-Key = %q, want secret", got)
+	}
+}
+
+func TestWithBearerTokenRotates(t *testing.T) {
+	rec, srv := newRecorder(t)
+	tokens := []string{"one", "two"}
+	client := NewClient(srv.URL, WithBearerToken(func() (string, error) {
+		tok := tokens[0]
+		tokens = tokens[1:]
+		return tok, nil
+	}))
+
+	client.Log(LogEntry{Cmd: "a"})
+	if got := rec.Header().Get("Authorization"); got != "Bearer one" {
+		t.Fatalf("first Authorization = %q", got)
+	}
+	client.Log(LogEntry{Cmd: "b"})
+	if got := rec.Header().Get("Authorization"); got != "Bearer two" {
+		t.Fatalf("second Authorization = %q", got)
+	}
+}
+
+func TestWithBearerTokenError(t *testing.T) {
+	rec, srv := newRecorder(t)
+	boom := errors.New("vault sealed")
+	client := NewClient(srv.URL, WithBearerToken(func() (string, error) { return "", boom }))
+
+	if err := client.Log(LogEntry{Cmd: "a"}); !errors.Is(err, boom) {
+		t.Fatalf("expected token error, got %v", err)
+	}
+	if rec.Attempts() != 0 {
+		t.Fatal("request must not be sent without credentials")
+	}
+}
+
+func TestWithBasicAuthOnQuery(t *testing.T) {
+	var user, pass string
+	srv := newLogsServer(t, 0, func(r *http.Request) { user, pass, _ = r.BasicAuth() })
+	client := NewClient(srv.URL, WithBasicAuth("ops", "hunter2"))
+
+	if _, err := client.Query(context.Background(), QueryParams{}); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if user != "ops" || pass != "hunter2" {
+		t.Fatalf("basic auth = %q/%q", user, pass)
+	}
+}
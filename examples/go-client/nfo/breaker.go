@@ -0,0 +1,183 @@
+package nfo
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting nfo-service while the
+// circuit breaker is open.
+var ErrCircuitOpen = errors.New("nfo: circuit open")
+
+// BreakerState is the state of the client's circuit breaker.
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects requests with ErrCircuitOpen.
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of probe requests through.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// BreakerConfig configures WithCircuitBreaker. Zero values select defaults.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failed requests that
+	// opens the circuit (default 5).
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open before probing
+	// (default 30s).
+	OpenDuration time.Duration
+	// HalfOpenProbes is how many probe requests may run while half-open;
+	// that many must succeed to close the circuit again (default 1).
+	HalfOpenProbes int
+	// OnStateChange, if set, is called after every transition.
+	OnStateChange func(from, to BreakerState)
+}
+
+// WithCircuitBreaker stops the client from calling nfo-service after
+// repeated failures, so a dead backend costs callers an immediate
+// ErrCircuitOpen instead of a timeout. Only failures that WithRetry would
+// retry (transport errors, 429, 5xx) count; a request's retries count once.
+//
+// An AsyncClient keeps working while the circuit is open: affected entries
+// go to its Spill if one is configured and are dropped otherwise.
+func WithCircuitBreaker(cfg BreakerConfig) Option {
+	return func(c *clientConfig) {
+		c.client.breaker = newBreaker(cfg)
+	}
+}
+
+// BreakerState reports the circuit state; BreakerClosed without a breaker.
+func (c *NfoClient) BreakerState() BreakerState {
+	if c.breaker == nil {
+		return BreakerClosed
+	}
+	return c.breaker.State()
+}
+
+type breaker struct {
+	cfg BreakerConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	gen       uint64 // advanced by every transition
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+}
+
+func newBreaker(cfg BreakerConfig) *breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = 30 * time.Second
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	return &breaker{cfg: cfg, now: time.Now}
+}
+
+func (b *breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow reports whether a request may be sent now, and the generation to
+// pass to record with its outcome.
+func (b *breaker) allow() (uint64, bool) {
+	b.mu.Lock()
+	notify := func() {}
+	defer func() {
+		b.mu.Unlock()
+		notify()
+	}()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.OpenDuration {
+			return 0, false
+		}
+		notify = b.set(BreakerHalfOpen)
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.cfg.HalfOpenProbes {
+			return 0, false
+		}
+		b.probes++
+	}
+	return b.gen, true
+}
+
+// record reports the outcome of a request admitted by allow in generation
+// gen. Outcomes of requests admitted before the last transition are
+// ignored: a request let through while closed that completes once the
+// circuit is half-open is not one of its probes.
+func (b *breaker) record(gen uint64, ok bool) {
+	b.mu.Lock()
+	notify := func() {}
+	defer func() {
+		b.mu.Unlock()
+		notify()
+	}()
+	if gen != b.gen {
+		return
+	}
+
+	switch b.state {
+	case BreakerClosed:
+		if ok {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			notify = b.set(BreakerOpen)
+		}
+	case BreakerHalfOpen:
+		b.probes--
+		if !ok {
+			notify = b.set(BreakerOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenProbes {
+			notify = b.set(BreakerClosed)
+		}
+	}
+}
+
+// set moves to state to and returns the hook call to run once b.mu is
+// released. Callers hold b.mu.
+func (b *breaker) set(to BreakerState) func() {
+	from := b.state
+	b.state = to
+	b.gen++
+	b.failures, b.probes, b.successes = 0, 0, 0
+	if to == BreakerOpen {
+		b.openedAt = b.now()
+	}
+	if b.cfg.OnStateChange == nil || from == to {
+		return func() {}
+	}
+	return func() { b.cfg.OnStateChange(from, to) }
+}
@@ -0,0 +1,134 @@
+package nfo
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	rec, srv := newRecorder(t)
+	var transitions []string
+	client := NewClient(srv.URL, WithCircuitBreaker(BreakerConfig{
+		FailureThreshold: 2,
+		OpenDuration:     time.Minute,
+		OnStateChange: func(from, to BreakerState) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	}))
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+
+	rec.SetStatus(http.StatusServiceUnavailable)
+	client.Log(LogEntry{Cmd: "a"})
+	client.Log(LogEntry{Cmd: "b"})
+	if client.BreakerState() != BreakerOpen {
+		t.Fatalf("expected open after 2 failures, got %s", client.BreakerState())
+	}
+
+	attempts := rec.Attempts()
+	if err := client.Log(LogEntry{Cmd: "c"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if rec.Attempts() != attempts {
+		t.Fatal("open circuit must not contact the service")
+	}
+
+	rec.SetStatus(http.StatusOK)
+	now = now.Add(time.Minute)
+	if err := client.Log(LogEntry{Cmd: "probe"}); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if client.BreakerState() != BreakerClosed {
+		t.Fatalf("expected closed after successful probe, got %s", client.BreakerState())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("transitions = %v, want %v", transitions, want)
+		}
+	}
+}
+
+func TestBreakerFailedProbeReopens(t *testing.T) {
+	b := newBreaker(BreakerConfig{FailureThreshold: 1, OpenDuration: time.Second})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	gen, _ := b.allow()
+	b.record(gen, false)
+	now = now.Add(time.Second)
+
+	gen, ok := b.allow()
+	if !ok {
+		t.Fatal("first probe should be admitted")
+	}
+	if _, ok := b.allow(); ok {
+		t.Fatal("only one probe may run while half-open")
+	}
+	b.record(gen, false)
+	if b.State() != BreakerOpen {
+		t.Fatalf("failed probe should reopen, got %s", b.State())
+	}
+}
+
+func TestBreakerIgnoresStaleOutcomes(t *testing.T) {
+	b := newBreaker(BreakerConfig{FailureThreshold: 1, OpenDuration: time.Second})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	slow, _ := b.allow() // admitted while closed, completes late
+	gen, _ := b.allow()
+	b.record(gen, false)
+	now = now.Add(time.Second)
+
+	probe, ok := b.allow()
+	if !ok {
+		t.Fatal("first probe should be admitted")
+	}
+	b.record(slow, true)
+	if _, ok := b.allow(); ok {
+		t.Fatal("a late closed-state request must not free a probe slot")
+	}
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("a late closed-state request must not close the circuit, got %s", b.State())
+	}
+	b.record(probe, true)
+	if b.State() != BreakerClosed {
+		t.Fatalf("expected closed after successful probe, got %s", b.State())
+	}
+}
+
+func TestBreakerIgnoresClientErrors(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithCircuitBreaker(BreakerConfig{FailureThreshold: 1}))
+
+	rec.SetStatus(http.StatusBadRequest)
+	client.Log(LogEntry{Cmd: "bad"})
+	if client.BreakerState() != BreakerClosed {
+		t.Fatal("4xx responses mean the service is up and must not open the circuit")
+	}
+}
+
+func TestAsyncClientSpillsWhileOpen(t *testing.T) {
+	rec, srv := newRecorder(t)
+	spill, _ := OpenSpill(t.TempDir(), 0)
+	client := NewClient(srv.URL, WithCircuitBreaker(BreakerConfig{FailureThreshold: 1, OpenDuration: time.Hour}))
+	async := NewAsyncClient(client, AsyncConfig{FlushInterval: time.Hour, Spill: spill})
+	defer async.Close()
+
+	rec.SetStatus(http.StatusBadGateway)
+	async.Log(LogEntry{Cmd: "a"})
+	async.Flush()
+	async.Log(LogEntry{Cmd: "b"})
+	async.Flush()
+
+	if spill.Len() != 2 || rec.Attempts() != 1 {
+		t.Fatalf("spill=%d attempts=%d", spill.Len(), rec.Attempts())
+	}
+}
@@ -0,0 +1,50 @@
+package nfo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// contextLogger is implemented by loggers that can bind a send to a context.
+type contextLogger interface {
+	LogContext(ctx context.Context, entry LogEntry) error
+}
+
+// logContext logs entry through logger, passing ctx along when supported.
+func logContext(ctx context.Context, logger Logger, entry LogEntry) error {
+	if cl, ok := logger.(contextLogger); ok {
+		return cl.LogContext(ctx, entry)
+	}
+	return logger.Log(entry)
+}
+
+// Call runs fn, logs its success, duration and error through logger, and
+// returns fn's result unchanged. Unlike LogCall it works with any result
+// type, so real business functions can be wrapped directly:
+//
+//	user, err := nfo.Call(ctx, client, "load_user", []string{id}, func() (*User, error) {
+//	    return repo.Load(ctx, id)
+//	})
+//
+// Logging failures are not reported; the caller always sees fn's own error.
+func Call[T any](ctx context.Context, logger Logger, cmd string, args []string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	result, err := fn()
+	duration := float64(time.Since(start).Milliseconds())
+
+	success := err == nil
+	entry := LogEntry{
+		Cmd:        cmd,
+		Args:       args,
+		Language:   "go",
+		Success:    &success,
+		DurationMs: &duration,
+		Fields:     map[string]any{"return_type": fmt.Sprintf("%T", result)},
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	logContext(ctx, logger, entry)
+	return result, err
+}
@@ -0,0 +1,57 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type user struct{ Name string }
+
+func TestCallReturnsResult(t *testing.T) {
+	mem := &memLogger{}
+
+	u, err := Call(context.Background(), mem, "load_user", []string{"42"}, func() (*user, error) {
+		return &user{Name: "ada"}, nil
+	})
+	if err != nil || u.Name != "ada" {
+		t.Fatalf("Call = %v, %v", u, err)
+	}
+	e := mem.Entries()[0]
+	if e.Cmd != "load_user" || !*e.Success || e.DurationMs == nil {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if e.Fields["return_type"] != "*nfo.user" {
+		t.Fatalf("unexpected return_type: %v", e.Fields["return_type"])
+	}
+}
+
+func TestCallReturnsError(t *testing.T) {
+	mem := &memLogger{}
+	boom := errors.New("not found")
+
+	n, err := Call(context.Background(), mem, "count", nil, func() (int, error) {
+		return 0, boom
+	})
+	if n != 0 || !errors.Is(err, boom) {
+		t.Fatalf("Call = %v, %v", n, err)
+	}
+	if e := mem.Entries()[0]; *e.Success || e.Error != "not found" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+}
+
+func TestCallUsesClientContext(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	v, err := Call(ctx, client, "noop", nil, func() (string, error) { return "ok", nil })
+	if v != "ok" || err != nil {
+		t.Fatalf("Call = %v, %v", v, err)
+	}
+	if len(rec.Entries()) != 0 {
+		t.Fatal("cancelled context should abort the log request")
+	}
+}
@@ -0,0 +1,228 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIVersion is the version of the nfo-service API this client speaks. It
+// is sent in APIVersionHeader with every request.
+const (
+	APIVersion       = "1"
+	APIVersionHeader = "X-Nfo-Api-Version"
+)
+
+// ErrUnsupported is returned by calls the server has reported, through
+// Capabilities, that it does not support.
+var ErrUnsupported = errors.New("nfo: not supported by nfo-service")
+
+// Capabilities describes what an nfo-service instance supports, as
+// reported by GET /capabilities.
+type Capabilities struct {
+	// APIVersion is the API version the service implements.
+	APIVersion string `json:"api_version"`
+	// Fields are the LogEntry JSON keys the service accepts. Empty means
+	// it did not say, and entries are sent unchanged.
+	Fields []string `json:"fields,omitempty"`
+	// Codecs are the Content-Types accepted for log requests.
+	Codecs []string `json:"codecs,omitempty"`
+	// Compression lists the accepted Content-Encodings, e.g. "gzip".
+	Compression []string `json:"compression,omitempty"`
+	// Batch reports POST /logs/batch, Query GET /logs and Streaming GET
+	// /logs/stream.
+	Batch     bool `json:"batch"`
+	Query     bool `json:"query"`
+	Streaming bool `json:"streaming"`
+	// Acknowledgements reports that log responses list the IDs of stored
+	// entries and that POST /logs/ack confirms entries; see
+	// WithAcknowledgements.
+	Acknowledgements bool `json:"acknowledgements"`
+}
+
+// LegacyCapabilities describes a service that predates capability
+// negotiation and answers 404 to GET /capabilities: single JSON entries on
+// POST /log with the original fields, and queries on GET /logs.
+var LegacyCapabilities = Capabilities{
+	APIVersion: "0",
+	Fields:     []string{"cmd", "args", "language", "env", "success", "duration_ms", "output", "error"},
+	Codecs:     []string{ContentTypeJSON},
+	Query:      true,
+}
+
+// Capabilities asks nfo-service what it supports. A service without the
+// GET /capabilities endpoint is reported as LegacyCapabilities.
+func (c *NfoClient) Capabilities(ctx context.Context) (Capabilities, error) {
+	data, err := c.do(ctx, http.MethodGet, "/capabilities", "", nil)
+	var ae *APIError
+	if errors.As(err, &ae) && ae.StatusCode == http.StatusNotFound {
+		return LegacyCapabilities, nil
+	}
+	if err != nil {
+		return Capabilities{}, err
+	}
+	var caps Capabilities
+	if err := json.Unmarshal(data, &caps); err != nil {
+		return Capabilities{}, fmt.Errorf("unmarshal: %w", err)
+	}
+	return caps, nil
+}
+
+// WithNegotiation makes the client adapt to the capabilities of the
+// nfo-service it talks to, so one client version works against a fleet of
+// older and newer services. Before its first log request to an endpoint,
+// and again every refresh (default 5m), the client calls Capabilities and
+// then:
+//
+//   - leaves out LogEntry fields the service does not accept,
+//   - posts entries one by one if it has no batch endpoint,
+//   - sends JSON if it does not accept the configured codec,
+//   - skips compression it does not accept, and
+//   - fails Query and TailLogs with ErrUnsupported if it lacks them.
+//
+// If the capabilities cannot be fetched, requests are sent unchanged and
+// the fetch is retried after refresh. Negotiation applies to HTTP only; it
+// has no effect with WithTransport.
+func WithNegotiation(refresh time.Duration) Option {
+	return func(c *clientConfig) {
+		if refresh <= 0 {
+			refresh = 5 * time.Minute
+		}
+		c.client.negotiator = &negotiator{refresh: refresh, endpoints: make(map[string]*negotiated)}
+	}
+}
+
+// negotiator caches the capabilities of each endpoint.
+type negotiator struct {
+	refresh time.Duration
+
+	mu        sync.Mutex
+	endpoints map[string]*negotiated
+}
+
+// negotiated is the result of one Capabilities call; caps is nil if it
+// failed.
+type negotiated struct {
+	caps    *Capabilities
+	fields  map[string]bool
+	fetched time.Time
+}
+
+// negotiate returns the capabilities of the active endpoint, fetching them
+// if they are missing or stale, or nil if they are unknown.
+func (c *NfoClient) negotiate(ctx context.Context) *negotiated {
+	n := c.negotiator
+	if n == nil || c.transport != nil {
+		return nil
+	}
+	endpoint := c.Endpoint()
+	n.mu.Lock()
+	cur := n.endpoints[endpoint]
+	n.mu.Unlock()
+	if cur != nil && time.Since(cur.fetched) < n.refresh {
+		return cur.known()
+	}
+
+	next := &negotiated{fetched: time.Now()}
+	if caps, err := c.Capabilities(ctx); err == nil {
+		next.caps = &caps
+		if len(caps.Fields) > 0 {
+			next.fields = make(map[string]bool, len(caps.Fields))
+			for _, f := range caps.Fields {
+				next.fields[f] = true
+			}
+		}
+	} else if cur != nil {
+		next.caps, next.fields = cur.caps, cur.fields // keep the last known
+	}
+	n.mu.Lock()
+	n.endpoints[endpoint] = next
+	n.mu.Unlock()
+	return next.known()
+}
+
+// cachedCapabilities returns the capabilities last fetched for endpoint
+// without fetching, or nil.
+func (c *NfoClient) cachedCapabilities(endpoint string) *negotiated {
+	n := c.negotiator
+	if n == nil {
+		return nil
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.endpoints[endpoint].known()
+}
+
+func (n *negotiated) known() *negotiated {
+	if n == nil || n.caps == nil {
+		return nil
+	}
+	return n
+}
+
+// codec returns codec if the service accepts it, or JSON.
+func (n *negotiated) codec(codec Codec) Codec {
+	if n == nil || len(n.caps.Codecs) == 0 || slices.Contains(n.caps.Codecs, codec.ContentType()) {
+		return codec
+	}
+	return JSONCodec
+}
+
+// batch reports whether entries may be sent to POST /logs/batch.
+func (n *negotiated) batch() bool {
+	return n == nil || n.caps.Batch
+}
+
+// compression reports whether the service accepts encoding.
+func (n *negotiated) compression(encoding string) bool {
+	return n == nil || slices.Contains(n.caps.Compression, encoding)
+}
+
+// require fails with ErrUnsupported unless supported.
+func (n *negotiated) require(supported func(Capabilities) bool, what string) error {
+	if n == nil || supported(*n.caps) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (API version %s)", ErrUnsupported, what, n.caps.APIVersion)
+}
+
+// strip clears the fields of entry the service does not accept.
+func (n *negotiated) strip(entry LogEntry) LogEntry {
+	if n == nil || n.fields == nil {
+		return entry
+	}
+	v := reflect.ValueOf(&entry).Elem()
+	for _, f := range entryJSONFields {
+		if !n.fields[f.name] {
+			v.Field(f.index).SetZero()
+		}
+	}
+	return entry
+}
+
+// entryField is a JSON-encoded field of LogEntry.
+type entryField struct {
+	name  string
+	index int
+}
+
+// entryJSONFields lists the JSON-encoded fields of LogEntry other than
+// cmd, which every service accepts.
+var entryJSONFields = func() []entryField {
+	var fields []entryField
+	t := reflect.TypeOf(LogEntry{})
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" && name != "cmd" {
+			fields = append(fields, entryField{name, i})
+		}
+	}
+	return fields
+}()
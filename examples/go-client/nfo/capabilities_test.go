@@ -0,0 +1,94 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// newLegacyServer emulates an nfo-service that predates capability
+// negotiation: it only has POST /log and GET /logs, accepts JSON only and
+// rejects unknown fields.
+func newLegacyServer(t *testing.T) (*httptest.Server, func() []map[string]any) {
+	t.Helper()
+	var mu sync.Mutex
+	var entries []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(APIVersionHeader) != APIVersion {
+			http.Error(w, "missing API version", http.StatusBadRequest)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/log":
+			var entry map[string]any
+			if r.Header.Get("Content-Type") != ContentTypeJSON || r.Header.Get("Content-Encoding") != "" ||
+				json.NewDecoder(r.Body).Decode(&entry) != nil {
+				http.Error(w, "bad body", http.StatusUnsupportedMediaType)
+				return
+			}
+			for key := range entry {
+				if !slices.Contains(LegacyCapabilities.Fields, key) {
+					http.Error(w, "unknown field "+key, http.StatusUnprocessableEntity)
+					return
+				}
+			}
+			mu.Lock()
+			entries = append(entries, entry)
+			mu.Unlock()
+		case r.Method == http.MethodGet && r.URL.Path == "/logs":
+			w.Write([]byte("[]"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		return entries
+	}
+}
+
+func TestNegotiationLegacyServer(t *testing.T) {
+	srv, entries := newLegacyServer(t)
+
+	plain := NewClient(srv.URL, WithCompression(1))
+	if err := plain.Log(LogEntry{Cmd: "a"}); err == nil {
+		t.Fatal("expected a legacy server to reject a client without negotiation")
+	}
+
+	client := NewClient(srv.URL,
+		WithNegotiation(0),
+		WithCompression(1),
+		WithCodec(MsgpackCodec),
+		WithFields(map[string]any{"team": "core"}),
+	)
+	caps, err := client.Capabilities(context.Background())
+	if err != nil || caps.APIVersion != LegacyCapabilities.APIVersion {
+		t.Fatalf("Capabilities = %+v, %v", caps, err)
+	}
+
+	ok := false
+	if err := client.Log(LogEntry{Cmd: "a", Success: &ok, Error: "boom", Level: LevelError}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if err := client.LogBatch([]LogEntry{{Cmd: "b"}, {Cmd: "c"}}); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+	got := entries()
+	if len(got) != 3 || got[0]["error"] != "boom" || got[0]["language"] != "go" || got[2]["cmd"] != "c" {
+		t.Fatalf("entries = %v", got)
+	}
+
+	if _, err := client.Query(context.Background(), QueryParams{}); err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if _, err := client.TailLogs(context.Background(), TailFilter{}); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("TailLogs: %v, want ErrUnsupported", err)
+	}
+}
@@ -0,0 +1,592 @@
+// Package nfo is a Go HTTP client for the nfo centralized logging service.
+//
+// It sends log entries to nfo-service via HTTP POST.
+// Pair with examples/http-service/main.py.
+package nfo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// LogEntry matches the nfo-service API schema.
+type LogEntry struct {
+	Cmd        string   `json:"cmd"`
+	Args       []string `json:"args"`
+	Language   string   `json:"language"`
+	Env        string   `json:"env"`
+	Success    *bool    `json:"success,omitempty"`
+	DurationMs *float64 `json:"duration_ms,omitempty"`
+	Output     string   `json:"output,omitempty"`
+	Error      string   `json:"error,omitempty"`
+
+	// Level is the severity; see Level for how an unset level is derived.
+	Level Level `json:"level,omitempty"`
+
+	// Timestamp is when the entry was logged, in UTC. The client sets it
+	// unless it is already set and keeps it through queueing, batching,
+	// retries and Spill replay, so delays in delivery do not skew it.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+
+	// ClockSkewMs is how far the logging client's clock was ahead of
+	// nfo-service's (negative if behind) when the entry was logged; see
+	// WithClockSync.
+	ClockSkewMs *float64 `json:"clock_skew_ms,omitempty"`
+
+	// TraceID and SpanID link the entry to a distributed trace.
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
+
+	// CorrelationID groups the entries of one job or request; see
+	// WithCorrelationID.
+	CorrelationID string `json:"correlation_id,omitempty"`
+
+	// TruncatedBytes is how many bytes WithTruncation cut from the entry,
+	// and Attachments maps "output" and "error" to the IDs of their full
+	// text when it was offloaded.
+	TruncatedBytes int               `json:"truncated_bytes,omitempty"`
+	Attachments    map[string]string `json:"attachments,omitempty"`
+
+	// Fingerprint identifies repeats of the same failure, and RepeatCount
+	// is how many duplicates a rollup entry stands for; see WithDedup.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	RepeatCount int    `json:"repeat_count,omitempty"`
+
+	// ID identifies the entry to nfo-service, which acknowledges it by ID
+	// and stores it once however often it is sent; see
+	// WithAcknowledgements.
+	ID string `json:"id,omitempty"`
+
+	// Fields holds arbitrary structured data such as request or user IDs.
+	// It is sent as a nested "fields" object unless the client was built
+	// with WithFlattenFields.
+	Fields map[string]any `json:"fields,omitempty"`
+
+	// Meta carries host/process details; see Metadata.
+	Meta *Metadata `json:"meta,omitempty"`
+
+	// Priority orders entries in an AsyncClient queue; see Priority. It is
+	// never sent.
+	Priority Priority `json:"-"`
+
+	// Cursor is the stream position of an entry received from TailLogs.
+	// It is never sent; pass it as TailFilter.Cursor to resume after it.
+	Cursor string `json:"-"`
+}
+
+// Logger is anything that accepts log entries: NfoClient sends them right
+// away, AsyncClient queues them for background delivery.
+type Logger interface {
+	Log(entry LogEntry) error
+}
+
+var (
+	_ Logger = (*NfoClient)(nil)
+	_ Logger = (*AsyncClient)(nil)
+)
+
+// NfoClient sends log entries to the nfo HTTP service.
+//
+// An NfoClient is safe for concurrent use by multiple goroutines and should
+// be shared rather than created per request, so that connections are
+// reused; see WithConnectionPool. Its exported fields must not be changed
+// once it is in use.
+type NfoClient struct {
+	BaseURL    string
+	HTTPClient *http.Client
+
+	// MaxBatchSize caps the number of entries per LogBatch request.
+	MaxBatchSize int
+	// MaxBatchBytes caps the encoded size of a LogBatch request body.
+	MaxBatchBytes int
+
+	headers     http.Header
+	auth        []authFunc
+	userAgent   string
+	compressMin int
+	retry       retryPolicy
+	breaker     *breaker
+	failover    *failover
+	metrics     Metrics
+	transport   Transport
+	sampler     *sampler
+	limiter     *limiter
+	redactor    *redactor
+	truncate    *TruncateConfig
+	minLevel    Level
+	hooks       []Hook
+	clock       func() time.Time
+	codec       Codec
+	inflight    chan struct{}
+	onDrop      func(LogEntry, DropReason)
+	dedup       *deduper
+	deadLetter  *DeadLetterConfig
+	negotiator  *negotiator
+	acks        bool
+	clockSync   *clockSync
+
+	jsonFallback atomic.Bool
+
+	traceExtractor TraceExtractor
+
+	defaults      LogEntry
+	meta          *Metadata
+	flatten       bool
+	flattenPrefix string
+}
+
+// Default batch limits applied by NewNfoClient.
+const (
+	DefaultMaxBatchSize  = 100
+	DefaultMaxBatchBytes = 1 << 20
+)
+
+// NewNfoClient creates a client pointing at the given nfo-service URL.
+// It is shorthand for NewClient(baseURL).
+func NewNfoClient(baseURL string) *NfoClient {
+	return NewClient(baseURL)
+}
+
+// Log sends a single log entry to nfo-service.
+func (c *NfoClient) Log(entry LogEntry) error {
+	return c.LogContext(context.Background(), entry)
+}
+
+// LogContext is Log bound to ctx, which cancels the request and any retries.
+// The entry is linked to the trace span and correlation ID in ctx.
+func (c *NfoClient) LogContext(ctx context.Context, entry LogEntry) error {
+	entry, ok, err := c.accept(ctx, entry)
+	if !ok {
+		return err
+	}
+	entry = c.prepare(c.stampContext(ctx, entry))
+	if c.transport != nil {
+		if err := c.deliver(ctx, []LogEntry{entry}); err != nil {
+			return err
+		}
+		c.metrics.EntriesSent(1)
+		return nil
+	}
+	if err := c.postEntry(ctx, entry); err != nil {
+		return err
+	}
+	c.metrics.EntriesSent(1)
+	return nil
+}
+
+// postEntry sends a prepared entry to POST /log in the client's wire format.
+func (c *NfoClient) postEntry(ctx context.Context, entry LogEntry) error {
+	n := c.negotiate(ctx)
+	if err := c.requireAcks(n); err != nil {
+		return err
+	}
+	codec := n.codec(c.wireCodec())
+	data, err := c.encode(codec, n.strip(entry))
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/log", codec.ContentType(), data)
+	if c.fellBack(codec, err) {
+		return c.postEntry(ctx, entry)
+	}
+	if err == nil && c.acks {
+		_, err = c.awaitAcks(ctx, "/log", codec, []LogEntry{entry}, [][]byte{data}, resp)
+	}
+	return err
+}
+
+// LogBatch sends entries to nfo-service's batch endpoint, splitting them
+// into as many requests as MaxBatchSize and MaxBatchBytes require.
+func (c *NfoClient) LogBatch(entries []LogEntry) error {
+	entries, acceptErr := c.acceptAll(context.Background(), entries)
+	_, err := c.logBatch(context.Background(), entries)
+	return errors.Join(acceptErr, err)
+}
+
+// logBatch is LogBatch bound to ctx that also returns the entries whose
+// request failed.
+func (c *NfoClient) logBatch(ctx context.Context, entries []LogEntry) ([]LogEntry, error) {
+	n := c.negotiate(ctx)
+	if err := c.requireAcks(n); err != nil {
+		return entries, err
+	}
+	if !n.batch() {
+		return c.postEach(ctx, entries)
+	}
+	codec := n.codec(c.wireCodec())
+	prepared := make([]LogEntry, len(entries))
+	encoded := make([][]byte, 0, len(entries))
+	for i, entry := range entries {
+		prepared[i] = c.prepare(entry)
+		data, err := c.encode(codec, n.strip(prepared[i]))
+		if err != nil {
+			return entries, err
+		}
+		encoded = append(encoded, data)
+	}
+
+	var (
+		failed []LogEntry
+		errs   []error
+		offset int
+	)
+	for _, chunk := range splitBatch(encoded, c.MaxBatchSize, c.MaxBatchBytes) {
+		batch := entries[offset : offset+len(chunk)]
+		var err error
+		if c.transport != nil {
+			err = c.deliver(ctx, prepared[offset:offset+len(chunk)])
+		} else {
+			var resp []byte
+			resp, err = c.do(ctx, http.MethodPost, "/logs/batch", codec.ContentType(), codec.Batch(chunk))
+			if c.fellBack(codec, err) {
+				retryFailed, err := c.logBatch(ctx, entries[offset:])
+				return append(failed, retryFailed...), errors.Join(append(errs, err)...)
+			}
+			if err == nil && c.acks {
+				var missing []int
+				missing, err = c.awaitAcks(ctx, "/logs/batch", codec, prepared[offset:offset+len(chunk)], chunk, resp)
+				if acked := len(chunk) - len(missing); err != nil && acked > 0 {
+					c.metrics.EntriesSent(acked)
+				}
+				batch = entriesAt(batch, missing)
+			}
+		}
+		if err != nil {
+			failed = append(failed, batch...)
+			errs = append(errs, err)
+		} else {
+			c.metrics.EntriesSent(len(chunk))
+			c.metrics.BatchFlushed(len(chunk))
+		}
+		offset += len(chunk)
+	}
+	return failed, errors.Join(errs...)
+}
+
+// postEach sends entries one by one to POST /log, for services without a
+// batch endpoint, and returns the ones that failed.
+func (c *NfoClient) postEach(ctx context.Context, entries []LogEntry) ([]LogEntry, error) {
+	var (
+		failed []LogEntry
+		errs   []error
+	)
+	for _, entry := range entries {
+		if err := c.postEntry(ctx, c.prepare(entry)); err != nil {
+			failed = append(failed, entry)
+			errs = append(errs, err)
+			continue
+		}
+		c.metrics.EntriesSent(1)
+	}
+	return failed, errors.Join(errs...)
+}
+
+// splitBatch groups encoded entries so that no group holds more than
+// maxSize entries or, once joined into a batch, exceeds about maxBytes.
+// An entry larger than maxBytes on its own is sent alone.
+func splitBatch(encoded [][]byte, maxSize, maxBytes int) [][][]byte {
+	var (
+		chunks [][][]byte
+		cur    [][]byte
+		size   = 2 // "[]"
+	)
+	for _, data := range encoded {
+		full := maxSize > 0 && len(cur) >= maxSize
+		big := maxBytes > 0 && len(cur) > 0 && size+len(data)+1 > maxBytes
+		if full || big {
+			chunks = append(chunks, cur)
+			cur, size = nil, 2
+		}
+		cur = append(cur, data)
+		size += len(data) + 1
+	}
+	if len(cur) > 0 {
+		chunks = append(chunks, cur)
+	}
+	return chunks
+}
+
+// accept timestamps entry and runs it through the client's intake stages:
+// hooks, level filtering, deduplication, sampling, rate limiting, redaction
+// and truncation. It reports false for entries that must not be sent; the error
+// is nil when they were merely filtered out.
+func (c *NfoClient) accept(ctx context.Context, entry LogEntry) (LogEntry, bool, error) {
+	entry, ok, err := c.runHooks(ctx, c.stampTime(entry))
+	if !ok {
+		return entry, false, err
+	}
+	if levelOf(entry) < c.minLevel {
+		return entry, false, nil
+	}
+	if c.dedup != nil {
+		if entry, ok = c.dedup.check(entry); !ok {
+			return entry, false, nil
+		}
+	}
+	if ok, err := c.admit(ctx, entry); !ok {
+		return entry, false, err
+	}
+	return c.finish(ctx, entry), true, nil
+}
+
+// finish runs the last intake stages, redaction and truncation.
+func (c *NfoClient) finish(ctx context.Context, entry LogEntry) LogEntry {
+	if c.redactor != nil {
+		entry = c.redactor.entry(entry)
+	}
+	if c.truncate != nil {
+		entry = c.truncateEntry(ctx, entry)
+	}
+	if c.acks && entry.ID == "" {
+		entry.ID = NewULID()
+	}
+	return entry
+}
+
+// acceptAll runs entries through accept, joining the errors of rejected
+// ones.
+func (c *NfoClient) acceptAll(ctx context.Context, entries []LogEntry) ([]LogEntry, error) {
+	kept := make([]LogEntry, 0, len(entries))
+	var limited int
+	var errs []error
+	for _, entry := range entries {
+		entry, ok, err := c.accept(ctx, entry)
+		switch {
+		case errors.Is(err, ErrRateLimited):
+			limited++
+		case err != nil:
+			errs = append(errs, err)
+		case ok:
+			kept = append(kept, entry)
+		}
+	}
+	if limited > 0 {
+		errs = append(errs, fmt.Errorf("%w: %d entries", ErrRateLimited, limited))
+	}
+	return kept, errors.Join(errs...)
+}
+
+// prepare fills the fields entry leaves empty from the client defaults,
+// attaches host metadata and resolves the level.
+func (c *NfoClient) prepare(entry LogEntry) LogEntry {
+	entry = c.enrich(mergeEntry(entry, c.defaults))
+	entry.Level = levelOf(entry)
+	return entry
+}
+
+// mergeEntry returns entry with its zero-valued fields taken from d.
+func mergeEntry(entry, d LogEntry) LogEntry {
+	if entry.Cmd == "" {
+		entry.Cmd = d.Cmd
+	}
+	if entry.Args == nil {
+		entry.Args = d.Args
+	}
+	if entry.Language == "" {
+		entry.Language = d.Language
+	}
+	if entry.Env == "" {
+		entry.Env = d.Env
+	}
+	if entry.Success == nil {
+		entry.Success = d.Success
+	}
+	if entry.DurationMs == nil {
+		entry.DurationMs = d.DurationMs
+	}
+	if entry.Output == "" {
+		entry.Output = d.Output
+	}
+	if entry.Error == "" {
+		entry.Error = d.Error
+	}
+	if entry.Level == 0 {
+		entry.Level = d.Level
+	}
+	entry.Fields = mergeFields(entry.Fields, d.Fields)
+	return entry
+}
+
+// mergeFields returns a new map holding fields plus every key of defaults
+// that fields does not set. Neither input is modified.
+func mergeFields(fields, defaults map[string]any) map[string]any {
+	if len(defaults) == 0 {
+		return fields
+	}
+	merged := make(map[string]any, len(fields)+len(defaults))
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return merged
+}
+
+// do performs a request with retries and returns the response body of the
+// first successful attempt.
+func (c *NfoClient) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	var encoding string
+	if c.compressMin > 0 && len(body) >= c.compressMin && c.cachedCapabilities(c.Endpoint()).compression("gzip") {
+		compressed, err := gzipBytes(body)
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		body, encoding = compressed, "gzip"
+	}
+
+	var data []byte
+	err := c.guard(ctx, func(ctx context.Context) error {
+		base := c.baseURL(ctx)
+		var err error
+		data, err = c.send(ctx, base, method, path, contentType, body, encoding)
+		if c.failover != nil {
+			c.failover.record(base, err)
+		}
+		return err
+	})
+	return data, err
+}
+
+// guard runs attempt behind the circuit breaker and retry policy.
+func (c *NfoClient) guard(ctx context.Context, attempt func(context.Context) error) error {
+	var gen uint64
+	if c.breaker != nil {
+		var ok bool
+		if gen, ok = c.breaker.allow(); !ok {
+			return ErrCircuitOpen
+		}
+	}
+	err := c.retryLoop(ctx, attempt)
+	if c.breaker != nil {
+		c.breaker.record(gen, err == nil || !retryable(err) || errors.Is(err, context.Canceled))
+	}
+	return err
+}
+
+// retryLoop calls attempt until it succeeds, fails permanently or runs out
+// of attempts.
+func (c *NfoClient) retryLoop(ctx context.Context, attempt func(context.Context) error) error {
+	for n := 1; ; n++ {
+		err := attempt(ctx)
+		if err == nil || n >= c.retry.attempts || !retryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.retry.delay(n)):
+		}
+		c.metrics.RequestRetried()
+	}
+}
+
+func (c *NfoClient) send(ctx context.Context, base, method, path, contentType string, body []byte, encoding string) (data []byte, err error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	req, err := c.newRequest(ctx, base, method, path, contentType, body, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	defer func() { c.metrics.RequestCompleted(time.Since(start), err) }()
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, &transportError{op: strings.ToLower(method), err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+	return data, nil
+}
+
+// newRequest builds a request to base+path carrying the client's headers,
+// trace context and credentials.
+func (c *NfoClient) newRequest(ctx context.Context, base, method, path, contentType string, body []byte, encoding string) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, base+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.ToLower(method), err)
+	}
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	req.Header.Set(APIVersionHeader, APIVersion)
+	if tc, ok := c.trace(ctx); ok {
+		req.Header.Set("traceparent", tc.Traceparent())
+	}
+	if id, ok := CorrelationID(ctx); ok {
+		req.Header.Set(CorrelationHeader, id)
+	}
+	for _, auth := range c.auth {
+		if err := auth(req); err != nil {
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+	}
+	return req, nil
+}
+
+// LogCall wraps a function execution with nfo logging.
+func (c *NfoClient) LogCall(cmd string, args []string, fn func() (string, error)) error {
+	return c.Log(callEntry(cmd, args, fn))
+}
+
+// callEntry runs fn and describes the call as a LogEntry.
+func callEntry(cmd string, args []string, fn func() (string, error)) LogEntry {
+	start := time.Now()
+	output, err := fn()
+	duration := float64(time.Since(start).Milliseconds())
+
+	success := err == nil
+	entry := LogEntry{
+		Cmd:        cmd,
+		Args:       args,
+		Language:   "go",
+		Success:    &success,
+		DurationMs: &duration,
+		Output:     output,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+func getEnv(key, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return fallback
+}
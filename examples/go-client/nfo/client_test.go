@@ -0,0 +1,226 @@
+package nfo
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// recorder is a fake nfo-service that remembers every entry it receives.
+type recorder struct {
+	mu       sync.Mutex
+	entries  []LogEntry
+	requests int
+	attempts int
+	status   int
+	fail     []int
+	header   http.Header
+	body     []byte
+}
+
+func newRecorder(t *testing.T) (*recorder, *httptest.Server) {
+	t.Helper()
+	rec := &recorder{status: http.StatusOK}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			body = zr
+		}
+		raw, err := io.ReadAll(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var entries []LogEntry
+		if r.URL.Path == "/logs/batch" {
+			err = json.Unmarshal(raw, &entries)
+		} else {
+			entries = make([]LogEntry, 1)
+			err = json.Unmarshal(raw, &entries[0])
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.attempts++
+		rec.header = r.Header.Clone()
+		rec.body = raw
+		if len(rec.fail) > 0 {
+			w.WriteHeader(rec.fail[0])
+			rec.fail = rec.fail[1:]
+			return
+		}
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			return
+		}
+		rec.requests++
+		rec.entries = append(rec.entries, entries...)
+	}))
+	t.Cleanup(srv.Close)
+	return rec, srv
+}
+
+func (r *recorder) Entries() []LogEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]LogEntry(nil), r.entries...)
+}
+
+func (r *recorder) Requests() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests
+}
+
+func (r *recorder) Attempts() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts
+}
+
+func (r *recorder) Body() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.body
+}
+
+func (r *recorder) Header() http.Header {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.header
+}
+
+// FailNext makes the next len(statuses) requests fail with those statuses.
+func (r *recorder) FailNext(statuses ...int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fail = append(r.fail, statuses...)
+}
+
+func (r *recorder) SetStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+// memLogger collects entries in memory.
+type memLogger struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+func (m *memLogger) Log(entry LogEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *memLogger) Entries() []LogEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]LogEntry(nil), m.entries...)
+}
+
+func TestLog(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewNfoClient(srv.URL)
+
+	if err := client.Log(LogEntry{Cmd: "build", Args: []string{"v1"}, Language: "go"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	got := rec.Entries()
+	if len(got) != 1 || got[0].Cmd != "build" || got[0].Args[0] != "v1" {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+}
+
+func TestLogStatusError(t *testing.T) {
+	rec, srv := newRecorder(t)
+	rec.SetStatus(http.StatusInternalServerError)
+
+	if err := NewNfoClient(srv.URL).Log(LogEntry{Cmd: "build"}); err == nil {
+		t.Fatal("expected error for 500 response")
+	}
+}
+
+func TestLogCall(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewNfoClient(srv.URL)
+
+	err := client.LogCall("validate", []string{"x"}, func() (string, error) {
+		return "", errors.New("bad input")
+	})
+	if err != nil {
+		t.Fatalf("LogCall: %v", err)
+	}
+	got := rec.Entries()
+	if len(got) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(got))
+	}
+	if got[0].Success == nil || *got[0].Success {
+		t.Errorf("expected success=false, got %v", got[0].Success)
+	}
+	if got[0].Error != "bad input" || got[0].DurationMs == nil {
+		t.Errorf("unexpected entry: %+v", got[0])
+	}
+}
+
+func TestLogBatch(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewNfoClient(srv.URL)
+	client.MaxBatchSize = 2
+
+	entries := []LogEntry{{Cmd: "a"}, {Cmd: "b"}, {Cmd: "c"}}
+	if err := client.LogBatch(entries); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+	if got := rec.Entries(); len(got) != 3 || got[2].Cmd != "c" {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+	if rec.Requests() != 2 {
+		t.Fatalf("expected 2 requests, got %d", rec.Requests())
+	}
+}
+
+func TestSplitBatch(t *testing.T) {
+	encoded := [][]byte{[]byte("aaaa"), []byte("bb"), []byte("cc"), []byte("dddddddddd")}
+
+	tests := []struct {
+		name     string
+		maxSize  int
+		maxBytes int
+		want     []int
+	}{
+		{"unlimited", 0, 0, []int{4}},
+		{"by count", 3, 0, []int{3, 1}},
+		{"by bytes", 0, 10, []int{2, 1, 1}},
+		{"oversized entry alone", 0, 5, []int{1, 1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks := splitBatch(encoded, tt.maxSize, tt.maxBytes)
+			if len(chunks) != len(tt.want) {
+				t.Fatalf("got %d chunks, want %d", len(chunks), len(tt.want))
+			}
+			for i, chunk := range chunks {
+				if len(chunk) != tt.want[i] {
+					t.Errorf("chunk %d has %d entries, want %d", i, len(chunk), tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoServerTime is returned by SyncClock when nfo-service reports its
+// time neither on GET /time nor in a Date header.
+var ErrNoServerTime = errors.New("nfo: server time unavailable")
+
+// ServerTime is the response of GET /time.
+type ServerTime struct {
+	Time time.Time `json:"time"`
+}
+
+// ClockSyncConfig configures WithClockSync. Zero values select the
+// defaults.
+type ClockSyncConfig struct {
+	// Interval is how often the skew is measured again (default 5m).
+	Interval time.Duration
+	// Timeout bounds each measurement (default 2s).
+	Timeout time.Duration
+	// Threshold is the smallest skew that is annotated and corrected
+	// (default 0). Skews within the measurement's uncertainty always
+	// count as zero.
+	Threshold time.Duration
+	// Adjust corrects the timestamps the client sets itself by the skew,
+	// so they are in the service's time. Timestamps set by the caller are
+	// kept as they are.
+	Adjust bool
+}
+
+// WithClockSync makes the client compare its clock with nfo-service's, so
+// that timestamps and durations from hosts whose clocks drift can be told
+// apart in queries. Before the first entry, and again every
+// cfg.Interval, a background request to GET /time measures the skew,
+// halving the round trip; services without the endpoint are measured by
+// the Date header of the response, to the second. Then every entry:
+//
+//   - is annotated with ClockSkewMs, unless it has it already, e.g. when
+//     relayed from another client, and
+//   - with cfg.Adjust, has its timestamp corrected by the skew if the
+//     client set it.
+//
+// Entries logged before the first measurement completes are left alone;
+// call SyncClock at startup to measure right away. Metrics implementing
+// ClockMetrics receive every measurement.
+func WithClockSync(cfg ClockSyncConfig) Option {
+	return func(c *clientConfig) {
+		if cfg.Interval <= 0 {
+			cfg.Interval = 5 * time.Minute
+		}
+		if cfg.Timeout <= 0 {
+			cfg.Timeout = 2 * time.Second
+		}
+		c.client.clockSync = &clockSync{cfg: cfg}
+	}
+}
+
+// ClockMetrics is implemented by Metrics that also track clock skew. The
+// client calls ClockSkew after each measurement made for WithClockSync or
+// SyncClock.
+type ClockMetrics interface {
+	ClockSkew(skew time.Duration)
+}
+
+// clockSync holds the skew measured for WithClockSync.
+type clockSync struct {
+	cfg ClockSyncConfig
+
+	mu       sync.Mutex
+	skew     time.Duration
+	measured bool
+	next     time.Time // when to measure again
+	running  atomic.Bool
+}
+
+// SyncClock measures how far the client's clock is ahead of nfo-service's
+// (negative if behind) and returns the skew. With WithClockSync, entries
+// are annotated with it from then on.
+func (c *NfoClient) SyncClock(ctx context.Context) (time.Duration, error) {
+	skew, err := c.measureSkew(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if s := c.clockSync; s != nil {
+		s.mu.Lock()
+		s.skew, s.measured = skew, true
+		s.mu.Unlock()
+	}
+	if m, ok := c.metrics.(ClockMetrics); ok {
+		m.ClockSkew(skew)
+	}
+	return skew, nil
+}
+
+// ClockSkew returns the skew last measured for WithClockSync, and false
+// if there is none yet.
+func (c *NfoClient) ClockSkew() (time.Duration, bool) {
+	s := c.clockSync
+	if s == nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.skew, s.measured
+}
+
+// measureSkew asks the active endpoint for its time. The local time is
+// taken as halfway through the round trip, and a skew within the
+// uncertainty of the measurement is reported as zero.
+func (c *NfoClient) measureSkew(ctx context.Context) (time.Duration, error) {
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	req, err := c.newRequest(ctx, c.baseURL(ctx), http.MethodGet, "/time", "", nil, "")
+	if err != nil {
+		return 0, err
+	}
+	local, start := c.clock(), time.Now()
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, &transportError{op: "get", err: err}
+	}
+	defer resp.Body.Close()
+	rtt := time.Since(start)
+	local = local.Add(rtt / 2)
+
+	var skew, uncertainty time.Duration
+	var st ServerTime
+	if resp.StatusCode == http.StatusOK && json.NewDecoder(io.LimitReader(resp.Body, 1<<10)).Decode(&st) == nil && !st.Time.IsZero() {
+		skew, uncertainty = local.Sub(st.Time), rtt/2
+	} else if date, err := http.ParseTime(resp.Header.Get("Date")); err == nil {
+		// Date has whole seconds: the server time is within the second.
+		skew, uncertainty = local.Sub(date.Add(500*time.Millisecond)), rtt/2+500*time.Millisecond
+	} else {
+		return 0, ErrNoServerTime
+	}
+	if skew.Abs() <= uncertainty {
+		skew = 0
+	}
+	return skew, nil
+}
+
+// stampTime timestamps entry and, with WithClockSync, annotates it with
+// the clock skew and corrects the timestamp.
+func (c *NfoClient) stampTime(entry LogEntry) LogEntry {
+	stamped := entry.Timestamp == nil
+	entry = stamp(entry, c.clock)
+	s := c.clockSync
+	if s == nil {
+		return entry
+	}
+	s.refresh(c)
+	s.mu.Lock()
+	skew, measured := s.skew, s.measured
+	s.mu.Unlock()
+	if !measured || skew.Abs() < s.cfg.Threshold {
+		return entry
+	}
+	if entry.ClockSkewMs == nil {
+		ms := math.Round(float64(skew)/float64(time.Microsecond)) / 1000
+		entry.ClockSkewMs = &ms
+	}
+	if s.cfg.Adjust && stamped {
+		t := entry.Timestamp.Add(-skew)
+		entry.Timestamp = &t
+	}
+	return entry
+}
+
+// refresh starts a measurement in the background when one is due and none
+// is running. A failed measurement keeps the last skew until the next.
+func (s *clockSync) refresh(c *NfoClient) {
+	now := time.Now()
+	s.mu.Lock()
+	due := now.After(s.next)
+	if due {
+		s.next = now.Add(s.cfg.Interval)
+	}
+	s.mu.Unlock()
+	if !due || !s.running.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.running.Store(false)
+		ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+		defer cancel()
+		c.SyncClock(ctx)
+	}()
+}
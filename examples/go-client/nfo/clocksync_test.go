@@ -0,0 +1,130 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTimeServer serves GET /time if endpoint is set, and otherwise 404
+// with only the Date header.
+func newTimeServer(t *testing.T, endpoint bool) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if endpoint && r.URL.Path == "/time" {
+			json.NewEncoder(w).Encode(ServerTime{Time: time.Now().UTC()})
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// skewMetrics records ClockSkew calls.
+type skewMetrics struct {
+	nopMetrics
+	mu    sync.Mutex
+	skews []time.Duration
+}
+
+func (m *skewMetrics) ClockSkew(skew time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.skews = append(m.skews, skew)
+}
+
+func fastClock() time.Time { return time.Now().Add(time.Hour) }
+
+func TestSyncClock(t *testing.T) {
+	srv := newTimeServer(t, true)
+	metrics := &skewMetrics{}
+	c := NewClient(srv.URL, WithClock(fastClock), WithClockSync(ClockSyncConfig{Adjust: true}), WithMetrics(metrics))
+
+	skew, err := c.SyncClock(context.Background())
+	if err != nil || (skew-time.Hour).Abs() > time.Second {
+		t.Fatalf("SyncClock = %v, %v, want about 1h", skew, err)
+	}
+	if got, ok := c.ClockSkew(); !ok || got != skew {
+		t.Fatalf("ClockSkew = %v, %v", got, ok)
+	}
+	if len(metrics.skews) != 1 || metrics.skews[0] != skew {
+		t.Fatalf("metrics = %v", metrics.skews)
+	}
+
+	entry, ok, _ := c.accept(context.Background(), LogEntry{Cmd: "a"})
+	if !ok || entry.ClockSkewMs == nil || (*entry.ClockSkewMs < 3.599e6 || *entry.ClockSkewMs > 3.601e6) {
+		t.Fatalf("entry not annotated: %+v", entry)
+	}
+	if d := time.Since(*entry.Timestamp).Abs(); d > time.Second {
+		t.Fatalf("timestamp off by %v, want it corrected to server time", d)
+	}
+
+	// Timestamps and skews set by the caller are kept.
+	ts, relayed := time.Now().Add(-time.Minute).UTC(), 12.5
+	entry, _, _ = c.accept(context.Background(), LogEntry{Cmd: "b", Timestamp: &ts, ClockSkewMs: &relayed})
+	if !entry.Timestamp.Equal(ts) || *entry.ClockSkewMs != relayed {
+		t.Fatalf("caller's values changed: %+v", entry)
+	}
+}
+
+func TestSyncClockDateHeader(t *testing.T) {
+	srv := newTimeServer(t, false)
+
+	c := NewClient(srv.URL, WithClock(fastClock))
+	if skew, err := c.SyncClock(context.Background()); err != nil || (skew-time.Hour).Abs() > 2*time.Second {
+		t.Fatalf("SyncClock = %v, %v, want about 1h", skew, err)
+	}
+	// Within the second of precision of Date, a synchronised clock has no skew.
+	if skew, err := NewClient(srv.URL).SyncClock(context.Background()); err != nil || skew != 0 {
+		t.Fatalf("SyncClock = %v, %v, want 0", skew, err)
+	}
+
+	bare := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer bare.Close()
+	if _, err := NewClient(bare.URL).SyncClock(context.Background()); !errors.Is(err, ErrNoServerTime) {
+		t.Fatalf("SyncClock = %v, want ErrNoServerTime", err)
+	}
+}
+
+func TestClockSyncBackground(t *testing.T) {
+	srv := newTimeServer(t, true)
+	c := NewClient(srv.URL, WithClock(fastClock), WithClockSync(ClockSyncConfig{}))
+
+	entry, _, _ := c.accept(context.Background(), LogEntry{Cmd: "a"})
+	if entry.ClockSkewMs != nil {
+		t.Fatalf("annotated before the first measurement: %+v", entry)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := c.ClockSkew(); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("skew never measured")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	entry, _, _ = c.accept(context.Background(), LogEntry{Cmd: "b"})
+	if entry.ClockSkewMs == nil || !entry.Timestamp.After(time.Now().Add(time.Minute)) {
+		t.Fatalf("want an annotated, unadjusted entry: %+v", entry)
+	}
+}
+
+func TestClockSyncThreshold(t *testing.T) {
+	srv := newTimeServer(t, true)
+	c := NewClient(srv.URL, WithClock(fastClock), WithClockSync(ClockSyncConfig{Threshold: 2 * time.Hour}))
+	if _, err := c.SyncClock(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _, _ := c.accept(context.Background(), LogEntry{Cmd: "a"}); entry.ClockSkewMs != nil {
+		t.Fatalf("skew below threshold annotated: %+v", entry)
+	}
+}
@@ -0,0 +1,210 @@
+package nfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+)
+
+// Content types of the built-in codecs.
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeNDJSON  = "application/x-ndjson"
+	ContentTypeMsgpack = "application/msgpack"
+)
+
+// Codec encodes entries for the wire. The client sends its ContentType with
+// every POST /log and /logs/batch request so nfo-service can pick the
+// matching decoder.
+type Codec interface {
+	// ContentType is the media type of encoded bodies.
+	ContentType() string
+	// Marshal encodes one entry, the body of POST /log.
+	Marshal(entry LogEntry) ([]byte, error)
+	// Batch joins entries encoded by Marshal into a POST /logs/batch body.
+	Batch(encoded [][]byte) []byte
+	// Unmarshal decodes a body made by Marshal or Batch.
+	Unmarshal(data []byte) ([]LogEntry, error)
+}
+
+// Built-in codecs. JSONCodec is the default; NDJSONCodec sends batches as
+// one JSON document per line, which servers can decode as a stream;
+// MsgpackCodec sends MessagePack, which is smaller and cheaper to encode.
+var (
+	JSONCodec    Codec = jsonCodec{}
+	NDJSONCodec  Codec = ndjsonCodec{}
+	MsgpackCodec Codec = msgpackCodec{}
+)
+
+// CodecFor returns the built-in codec for a Content-Type header value.
+// Parameters such as charset are ignored and an empty value selects JSON.
+func CodecFor(contentType string) (Codec, bool) {
+	if contentType == "" {
+		return JSONCodec, true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	switch mediaType {
+	case ContentTypeJSON:
+		return JSONCodec, true
+	case ContentTypeNDJSON, "application/jsonl":
+		return NDJSONCodec, true
+	case ContentTypeMsgpack, "application/x-msgpack":
+		return MsgpackCodec, true
+	}
+	return nil, false
+}
+
+// WithCodec selects the wire format of log requests. If nfo-service
+// answers 415 Unsupported Media Type, the client resends in JSON and keeps
+// using JSON from then on. Queries and other requests always use JSON.
+func WithCodec(codec Codec) Option {
+	return func(cfg *clientConfig) {
+		if codec != nil {
+			cfg.client.codec = codec
+		}
+	}
+}
+
+// flatMarshaler is implemented by codecs that can send Fields as top-level
+// keys for WithFlattenFields. Other codecs send them nested.
+type flatMarshaler interface {
+	marshalFlat(entry LogEntry, prefix string) ([]byte, error)
+}
+
+// wireCodec returns the codec for the next log request.
+func (c *NfoClient) wireCodec() Codec {
+	if c.jsonFallback.Load() {
+		return JSONCodec
+	}
+	return c.codec
+}
+
+// fellBack reports whether err is nfo-service rejecting codec's format, in
+// which case the client switches to JSON for good.
+func (c *NfoClient) fellBack(codec Codec, err error) bool {
+	var ae *APIError
+	if codec == JSONCodec || !errors.As(err, &ae) || ae.StatusCode != http.StatusUnsupportedMediaType {
+		return false
+	}
+	c.jsonFallback.Store(true)
+	return true
+}
+
+// encode marshals entry with codec according to the client's field layout.
+func (c *NfoClient) encode(codec Codec, entry LogEntry) ([]byte, error) {
+	if c.flatten && len(entry.Fields) > 0 {
+		if fm, ok := codec.(flatMarshaler); ok {
+			return fm.marshalFlat(entry, c.flattenPrefix)
+		}
+	}
+	return codec.Marshal(entry)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return ContentTypeJSON }
+
+func (jsonCodec) Marshal(entry LogEntry) ([]byte, error) {
+	data, err := marshalEntry(&entry)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+	return data, nil
+}
+
+func (jsonCodec) marshalFlat(entry LogEntry, prefix string) ([]byte, error) {
+	return marshalFlatJSON(entry, prefix)
+}
+
+func (jsonCodec) Batch(encoded [][]byte) []byte {
+	body := append([]byte{'['}, bytes.Join(encoded, []byte{','})...)
+	return append(body, ']')
+}
+
+func (jsonCodec) Unmarshal(data []byte) ([]LogEntry, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var entries []LogEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("unmarshal: %w", err)
+		}
+		return entries, nil
+	}
+	var entry LogEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	return []LogEntry{entry}, nil
+}
+
+type ndjsonCodec struct{}
+
+func (ndjsonCodec) ContentType() string { return ContentTypeNDJSON }
+
+func (ndjsonCodec) Marshal(entry LogEntry) ([]byte, error) {
+	return jsonCodec{}.Marshal(entry)
+}
+
+func (ndjsonCodec) marshalFlat(entry LogEntry, prefix string) ([]byte, error) {
+	return marshalFlatJSON(entry, prefix)
+}
+
+func (ndjsonCodec) Batch(encoded [][]byte) []byte {
+	var body []byte
+	for _, data := range encoded {
+		body = append(body, data...)
+		body = append(body, '\n')
+	}
+	return body
+}
+
+func (ndjsonCodec) Unmarshal(data []byte) ([]LogEntry, error) {
+	var entries []LogEntry
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var entry LogEntry
+		if err := dec.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("unmarshal line %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// marshalFlatJSON encodes entry with its Fields as top-level keys named
+// prefix+key, skipping keys that clash with LogEntry fields.
+func marshalFlatJSON(entry LogEntry, prefix string) ([]byte, error) {
+	fields := entry.Fields
+	entry.Fields = nil
+	buf := getBuf()
+	defer putBuf(buf)
+	data, err := appendEntry(*buf, &entry)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	data = data[:len(data)-1] // drop the closing brace
+	var scratch [16]string
+	for _, key := range sortedMapKeys(scratch[:0], fields) {
+		name := prefix + key
+		if reservedKeys[name] {
+			continue
+		}
+		data = append(data, ',')
+		data = appendJSONString(data, name)
+		data = append(data, ':')
+		if data, err = appendValue(data, fields[key]); err != nil {
+			return nil, fmt.Errorf("marshal field %q: %w", key, err)
+		}
+	}
+	data = append(data, '}')
+	*buf = data
+	return slices.Clone(data), nil
+}
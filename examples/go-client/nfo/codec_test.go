@@ -0,0 +1,109 @@
+package nfo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	ok := true
+	ms := 12.5
+	ts := time.Date(2026, 3, 1, 10, 0, 0, 5, time.UTC)
+	entry := LogEntry{
+		Cmd: "deploy", Args: []string{"api", "--force"}, Language: "go", Env: "prod",
+		Success: &ok, DurationMs: &ms, Output: "done\n", Level: LevelWarn, Timestamp: &ts,
+		CorrelationID: "job-1",
+		Fields:        map[string]any{"user": "u1", "attempt": float64(2), "tags": []any{"a"}},
+		Meta:          &Metadata{Hostname: "h1", PID: 42},
+	}
+
+	for _, codec := range []Codec{JSONCodec, NDJSONCodec, MsgpackCodec} {
+		data, err := codec.Marshal(entry)
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", codec.ContentType(), err)
+		}
+		got, err := codec.Unmarshal(data)
+		if err != nil || len(got) != 1 || !reflect.DeepEqual(got[0], entry) {
+			t.Fatalf("%s: round trip = %+v, %v", codec.ContentType(), got, err)
+		}
+
+		other, _ := codec.Marshal(LogEntry{Cmd: "other"})
+		got, err = codec.Unmarshal(codec.Batch([][]byte{data, other}))
+		if err != nil || len(got) != 2 || got[1].Cmd != "other" {
+			t.Fatalf("%s: batch = %+v, %v", codec.ContentType(), got, err)
+		}
+	}
+}
+
+func TestCodecFor(t *testing.T) {
+	tests := map[string]Codec{
+		"":                                JSONCodec,
+		"application/json; charset=utf-8": JSONCodec,
+		"application/x-ndjson":            NDJSONCodec,
+		"application/x-msgpack":           MsgpackCodec,
+		"text/plain":                      nil,
+	}
+	for contentType, want := range tests {
+		if got, _ := CodecFor(contentType); got != want {
+			t.Errorf("CodecFor(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
+
+func TestWithCodecFlatten(t *testing.T) {
+	c := NewClient("http://unused", WithCodec(MsgpackCodec), WithFlattenFields("f_"))
+	data, err := c.encode(c.wireCodec(), LogEntry{Cmd: "x", Fields: map[string]any{"user": "u1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r msgpackReader
+	r.data = data
+	doc, _ := r.value()
+	m := doc.(map[string]any)
+	if m["f_user"] != "u1" || m["fields"] != nil {
+		t.Fatalf("fields not flattened: %v", m)
+	}
+}
+
+func TestCodecFallsBackToJSON(t *testing.T) {
+	var mu sync.Mutex
+	var types []string
+	var logged []LogEntry
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		contentType := r.Header.Get("Content-Type")
+		types = append(types, contentType)
+		if contentType != ContentTypeJSON {
+			http.Error(w, "json only", http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		entries, _ := JSONCodec.Unmarshal(body)
+		logged = append(logged, entries...)
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithCodec(MsgpackCodec))
+	if err := client.LogBatch([]LogEntry{{Cmd: "a"}, {Cmd: "b"}}); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+	if err := client.Log(LogEntry{Cmd: "c"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{ContentTypeMsgpack, ContentTypeJSON, ContentTypeJSON}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("content types = %v, want %v", types, want)
+	}
+	if len(logged) != 3 {
+		t.Fatalf("logged %d entries, want 3", len(logged))
+	}
+}
@@ -0,0 +1,48 @@
+package nfo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"slices"
+	"sync"
+)
+
+// DefaultCompressThreshold is the body size above which WithCompression
+// gzips requests when no explicit threshold is given.
+const DefaultCompressThreshold = 1024
+
+// WithCompression gzips request bodies of at least threshold bytes and
+// marks them with "Content-Encoding: gzip". Smaller bodies are sent as-is,
+// since compression would cost more than it saves. A threshold <= 0
+// selects DefaultCompressThreshold. The service must decompress the
+// bodies; with WithNegotiation, they are sent uncompressed to services
+// whose Capabilities do not list gzip.
+func WithCompression(threshold int) Option {
+	return func(cfg *clientConfig) {
+		if threshold <= 0 {
+			threshold = DefaultCompressThreshold
+		}
+		cfg.client.compressMin = threshold
+	}
+}
+
+// gzipWriters reuses gzip writers, whose compression state is several
+// hundred kilobytes, across requests.
+var gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	buf := getBuf()
+	defer putBuf(buf)
+	w := bytes.NewBuffer(*buf)
+	zw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(zw)
+	zw.Reset(w)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	*buf = w.Bytes()
+	return slices.Clone(w.Bytes()), nil
+}
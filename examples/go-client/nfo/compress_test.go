@@ -0,0 +1,65 @@
+package nfo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWithCompressionAboveThreshold(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithCompression(512))
+
+	big := strings.Repeat("x", 4096)
+	if err := client.Log(LogEntry{Cmd: "dump", Output: big}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+	if entries := rec.Entries(); len(entries) != 1 || entries[0].Output != big {
+		t.Fatal("compressed entry did not round-trip")
+	}
+}
+
+func TestWithCompressionBelowThreshold(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithCompression(512))
+
+	client.Log(LogEntry{Cmd: "small"})
+	if got := rec.Header().Get("Content-Encoding"); got != "" {
+		t.Fatalf("small body should not be compressed, got %q", got)
+	}
+}
+
+func TestGzipBytesReusesWriters(t *testing.T) {
+	for i, in := range []string{strings.Repeat("a", 5000), "short", ""} {
+		data, err := gzipBytes([]byte(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if out, err := io.ReadAll(zr); err != nil || string(out) != in {
+			t.Fatalf("%d: round trip = %q, %v", i, out, err)
+		}
+	}
+}
+
+func BenchmarkGzipBytes(b *testing.B) {
+	entry := benchEntry()
+	encoded := make([][]byte, 100)
+	for i := range encoded {
+		encoded[i], _ = JSONCodec.Marshal(entry)
+	}
+	body := JSONCodec.Batch(encoded)
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	for range b.N {
+		gzipBytes(body)
+	}
+}
@@ -0,0 +1,449 @@
+package nfo
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidConfig wraps every error reported by LoadEnv, LoadFile and
+// Config.Validate.
+var ErrInvalidConfig = errors.New("nfo: invalid config")
+
+// Config holds client settings read by FromEnv or FromFile. Zero values
+// keep the NewClient defaults.
+type Config struct {
+	URL          string            // NFO_URL / url (required)
+	APIKey       string            // NFO_API_KEY / api_key
+	APIKeyHeader string            // NFO_API_KEY_HEADER / api_key_header (default X-API-Key)
+	Token        string            // NFO_TOKEN / token, sent as a bearer token
+	Timeout      time.Duration     // NFO_TIMEOUT / timeout, e.g. "5s"
+	Env          string            // NFO_ENV / env
+	BatchSize    int               // NFO_BATCH_SIZE / batch_size
+	BatchBytes   int               // NFO_BATCH_BYTES / batch_bytes
+	Retries      int               // NFO_RETRIES / retries, attempts per request
+	RetryBackoff time.Duration     // NFO_RETRY_BACKOFF / retry_backoff (default 100ms)
+	Compression  int               // NFO_COMPRESSION / compression, gzip threshold in bytes
+	Codec        string            // NFO_CODEC / codec: json, ndjson or msgpack
+	MinLevel     Level             // NFO_MIN_LEVEL / min_level
+	Sampling     int               // NFO_SAMPLING / sampling, keep 1 in n successes
+	UserAgent    string            // NFO_USER_AGENT / user_agent
+	Proxy        string            // NFO_PROXY / proxy, overrides HTTP(S)_PROXY
+	TLSCAFile    string            // NFO_TLS_CA_FILE / tls_ca_file, PEM CA bundle
+	TLSCertFile  string            // NFO_TLS_CERT_FILE / tls_cert_file, PEM client certificate
+	TLSKeyFile   string            // NFO_TLS_KEY_FILE / tls_key_file, PEM client key
+	Headers      map[string]string // NFO_HEADERS="k=v,k=v" / [headers]
+	Fields       map[string]string // NFO_FIELDS="k=v,k=v" / [fields]
+}
+
+// FromEnv builds a client from NFO_* environment variables; see Config for
+// the names. opts are applied after the loaded settings.
+func FromEnv(opts ...Option) (*NfoClient, error) {
+	cfg, err := LoadEnv()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.NewClient(opts...)
+}
+
+// FromFile builds a client from a YAML (.yaml, .yml) or TOML (.toml) file;
+// see LoadFile. opts are applied after the loaded settings.
+func FromFile(path string, opts ...Option) (*NfoClient, error) {
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.NewClient(opts...)
+}
+
+// NewClient validates cfg and builds a client from it plus opts.
+func (cfg Config) NewClient(opts ...Option) (*NfoClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewClient(cfg.URL, append(cfg.Options(), opts...)...), nil
+}
+
+// configKeys parses each setting into a Config. The names are the file
+// keys; the environment variables are the same in upper case with an NFO_
+// prefix.
+var configKeys = map[string]func(cfg *Config, v string) error{
+	"url":            func(cfg *Config, v string) error { cfg.URL = v; return nil },
+	"api_key":        func(cfg *Config, v string) error { cfg.APIKey = v; return nil },
+	"api_key_header": func(cfg *Config, v string) error { cfg.APIKeyHeader = v; return nil },
+	"token":          func(cfg *Config, v string) error { cfg.Token = v; return nil },
+	"timeout":        func(cfg *Config, v string) error { return parseDuration(v, &cfg.Timeout) },
+	"env":            func(cfg *Config, v string) error { cfg.Env = v; return nil },
+	"batch_size":     func(cfg *Config, v string) error { return parseCount(v, &cfg.BatchSize) },
+	"batch_bytes":    func(cfg *Config, v string) error { return parseCount(v, &cfg.BatchBytes) },
+	"retries":        func(cfg *Config, v string) error { return parseCount(v, &cfg.Retries) },
+	"retry_backoff":  func(cfg *Config, v string) error { return parseDuration(v, &cfg.RetryBackoff) },
+	"compression":    func(cfg *Config, v string) error { return parseCount(v, &cfg.Compression) },
+	"codec":          func(cfg *Config, v string) error { cfg.Codec = v; return nil },
+	"min_level": func(cfg *Config, v string) (err error) {
+		cfg.MinLevel, err = ParseLevel(v)
+		return err
+	},
+	"sampling":      func(cfg *Config, v string) error { return parseCount(v, &cfg.Sampling) },
+	"user_agent":    func(cfg *Config, v string) error { cfg.UserAgent = v; return nil },
+	"proxy":         func(cfg *Config, v string) error { cfg.Proxy = v; return nil },
+	"tls_ca_file":   func(cfg *Config, v string) error { cfg.TLSCAFile = v; return nil },
+	"tls_cert_file": func(cfg *Config, v string) error { cfg.TLSCertFile = v; return nil },
+	"tls_key_file":  func(cfg *Config, v string) error { cfg.TLSKeyFile = v; return nil },
+	"headers":       func(cfg *Config, v string) (err error) { cfg.Headers, err = parsePairs(v); return err },
+	"fields":        func(cfg *Config, v string) (err error) { cfg.Fields, err = parsePairs(v); return err },
+}
+
+// LoadEnv reads NFO_* environment variables into a Config and validates
+// it. Every bad value is reported, not just the first.
+func LoadEnv() (Config, error) {
+	var cfg Config
+	var errs []error
+	for _, key := range sortedConfigKeys() {
+		name := "NFO_" + strings.ToUpper(key)
+		if v := os.Getenv(name); v != "" {
+			if err := configKeys[key](&cfg, v); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s=%q: %v", ErrInvalidConfig, name, v, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return cfg, errors.Join(errs...)
+	}
+	return cfg, cfg.Validate()
+}
+
+// LoadFile reads a config file into a Config and validates it. The file
+// uses the keys of Config in a flat subset of YAML or TOML, chosen by
+// extension: one "key: value" (YAML) or "key = value" (TOML) per line,
+// plus a "headers" and a "fields" section (an indented block in YAML, a
+// [table] in TOML). Values may be quoted; # starts a comment. Unknown keys
+// are errors, so typos do not go unnoticed.
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("nfo: read config: %w", err)
+	}
+	var parse func([]byte) (map[string]string, map[string]map[string]string, error)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		parse = parseYAML
+	case ".toml":
+		parse = parseTOML
+	default:
+		return Config{}, fmt.Errorf("%w: %s: unsupported extension %q, want .yaml, .yml or .toml", ErrInvalidConfig, path, ext)
+	}
+	values, sections, err := parse(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+	}
+
+	var cfg Config
+	var errs []error
+	for _, key := range sortedKeys(values) {
+		set, ok := configKeys[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s: unknown key %q", ErrInvalidConfig, path, key))
+			continue
+		}
+		if err := set(&cfg, values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %s=%q: %v", ErrInvalidConfig, path, key, values[key], err))
+		}
+	}
+	for _, name := range sortedKeys(sections) {
+		section := sections[name]
+		switch name {
+		case "headers":
+			cfg.Headers = section
+		case "fields":
+			cfg.Fields = section
+		default:
+			errs = append(errs, fmt.Errorf("%w: %s: unknown section %q", ErrInvalidConfig, path, name))
+		}
+	}
+	if len(errs) > 0 {
+		return cfg, errors.Join(errs...)
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate reports missing or inconsistent settings.
+func (cfg Config) Validate() error {
+	var errs []error
+	if cfg.URL == "" {
+		errs = append(errs, fmt.Errorf("%w: url (NFO_URL) is required", ErrInvalidConfig))
+	} else if u, err := url.Parse(cfg.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("%w: url %q: want http(s)://host[:port]", ErrInvalidConfig, cfg.URL))
+	}
+	if cfg.Codec != "" && codecByName(cfg.Codec) == nil {
+		errs = append(errs, fmt.Errorf("%w: codec %q: want json, ndjson or msgpack", ErrInvalidConfig, cfg.Codec))
+	}
+	if cfg.APIKeyHeader != "" && cfg.APIKey == "" {
+		errs = append(errs, fmt.Errorf("%w: api_key_header is set without api_key", ErrInvalidConfig))
+	}
+	if cfg.Proxy != "" {
+		if _, err := parseProxyURL(cfg.Proxy); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %v", ErrInvalidConfig, err))
+		}
+	}
+	if _, err := cfg.loadTLS(); err != nil {
+		errs = append(errs, fmt.Errorf("%w: tls: %v", ErrInvalidConfig, err))
+	}
+	return errors.Join(errs...)
+}
+
+// loadTLS loads the TLS files named by cfg, if any.
+func (cfg Config) loadTLS() (*tls.Config, error) {
+	if cfg.TLSCAFile == "" && cfg.TLSCertFile == "" && cfg.TLSKeyFile == "" {
+		return nil, nil
+	}
+	return TLSConfig{CAFile: cfg.TLSCAFile, CertFile: cfg.TLSCertFile, KeyFile: cfg.TLSKeyFile}.load()
+}
+
+// Options converts cfg to client options. TLS files that fail to load are
+// skipped; Validate reports them.
+func (cfg Config) Options() []Option {
+	var opts []Option
+	if cfg.Timeout > 0 {
+		opts = append(opts, WithTimeout(cfg.Timeout))
+	}
+	if cfg.Env != "" {
+		opts = append(opts, WithEnv(cfg.Env))
+	}
+	if cfg.APIKey != "" {
+		header := cfg.APIKeyHeader
+		if header == "" {
+			header = "X-API-Key"
+		}
+		opts = append(opts, WithAPIKey(header, cfg.APIKey))
+	}
+	if cfg.Token != "" {
+		token := cfg.Token
+		opts = append(opts, WithBearerToken(func() (string, error) { return token, nil }))
+	}
+	if cfg.BatchSize > 0 || cfg.BatchBytes > 0 {
+		opts = append(opts, WithBatchLimits(cfg.BatchSize, cfg.BatchBytes))
+	}
+	if cfg.Retries > 0 {
+		backoff := cfg.RetryBackoff
+		if backoff <= 0 {
+			backoff = 100 * time.Millisecond
+		}
+		opts = append(opts, WithRetry(cfg.Retries, backoff))
+	}
+	if cfg.Compression > 0 {
+		opts = append(opts, WithCompression(cfg.Compression))
+	}
+	if codec := codecByName(cfg.Codec); codec != nil {
+		opts = append(opts, WithCodec(codec))
+	}
+	if cfg.MinLevel != 0 {
+		opts = append(opts, WithMinLevel(cfg.MinLevel))
+	}
+	if cfg.Sampling > 1 {
+		opts = append(opts, WithSampling(cfg.Sampling))
+	}
+	if cfg.UserAgent != "" {
+		opts = append(opts, WithUserAgent(cfg.UserAgent))
+	}
+	if cfg.Proxy != "" {
+		opts = append(opts, WithProxy(cfg.Proxy))
+	}
+	if tc, err := cfg.loadTLS(); err == nil && tc != nil {
+		opts = append(opts, WithTLSConfig(tc))
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, WithHeaders(cfg.Headers))
+	}
+	if len(cfg.Fields) > 0 {
+		fields := make(map[string]any, len(cfg.Fields))
+		for k, v := range cfg.Fields {
+			fields[k] = v
+		}
+		opts = append(opts, WithFields(fields))
+	}
+	return opts
+}
+
+func codecByName(name string) Codec {
+	switch strings.ToLower(name) {
+	case "json":
+		return JSONCodec
+	case "ndjson":
+		return NDJSONCodec
+	case "msgpack", "messagepack":
+		return MsgpackCodec
+	}
+	return nil
+}
+
+func sortedConfigKeys() []string {
+	keys := make([]string, 0, len(configKeys))
+	for k := range configKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func parseDuration(v string, d *time.Duration) error {
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed < 0 {
+		return errors.New("want a duration like 5s or 250ms")
+	}
+	*d = parsed
+	return nil
+}
+
+func parseCount(v string, n *int) error {
+	parsed, err := strconv.Atoi(v)
+	if err != nil || parsed < 0 {
+		return errors.New("want a non-negative integer")
+	}
+	*n = parsed
+	return nil
+}
+
+// parsePairs parses "k=v,k=v".
+func parsePairs(v string) (map[string]string, error) {
+	pairs := make(map[string]string)
+	for _, kv := range strings.Split(v, ",") {
+		k, val, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("want key=value pairs separated by commas, got %q", kv)
+		}
+		pairs[k] = val
+	}
+	return pairs, nil
+}
+
+// parseYAML parses top-level "key: value" lines and one level of indented
+// "key: value" lines under a "section:" line.
+func parseYAML(data []byte) (map[string]string, map[string]map[string]string, error) {
+	values := make(map[string]string)
+	sections := make(map[string]map[string]string)
+	var section map[string]string
+	err := scanConfig(data, func(n int, line string) error {
+		indented := line[0] == ' ' || line[0] == '\t'
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("line %d: want key: value", n)
+		}
+		value, err := unquote(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+		switch {
+		case indented && section == nil:
+			return fmt.Errorf("line %d: unexpected indentation", n)
+		case indented:
+			section[key] = value
+		case value == "":
+			section = make(map[string]string)
+			sections[key] = section
+		default:
+			section = nil
+			values[key] = value
+		}
+		return nil
+	})
+	return values, sections, err
+}
+
+// parseTOML parses "key = value" lines and [section] tables.
+func parseTOML(data []byte) (map[string]string, map[string]map[string]string, error) {
+	values := make(map[string]string)
+	sections := make(map[string]map[string]string)
+	target := values
+	err := scanConfig(data, func(n int, line string) error {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			name, ok := strings.CutSuffix(strings.TrimPrefix(line, "["), "]")
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("line %d: want [section]", n)
+			}
+			target = make(map[string]string)
+			sections[strings.TrimSpace(name)] = target
+			return nil
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+		if !ok || key == "" {
+			return fmt.Errorf("line %d: want key = value", n)
+		}
+		value, err := unquote(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+		target[key] = value
+		return nil
+	})
+	return values, sections, err
+}
+
+// scanConfig calls fn with the number and text of each line that is not
+// blank or a comment, with trailing comments removed.
+func scanConfig(data []byte, fn func(n int, line string) error) error {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := stripComment(sc.Text())
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if err := fn(n, line); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// stripComment removes a # comment that is not inside quotes.
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// unquote trims v and removes double quotes (with escapes) or single quotes.
+func unquote(v string) (string, error) {
+	v = strings.TrimSpace(v)
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		return strconv.Unquote(v)
+	}
+	if len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		return strings.ReplaceAll(v[1:len(v)-1], "''", "'"), nil
+	}
+	return v, nil
+}
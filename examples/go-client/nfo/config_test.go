@@ -0,0 +1,141 @@
+package nfo
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFromEnv(t *testing.T) {
+	rec, srv := newRecorder(t)
+	t.Setenv("NFO_URL", srv.URL)
+	t.Setenv("NFO_API_KEY", "k1")
+	t.Setenv("NFO_TIMEOUT", "2s")
+	t.Setenv("NFO_ENV", "staging")
+	t.Setenv("NFO_BATCH_SIZE", "7")
+	t.Setenv("NFO_CODEC", "ndjson")
+	t.Setenv("NFO_MIN_LEVEL", "warn")
+	t.Setenv("NFO_FIELDS", "team=core,region=eu")
+
+	client, err := FromEnv(WithUserAgent("custom"))
+	if err != nil {
+		t.Fatalf("FromEnv: %v", err)
+	}
+	if client.HTTPClient.Timeout != 2*time.Second || client.MaxBatchSize != 7 || client.codec != NDJSONCodec {
+		t.Fatalf("settings not applied: timeout=%v batch=%d codec=%v", client.HTTPClient.Timeout, client.MaxBatchSize, client.codec)
+	}
+
+	client.Log(LogEntry{Cmd: "skipped"})
+	client.Log(LogEntry{Cmd: "kept", Level: LevelError})
+	got := rec.Entries()
+	if len(got) != 1 || got[0].Env != "staging" || got[0].Fields["region"] != "eu" {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+	if h := rec.Header(); h.Get("X-API-Key") != "k1" || h.Get("User-Agent") != "custom" {
+		t.Fatalf("headers = %v", h)
+	}
+}
+
+func TestFromEnvErrors(t *testing.T) {
+	t.Setenv("NFO_URL", "")
+	if _, err := FromEnv(); !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), "NFO_URL") {
+		t.Fatalf("missing URL: %v", err)
+	}
+
+	t.Setenv("NFO_URL", "localhost:8080")
+	t.Setenv("NFO_TIMEOUT", "5 seconds")
+	t.Setenv("NFO_BATCH_SIZE", "-1")
+	_, err := LoadEnv()
+	for _, want := range []string{`NFO_TIMEOUT="5 seconds"`, `NFO_BATCH_SIZE="-1"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %s", err, want)
+		}
+	}
+
+	t.Setenv("NFO_TIMEOUT", "")
+	t.Setenv("NFO_BATCH_SIZE", "")
+	if _, err := LoadEnv(); err == nil || !strings.Contains(err.Error(), "want http(s)://host") {
+		t.Fatalf("bad URL: %v", err)
+	}
+
+	t.Setenv("NFO_URL", "https://nfo.internal")
+	t.Setenv("NFO_PROXY", "proxy.corp:3128")
+	t.Setenv("NFO_TLS_CERT_FILE", "/nonexistent/client.pem")
+	_, err = LoadEnv()
+	for _, want := range []string{"invalid proxy URL", "tls: a client certificate needs both"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("error %v does not mention %s", err, want)
+		}
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"nfo.yaml": `# nfo client
+url: "http://logs.internal:8080"
+timeout: 3s   # per request
+retries: 4
+codec: msgpack
+headers:
+  X-Team: core
+fields:
+  region: 'eu # west'
+`,
+		"nfo.toml": `url = "http://logs.internal:8080"
+timeout = "3s"
+retries = 4
+codec = "msgpack"
+
+[headers]
+X-Team = "core"
+
+[fields]
+region = "eu # west"
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0o644)
+		cfg, err := LoadFile(path)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if cfg.URL != "http://logs.internal:8080" || cfg.Timeout != 3*time.Second || cfg.Retries != 4 ||
+			cfg.Codec != "msgpack" || cfg.Headers["X-Team"] != "core" || cfg.Fields["region"] != "eu # west" {
+			t.Fatalf("%s: unexpected config %+v", name, cfg)
+		}
+		if _, err := FromFile(path); err != nil {
+			t.Fatalf("%s: FromFile: %v", name, err)
+		}
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"typo.yaml":    "url: http://x\ntimout: 5s\n",
+		"bad.toml":     "url = \"http://x\"\nretries = many\n",
+		"indent.yaml":  "  url: http://x\n",
+		"section.toml": "url = \"http://x\"\n[tags]\na = \"b\"\n",
+		"conf.json":    "{}",
+	}
+	wants := map[string]string{
+		"typo.yaml":    `unknown key "timout"`,
+		"bad.toml":     `retries="many"`,
+		"indent.yaml":  "indent.yaml: line 1: unexpected indentation",
+		"section.toml": `unknown section "tags"`,
+		"conf.json":    "unsupported extension",
+	}
+	for name, content := range tests {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0o644)
+		_, err := LoadFile(path)
+		if !errors.Is(err, ErrInvalidConfig) || !strings.Contains(err.Error(), wants[name]) {
+			t.Errorf("%s: error %v, want %q", name, err, wants[name])
+		}
+	}
+}
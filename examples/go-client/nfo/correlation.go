@@ -0,0 +1,89 @@
+package nfo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"net/http"
+	"time"
+)
+
+// Correlation headers read by CorrelationMiddleware. CorrelationHeader is
+// also sent on every request made with a context carrying an ID.
+const (
+	CorrelationHeader = "X-Correlation-ID"
+	RequestIDHeader   = "X-Request-ID"
+)
+
+type correlationKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying id, which context-aware
+// calls (LogContext, Call, CapturePanic, RunCommand, the slog handler)
+// stamp on every entry as correlation_id, so the steps of one job or
+// request can be queried together. An empty id is replaced by NewULID().
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		id = NewULID()
+	}
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the ID stored by WithCorrelationID.
+func CorrelationID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationKey{}).(string)
+	return id, ok && id != ""
+}
+
+// CorrelationMiddleware tags each request's context with the ID from its
+// X-Correlation-ID or X-Request-ID header, generating a ULID if neither is
+// set, and echoes it in the X-Correlation-ID response header.
+func CorrelationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(CorrelationHeader)
+		if id == "" {
+			id = r.Header.Get(RequestIDHeader)
+		}
+		ctx := WithCorrelationID(r.Context(), id)
+		id, _ = CorrelationID(ctx)
+		w.Header().Set(CorrelationHeader, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// crockford is the ULID alphabet.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a new ULID: 26 characters that sort by creation time
+// (millisecond precision) followed by 80 random bits.
+func NewULID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().UnixMilli())<<16)
+	rand.Read(b[6:])
+
+	// 128 bits as 26 base32 digits; the first digit holds the top 3 bits.
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+// stampContext links entry to the span and correlation ID in ctx, and
+// gives it the priority in ctx, unless it already names them. It does not
+// generate correlation IDs: an entry logged with a context without one
+// has none, since an ID of its own would group nothing. IDs are generated
+// by WithCorrelationID with an empty id and by CorrelationMiddleware.
+func (c *NfoClient) stampContext(ctx context.Context, entry LogEntry) LogEntry {
+	entry = c.stampTrace(ctx, entry)
+	if entry.CorrelationID == "" {
+		entry.CorrelationID, _ = CorrelationID(ctx)
+	}
+	if entry.Priority == 0 {
+		entry.Priority, _ = ContextPriority(ctx)
+	}
+	return entry
+}
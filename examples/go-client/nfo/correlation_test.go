@@ -0,0 +1,76 @@
+package nfo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewULID(t *testing.T) {
+	a := NewULID()
+	time.Sleep(2 * time.Millisecond)
+	b := NewULID()
+	if len(a) != 26 || strings.Trim(a, crockford) != "" {
+		t.Fatalf("malformed ULID %q", a)
+	}
+	if a[0] > '7' {
+		t.Fatalf("first digit of %q exceeds 128 bits", a)
+	}
+	if a >= b {
+		t.Fatalf("ULIDs do not sort by time: %s >= %s", a, b)
+	}
+}
+
+func TestCorrelationIDStamped(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	ctx := WithCorrelationID(context.Background(), "job-7")
+	client.LogContext(ctx, LogEntry{Cmd: "step1"})
+	if rec.Header().Get(CorrelationHeader) != "job-7" {
+		t.Fatalf("header = %q", rec.Header().Get(CorrelationHeader))
+	}
+	client.LogContext(ctx, LogEntry{Cmd: "step2", CorrelationID: "explicit"})
+	client.Log(LogEntry{Cmd: "unrelated"})
+
+	async := newAsyncClient(client, AsyncConfig{})
+	async.LogContext(ctx, LogEntry{Cmd: "step3"})
+	async.Flush()
+
+	got := rec.Entries()
+	want := []string{"job-7", "explicit", "", "job-7"}
+	for i, e := range got {
+		if e.CorrelationID != want[i] {
+			t.Fatalf("entry %d (%s): correlation_id = %q, want %q", i, e.Cmd, e.CorrelationID, want[i])
+		}
+	}
+
+	generated, ok := CorrelationID(WithCorrelationID(context.Background(), ""))
+	if !ok || len(generated) != 26 {
+		t.Fatalf("expected a generated ULID, got %q", generated)
+	}
+}
+
+func TestCorrelationMiddleware(t *testing.T) {
+	var seen string
+	h := CorrelationMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = CorrelationID(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if seen != "req-1" || w.Header().Get(CorrelationHeader) != "req-1" {
+		t.Fatalf("seen=%q echoed=%q", seen, w.Header().Get(CorrelationHeader))
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(seen) != 26 || w.Header().Get(CorrelationHeader) != seen {
+		t.Fatalf("expected a generated ID, seen=%q echoed=%q", seen, w.Header().Get(CorrelationHeader))
+	}
+}
@@ -0,0 +1,174 @@
+package nfo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// DedupConfig configures WithDedup.
+type DedupConfig struct {
+	// Window is how long duplicates of an entry are suppressed after it is
+	// sent (default 10s).
+	Window time.Duration
+	// Fingerprint identifies duplicates; entries for which it returns ""
+	// are never deduplicated. Nil selects Fingerprint, which only
+	// fingerprints failures.
+	Fingerprint func(LogEntry) string
+}
+
+// WithDedup suppresses repeated entries, such as the same failure logged on
+// every turn of a retry loop. The first entry with a given fingerprint is
+// sent right away and opens a window; duplicates within the window are
+// counted instead of sent. When the window closes, the last duplicate is
+// sent once as a rollup with RepeatCount set to the number suppressed.
+//
+// Rollups are sent from a timer goroutine with the client's retry policy.
+// AsyncClient.Close sends the rollups of windows still open; with a plain
+// NfoClient call FlushRollups on shutdown.
+func WithDedup(cfg DedupConfig) Option {
+	return func(c *clientConfig) {
+		if cfg.Window <= 0 {
+			cfg.Window = 10 * time.Second
+		}
+		if cfg.Fingerprint == nil {
+			cfg.Fingerprint = Fingerprint
+		}
+		client := c.client
+		client.dedup = &deduper{
+			cfg:     cfg,
+			windows: make(map[string]*dedupWindow),
+			emit:    func(entry LogEntry) { client.sendRollup(context.Background(), entry) },
+		}
+	}
+}
+
+// volatileError matches the parts of an error message that differ between
+// otherwise identical failures: UUIDs, hex IDs, numbers and addresses.
+var volatileError = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}|0x[0-9a-fA-F]+|\b[0-9a-fA-F]{12,}\b|\d+`)
+
+// Fingerprint returns a stable ID for the failure entry describes: a hash
+// of Cmd and the Error message with IDs, numbers and addresses masked, so
+// "dial 10.0.0.7:5432: timeout after 3s" and "dial 10.0.0.9:5432: timeout
+// after 5s" match. It returns "" for entries that did not fail.
+func Fingerprint(entry LogEntry) string {
+	if !failed(entry) {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(entry.Cmd + "\x00" + volatileError.ReplaceAllString(entry.Error, "#")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// FlushRollups sends the rollups of every open dedup window now instead of
+// when the windows close. It is a no-op without WithDedup.
+func (c *NfoClient) FlushRollups(ctx context.Context) error {
+	if c.dedup == nil {
+		return nil
+	}
+	var errs []error
+	for _, entry := range c.dedup.drain() {
+		if err := c.sendRollup(ctx, entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sendRollup finishes and sends a rollup entry. Failures are reported as
+// drops, since no caller is waiting for them.
+func (c *NfoClient) sendRollup(ctx context.Context, entry LogEntry) error {
+	entry = c.prepare(c.finish(ctx, entry))
+	var err error
+	if c.transport != nil {
+		err = c.deliver(ctx, []LogEntry{entry})
+	} else {
+		err = c.postEntry(ctx, entry)
+	}
+	if err != nil {
+		c.discard(dropReason(err), entry)
+		return err
+	}
+	c.metrics.EntriesSent(1)
+	return nil
+}
+
+// deduper tracks the open dedup windows by fingerprint.
+type deduper struct {
+	cfg  DedupConfig
+	emit func(LogEntry)
+
+	mu      sync.Mutex
+	windows map[string]*dedupWindow
+}
+
+type dedupWindow struct {
+	last    LogEntry
+	repeats int
+	timer   *time.Timer
+}
+
+// check stamps entry with its fingerprint and reports whether it should be
+// sent, or suppressed as a duplicate.
+func (d *deduper) check(entry LogEntry) (LogEntry, bool) {
+	fp := d.cfg.Fingerprint(entry)
+	if fp == "" {
+		return entry, true
+	}
+	entry.Fingerprint = fp
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if w, ok := d.windows[fp]; ok {
+		w.last = entry
+		w.repeats++
+		return entry, false
+	}
+	w := &dedupWindow{}
+	w.timer = time.AfterFunc(d.cfg.Window, func() { d.close(fp, w) })
+	d.windows[fp] = w
+	return entry, true
+}
+
+// close ends window w and emits its rollup, if it suppressed anything.
+func (d *deduper) close(fp string, w *dedupWindow) {
+	d.mu.Lock()
+	if d.windows[fp] != w {
+		d.mu.Unlock()
+		return // drained
+	}
+	delete(d.windows, fp)
+	rollup, ok := w.rollup()
+	d.mu.Unlock()
+	if ok {
+		d.emit(rollup)
+	}
+}
+
+// drain closes every window and returns their rollups.
+func (d *deduper) drain() []LogEntry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var rollups []LogEntry
+	for fp, w := range d.windows {
+		w.timer.Stop()
+		delete(d.windows, fp)
+		if rollup, ok := w.rollup(); ok {
+			rollups = append(rollups, rollup)
+		}
+	}
+	return rollups
+}
+
+// rollup returns the entry summarising the window's duplicates.
+func (w *dedupWindow) rollup() (LogEntry, bool) {
+	if w.repeats == 0 {
+		return LogEntry{}, false
+	}
+	entry := w.last
+	entry.RepeatCount = w.repeats
+	return entry, true
+}
@@ -0,0 +1,150 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	fail := func(cmd, msg string) LogEntry {
+		return LogEntry{Cmd: cmd, Error: msg}
+	}
+	a := Fingerprint(fail("sync", "dial 10.0.0.7:5432: timeout after 3s (request 6f1c2a9e-1b2c-4d5e-8f90-a1b2c3d4e5f6)"))
+	b := Fingerprint(fail("sync", "dial 10.0.0.9:5432: timeout after 5s (request 0a9b8c7d-6e5f-4a3b-2c1d-0e9f8a7b6c5d)"))
+	if a == "" || a != b {
+		t.Fatalf("fingerprints differ for the same failure: %q %q", a, b)
+	}
+	if c := Fingerprint(fail("sync", "permission denied")); c == a {
+		t.Fatal("different errors share a fingerprint")
+	}
+	if c := Fingerprint(fail("backup", "dial 10.0.0.7:5432: timeout after 3s")); c == Fingerprint(fail("sync", "dial 10.0.0.7:5432: timeout after 3s")) {
+		t.Fatal("different commands share a fingerprint")
+	}
+	if fp := Fingerprint(LogEntry{Cmd: "sync"}); fp != "" {
+		t.Fatalf("successful entry fingerprinted: %q", fp)
+	}
+}
+
+func TestDedup(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithDedup(DedupConfig{Window: 50 * time.Millisecond}))
+
+	for i := range 5 {
+		client.Log(LogEntry{Cmd: "sync", Error: "timeout after " + string(rune('1'+i)) + "s"})
+		client.Log(LogEntry{Cmd: "sync"})
+	}
+	got := rec.Entries()
+	if len(got) != 6 || got[0].Error != "timeout after 1s" || got[0].Fingerprint == "" || got[0].RepeatCount != 0 {
+		t.Fatalf("expected the first failure and every success, got %+v", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(rec.Entries()) < 7 {
+		if time.Now().After(deadline) {
+			t.Fatal("no rollup after the window closed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	rollup := rec.Entries()[6]
+	if rollup.RepeatCount != 4 || rollup.Error != "timeout after 5s" || rollup.Fingerprint != got[0].Fingerprint {
+		t.Fatalf("unexpected rollup: %+v", rollup)
+	}
+
+	client.Log(LogEntry{Cmd: "sync", Error: "timeout after 9s"})
+	if n := len(rec.Entries()); n != 8 {
+		t.Fatalf("a failure after the window should open a new one and be sent; have %d entries", n)
+	}
+}
+
+func TestDedupFlush(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithDedup(DedupConfig{Window: time.Hour}))
+	for range 3 {
+		client.Log(LogEntry{Cmd: "sync", Error: "boom"})
+	}
+	if err := client.FlushRollups(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Entries(); len(got) != 2 || got[1].RepeatCount != 2 {
+		t.Fatalf("entries = %+v", got)
+	}
+
+	async := NewAsyncClient(client, AsyncConfig{})
+	for range 4 {
+		async.Log(LogEntry{Cmd: "sync", Error: "boom"})
+	}
+	if err := async.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Entries(); len(got) != 4 || got[3].RepeatCount != 3 {
+		t.Fatalf("Close did not send the open rollup: %+v", got)
+	}
+}
+
+func TestDedupCloseQueueFull(t *testing.T) {
+	tr := &stuckTransport{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(tr.release)
+	var mu sync.Mutex
+	var dropped []LogEntry
+	client := NewClient("http://unused", WithTransport(tr), WithDedup(DedupConfig{Window: time.Hour}),
+		WithOnDrop(func(e LogEntry, _ DropReason) {
+			mu.Lock()
+			defer mu.Unlock()
+			dropped = append(dropped, e)
+		}))
+	async := NewAsyncClient(client, AsyncConfig{QueueSize: 1, FlushInterval: time.Millisecond})
+
+	async.Log(LogEntry{Cmd: "in-flight"})
+	<-tr.started
+	for range 3 {
+		async.Log(LogEntry{Cmd: "sync", Error: "boom"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	report, err := async.CloseContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || report.Dropped != 2 {
+		t.Fatalf("CloseContext = %+v, %v", report, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.ContainsFunc(dropped, func(e LogEntry) bool { return e.RepeatCount == 2 }) {
+		t.Fatalf("rollup not reported as dropped: %+v", dropped)
+	}
+}
+
+func TestDedupCloseBlockedQueue(t *testing.T) {
+	tr := &stuckTransport{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(tr.release)
+	client := NewClient("http://unused", WithTransport(tr), WithDedup(DedupConfig{Window: time.Hour}))
+	async := NewAsyncClient(client, AsyncConfig{QueueSize: 1, FlushInterval: time.Millisecond, Overflow: Block})
+
+	async.Log(LogEntry{Cmd: "in-flight"})
+	<-tr.started
+	for range 3 {
+		async.Log(LogEntry{Cmd: "sync", Error: "boom"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	report, err := async.CloseContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || report.Dropped != 2 {
+		t.Fatalf("CloseContext = %+v, %v", report, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("CloseContext took %v waiting for room for the rollup", d)
+	}
+
+	// Logging after close opens no dedup windows.
+	if err := async.Log(LogEntry{Cmd: "late", Error: "boom"}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Log after close: %v", err)
+	}
+	if n := len(client.dedup.windows); n != 0 {
+		t.Fatalf("%d dedup windows opened after close", n)
+	}
+}
@@ -0,0 +1,104 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// WithOnDrop calls fn for every entry the client or an AsyncClient on top
+// of it discards, with the reason; these are the entries counted by
+// Metrics.EntriesDropped. fn runs on the goroutine that dropped the entry,
+// never with internal locks held, so it may log, count or alert, but
+// should not block.
+//
+// Entries handed to fn have been redacted but not yet enriched with the
+// client defaults.
+func WithOnDrop(fn func(entry LogEntry, reason DropReason)) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.onDrop = fn
+	}
+}
+
+// DeadLetterConfig configures WithDeadLetter.
+type DeadLetterConfig struct {
+	// Sink receives dropped entries, e.g. a FileSink, or another
+	// NfoClient pointing at a secondary nfo-service.
+	Sink Transport
+	// Timeout bounds each Send to the sink (default 5s).
+	Timeout time.Duration
+	// OnError, if set, receives errors from the sink. Entries the sink
+	// fails to take are lost.
+	OnError func(error)
+}
+
+// WithDeadLetter forwards dropped entries to cfg.Sink so they can be
+// recovered later. Entries removed by WithSampling are deliberately
+// discarded and are not forwarded. The sink is called on the goroutine
+// that dropped the entries: with an AsyncClient that is usually the
+// background flusher, but queue overflow and rate limiting happen in the
+// caller of Log, so prefer a fast local sink such as a FileSink.
+//
+//	sink, _ := nfo.OpenFileSink("/var/log/nfo-dead-letter.jsonl", nfo.FileSinkConfig{})
+//	client := nfo.NewClient(url, nfo.WithDeadLetter(nfo.DeadLetterConfig{Sink: sink}))
+func WithDeadLetter(cfg DeadLetterConfig) Option {
+	return func(c *clientConfig) {
+		if cfg.Sink == nil {
+			return
+		}
+		if cfg.Timeout <= 0 {
+			cfg.Timeout = 5 * time.Second
+		}
+		c.client.deadLetter = &cfg
+	}
+}
+
+// Send delivers entries like LogBatch, so an NfoClient can serve as the
+// Transport of another client, for instance as its dead-letter sink.
+func (c *NfoClient) Send(ctx context.Context, entries []LogEntry) error {
+	_, err := c.logBatch(ctx, entries)
+	return err
+}
+
+// discard reports entries dropped for reason to the metrics, the OnDrop
+// callback and the dead-letter sink. Callers must not hold locks.
+func (c *NfoClient) discard(reason DropReason, entries ...LogEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	c.metrics.EntriesDropped(len(entries), reason)
+	if pm, ok := c.metrics.(PriorityMetrics); ok {
+		countByPriority(entries, func(n int, p Priority) { pm.EntriesDroppedByPriority(n, reason, p) })
+	}
+	if c.onDrop != nil {
+		for _, entry := range entries {
+			c.onDrop(entry, reason)
+		}
+	}
+	if dl := c.deadLetter; dl != nil && reason != DropSampled {
+		ctx, cancel := context.WithTimeout(context.Background(), dl.Timeout)
+		err := dl.Sink.Send(ctx, entries)
+		cancel()
+		if err != nil && dl.OnError != nil {
+			dl.OnError(fmt.Errorf("nfo: dead letter: %w", err))
+		}
+	}
+}
+
+// redacted applies the client's redaction to an entry dropped before the
+// redaction stage, if anything is going to see it.
+func (c *NfoClient) redacted(entry LogEntry) LogEntry {
+	if c.redactor == nil || (c.onDrop == nil && c.deadLetter == nil) {
+		return entry
+	}
+	return c.redactor.entry(entry)
+}
+
+// dropReason classifies the error that made a delivery fail.
+func dropReason(err error) DropReason {
+	if errors.Is(err, ErrTooLarge) || errors.Is(err, ErrPayloadTooLarge) {
+		return DropOversized
+	}
+	return DropSendFailed
+}
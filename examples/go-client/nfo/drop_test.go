@@ -0,0 +1,126 @@
+package nfo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type dropRecorder struct {
+	mu      sync.Mutex
+	entries []LogEntry
+	reasons []DropReason
+}
+
+func (d *dropRecorder) onDrop(entry LogEntry, reason DropReason) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = append(d.entries, entry)
+	d.reasons = append(d.reasons, reason)
+}
+
+func TestOnDrop(t *testing.T) {
+	var drops dropRecorder
+	var async *AsyncClient
+	client := NewNfoClient("http://unused")
+	WithOnDrop(func(entry LogEntry, reason DropReason) {
+		async.Len() // must not deadlock
+		drops.onDrop(entry, reason)
+	})(&clientConfig{client: client})
+	async = newAsyncClient(client, AsyncConfig{QueueSize: 1, Overflow: DropOldest})
+
+	async.Log(LogEntry{Cmd: "old"})
+	async.Log(LogEntry{Cmd: "new"})
+	if len(drops.entries) != 1 || drops.entries[0].Cmd != "old" || drops.reasons[0] != DropQueueFull {
+		t.Fatalf("drops = %+v %v", drops.entries, drops.reasons)
+	}
+	if async.Dropped() != 1 {
+		t.Fatalf("Dropped = %d", async.Dropped())
+	}
+}
+
+func TestOnDropSampledIsRedacted(t *testing.T) {
+	var drops dropRecorder
+	client := NewClient("http://unused",
+		WithTransport(NewWriterSink(io.Discard)),
+		WithSampling(1000),
+		WithRedaction(RedactConfig{Fields: []string{"password"}}),
+		WithOnDrop(drops.onDrop),
+	)
+	for range 3 {
+		client.Log(LogEntry{Cmd: "login", Fields: map[string]any{"password": "hunter2"}})
+	}
+	if len(drops.entries) == 0 || drops.reasons[0] != DropSampled {
+		t.Fatalf("drops = %v", drops.reasons)
+	}
+	if got := drops.entries[0].Fields["password"]; got != RedactMask {
+		t.Fatalf("dropped entry not redacted: %v", got)
+	}
+}
+
+func TestDeadLetter(t *testing.T) {
+	rec, srv := newRecorder(t)
+	rec.status = http.StatusRequestEntityTooLarge
+	backup, backupSrv := newRecorder(t)
+
+	var drops dropRecorder
+	client := NewClient(srv.URL,
+		WithOnDrop(drops.onDrop),
+		WithDeadLetter(DeadLetterConfig{Sink: NewClient(backupSrv.URL)}),
+	)
+	async := newAsyncClient(client, AsyncConfig{})
+	async.Log(LogEntry{Cmd: "big", Output: strings.Repeat("x", 100)})
+	async.Log(LogEntry{Cmd: "bigger", Output: strings.Repeat("x", 200)})
+	if _, err := async.FlushContext(context.Background()); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+
+	if len(drops.reasons) != 2 || drops.reasons[0] != DropOversized {
+		t.Fatalf("reasons = %v", drops.reasons)
+	}
+	got := backup.Entries()
+	if len(got) != 2 || got[0].Cmd != "big" || got[1].Cmd != "bigger" {
+		t.Fatalf("dead-letter entries = %+v", got)
+	}
+}
+
+func TestDeadLetterErrors(t *testing.T) {
+	var errs []error
+	sinkErr := errors.New("disk full")
+	client := NewClient("http://unused",
+		WithRateLimit(RateLimitConfig{PerSecond: 0.001}),
+		WithDeadLetter(DeadLetterConfig{
+			Sink:    transportFunc(func(context.Context, []LogEntry) error { return sinkErr }),
+			OnError: func(err error) { errs = append(errs, err) },
+		}),
+	)
+	async := newAsyncClient(client, AsyncConfig{})
+	async.Log(LogEntry{Cmd: "a"})
+	if err := async.Log(LogEntry{Cmd: "b"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], sinkErr) {
+		t.Fatalf("OnError calls = %v", errs)
+	}
+
+	var buf bytes.Buffer
+	sampled := NewClient("http://unused",
+		WithTransport(NewWriterSink(io.Discard)),
+		WithSampling(1000),
+		WithDeadLetter(DeadLetterConfig{Sink: NewWriterSink(&buf)}),
+	)
+	sampled.Log(LogEntry{Cmd: "a"})
+	sampled.Log(LogEntry{Cmd: "b"})
+	if buf.Len() != 0 {
+		t.Fatalf("sampled entries reached the dead-letter sink: %s", buf.String())
+	}
+}
+
+type transportFunc func(ctx context.Context, entries []LogEntry) error
+
+func (f transportFunc) Send(ctx context.Context, entries []LogEntry) error { return f(ctx, entries) }
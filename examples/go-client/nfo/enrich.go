@@ -0,0 +1,152 @@
+package nfo
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Metadata describes where an entry was produced. NewClient detects it once
+// and attaches it to every entry; see WithoutMetadata, WithContainerMetadata
+// and WithMetadata.
+type Metadata struct {
+	Hostname  string `json:"hostname,omitempty"`
+	PID       int    `json:"pid,omitempty"`
+	GoVersion string `json:"go_version,omitempty"`
+	Binary    string `json:"binary,omitempty"`
+	OS        string `json:"os,omitempty"`
+	Arch      string `json:"arch,omitempty"`
+
+	ContainerID  string `json:"container_id,omitempty"`
+	PodName      string `json:"pod_name,omitempty"`
+	PodNamespace string `json:"pod_namespace,omitempty"`
+	NodeName     string `json:"node_name,omitempty"`
+}
+
+// DetectMetadata collects host and process details. With container set it
+// also reads the container ID from /proc/self/cgroup and pod details from
+// the POD_NAME, POD_NAMESPACE and NODE_NAME variables that the Kubernetes
+// downward API conventionally exposes.
+func DetectMetadata(container bool) Metadata {
+	m := Metadata{
+		PID:       os.Getpid(),
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+	}
+	m.Hostname, _ = os.Hostname()
+	if exe, err := os.Executable(); err == nil {
+		m.Binary = filepath.Base(exe)
+	}
+	if container {
+		m.ContainerID = containerID("/proc/self/cgroup")
+		m.PodName = os.Getenv("POD_NAME")
+		m.PodNamespace = os.Getenv("POD_NAMESPACE")
+		m.NodeName = os.Getenv("NODE_NAME")
+	}
+	return m
+}
+
+// WithoutMetadata stops the client from attaching Metadata to entries.
+// Entries that set Meta themselves are still sent unchanged.
+func WithoutMetadata() Option {
+	return func(cfg *clientConfig) {
+		cfg.client.meta = nil
+	}
+}
+
+// WithContainerMetadata adds container and Kubernetes pod details to the
+// detected metadata.
+func WithContainerMetadata() Option {
+	return func(cfg *clientConfig) {
+		m := DetectMetadata(true)
+		if cfg.client.meta != nil {
+			m = mergeMetadata(*cfg.client.meta, m)
+		}
+		cfg.client.meta = &m
+	}
+}
+
+// WithMetadata overrides detected metadata with the non-zero fields of m.
+func WithMetadata(m Metadata) Option {
+	return func(cfg *clientConfig) {
+		if cfg.client.meta != nil {
+			m = mergeMetadata(m, *cfg.client.meta)
+		}
+		cfg.client.meta = &m
+	}
+}
+
+// enrich attaches the client metadata to entry. Fields the entry already
+// carries in its own Meta take precedence.
+func (c *NfoClient) enrich(entry LogEntry) LogEntry {
+	if c.meta == nil {
+		return entry
+	}
+	m := *c.meta
+	if entry.Meta != nil {
+		m = mergeMetadata(*entry.Meta, m)
+	}
+	entry.Meta = &m
+	return entry
+}
+
+// mergeMetadata returns m with its zero-valued fields taken from d.
+func mergeMetadata(m, d Metadata) Metadata {
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	fill(&m.Hostname, d.Hostname)
+	fill(&m.GoVersion, d.GoVersion)
+	fill(&m.Binary, d.Binary)
+	fill(&m.OS, d.OS)
+	fill(&m.Arch, d.Arch)
+	fill(&m.ContainerID, d.ContainerID)
+	fill(&m.PodName, d.PodName)
+	fill(&m.PodNamespace, d.PodNamespace)
+	fill(&m.NodeName, d.NodeName)
+	if m.PID == 0 {
+		m.PID = d.PID
+	}
+	return m
+}
+
+// containerID extracts a 64-hex container ID from a cgroup file, as written
+// by Docker, containerd and CRI-O ("…/docker-<id>.scope", "…/<id>").
+func containerID(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		base := line[strings.LastIndexByte(line, '/')+1:]
+		base = strings.TrimSuffix(base, ".scope")
+		if i := strings.LastIndexAny(base, "-:"); i >= 0 {
+			base = base[i+1:]
+		}
+		if isHexID(base) {
+			return base
+		}
+	}
+	return ""
+}
+
+func isHexID(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
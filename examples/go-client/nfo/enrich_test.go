@@ -0,0 +1,76 @@
+package nfo
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestClientAttachesMetadata(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithMetadata(Metadata{Hostname: "build-7"}))
+
+	client.Log(LogEntry{Cmd: "build"})
+	m := rec.Entries()[0].Meta
+	if m == nil {
+		t.Fatal("expected metadata on entry")
+	}
+	if m.Hostname != "build-7" || m.PID != os.Getpid() || m.GoVersion != runtime.Version() || m.OS != runtime.GOOS {
+		t.Fatalf("unexpected metadata: %+v", m)
+	}
+}
+
+func TestEntryMetadataOverrides(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	client.Log(LogEntry{Cmd: "build", Meta: &Metadata{Binary: "worker"}})
+	m := rec.Entries()[0].Meta
+	if m.Binary != "worker" || m.PID != os.Getpid() {
+		t.Fatalf("per-entry override not merged: %+v", m)
+	}
+}
+
+func TestWithoutMetadata(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithoutMetadata())
+
+	client.Log(LogEntry{Cmd: "build"})
+	if m := rec.Entries()[0].Meta; m != nil {
+		t.Fatalf("expected no metadata, got %+v", m)
+	}
+}
+
+func TestWithContainerMetadata(t *testing.T) {
+	t.Setenv("POD_NAME", "api-5d8f")
+	t.Setenv("POD_NAMESPACE", "payments")
+	client := NewClient("http://unused", WithContainerMetadata())
+
+	if client.meta.PodName != "api-5d8f" || client.meta.PodNamespace != "payments" {
+		t.Fatalf("pod details missing: %+v", client.meta)
+	}
+}
+
+func TestContainerID(t *testing.T) {
+	id := "3f4e1c0d9a2b8c7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b4c3d"
+	tests := map[string]string{
+		"cgroup v1": "12:memory:/docker/" + id + "\n",
+		"systemd":   "0::/system.slice/docker-" + id + ".scope\n",
+		"cri-o":     "0::/kubepods/pod1/crio-" + id + "\n",
+		"none":      "0::/user.slice/user-1000.slice\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "cgroup")
+			os.WriteFile(path, []byte(content), 0o644)
+			want := id
+			if name == "none" {
+				want = ""
+			}
+			if got := containerID(path); got != want {
+				t.Fatalf("containerID = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Errors that classify a failed request, for use with errors.Is. They are
+// matched by *APIError values and, for ErrServiceUnavailable, by requests
+// that got no response at all. See also ErrQueueFull, ErrCircuitOpen and
+// ErrRateLimited, and Retryable.
+var (
+	// ErrServiceUnavailable matches 502, 503 and 504 responses and
+	// requests that failed to reach nfo-service or timed out.
+	ErrServiceUnavailable = errors.New("nfo: service unavailable")
+	// ErrUnauthorized matches 401 and 403 responses: the credentials are
+	// missing, wrong or lack permission.
+	ErrUnauthorized = errors.New("nfo: unauthorized")
+	// ErrPayloadTooLarge matches 413 responses.
+	ErrPayloadTooLarge = errors.New("nfo: payload too large")
+)
+
+// maxErrorBody caps the response body kept in APIError.Body.
+const maxErrorBody = 1 << 10
+
+// APIError reports a response from nfo-service with a status other than
+// 200:
+//
+//	var apiErr *nfo.APIError
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict { ... }
+//
+// It matches ErrServiceUnavailable, ErrUnauthorized or ErrPayloadTooLarge
+// with errors.Is according to StatusCode.
+type APIError struct {
+	StatusCode int
+	// Body is the start of the response body, usually the service's
+	// explanation, with surrounding whitespace removed.
+	Body string
+}
+
+func (e *APIError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("nfo-service returned %d", e.StatusCode)
+	}
+	return fmt.Sprintf("nfo-service returned %d: %s", e.StatusCode, e.Body)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrServiceUnavailable:
+		return e.StatusCode == http.StatusBadGateway || e.StatusCode == http.StatusServiceUnavailable ||
+			e.StatusCode == http.StatusGatewayTimeout
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrPayloadTooLarge:
+		return e.StatusCode == http.StatusRequestEntityTooLarge
+	}
+	return false
+}
+
+// newAPIError builds the APIError for resp, reading at most maxErrorBody
+// bytes of its body.
+func newAPIError(resp *http.Response) *APIError {
+	body := make([]byte, maxErrorBody)
+	n, _ := io.ReadFull(resp.Body, body)
+	return &APIError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body[:n]))}
+}
+
+// transportError reports a request that got no response; op is the
+// lower-case HTTP method.
+type transportError struct {
+	op  string
+	err error
+}
+
+func (e *transportError) Error() string { return e.op + ": " + e.err.Error() }
+
+func (e *transportError) Unwrap() error { return e.err }
+
+func (e *transportError) Is(target error) bool {
+	return target == ErrServiceUnavailable && !errors.Is(e.err, context.Canceled)
+}
+
+// Retryable reports whether the operation that returned err may succeed if
+// tried again later: the service was unreachable, overloaded or timed out
+// (including 429 and 5xx responses), the circuit breaker was open or the
+// async queue was full. It is false for nil, for rejected requests such as
+// ErrUnauthorized and ErrPayloadTooLarge, for entries dropped by the rate
+// limiter, and for a closed client, invalid configuration or cancelled
+// context, where trying again cannot help.
+func Retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	for _, permanent := range []error{ErrClosed, ErrInvalidConfig, ErrUnsupported, ErrTooLarge, ErrRateLimited, context.Canceled} {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+	return retryable(err)
+}
+
+// retryable reports whether err is worth another attempt by the client's
+// retry policy: transport failures, 429 and 5xx responses are; other
+// statuses are not.
+func retryable(err error) bool {
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.StatusCode == http.StatusTooManyRequests || ae.StatusCode >= 500
+	}
+	return true
+}
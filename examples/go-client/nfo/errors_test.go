@@ -0,0 +1,86 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIErrors(t *testing.T) {
+	for _, tc := range []struct {
+		status    int
+		body      string
+		is        error
+		retryable bool
+	}{
+		{http.StatusServiceUnavailable, "overloaded\n", ErrServiceUnavailable, true},
+		{http.StatusUnauthorized, "bad token", ErrUnauthorized, false},
+		{http.StatusForbidden, "", ErrUnauthorized, false},
+		{http.StatusRequestEntityTooLarge, strings.Repeat("x", 5000), ErrPayloadTooLarge, false},
+		{http.StatusTooManyRequests, "", nil, true},
+		{http.StatusBadRequest, "missing cmd", nil, false},
+	} {
+		t.Run(fmt.Sprint(tc.status), func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+
+			err := NewClient(srv.URL, WithRetry(1, 0)).Log(LogEntry{Cmd: "a"})
+			var ae *APIError
+			if !errors.As(err, &ae) || ae.StatusCode != tc.status {
+				t.Fatalf("err = %v, want an APIError with status %d", err, tc.status)
+			}
+			if want := strings.TrimSpace(tc.body); len(want) > maxErrorBody {
+				if len(ae.Body) != maxErrorBody {
+					t.Fatalf("body not capped: %d bytes", len(ae.Body))
+				}
+			} else if ae.Body != want {
+				t.Fatalf("Body = %q, want %q", ae.Body, want)
+			}
+			for _, sentinel := range []error{ErrServiceUnavailable, ErrUnauthorized, ErrPayloadTooLarge} {
+				if errors.Is(err, sentinel) != (sentinel == tc.is) {
+					t.Errorf("errors.Is(%v, %v) = %v", err, sentinel, !(sentinel == tc.is))
+				}
+			}
+			if Retryable(err) != tc.retryable {
+				t.Errorf("Retryable(%v) = %v", err, !tc.retryable)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	unreachable := NewClient(srv.URL, WithRetry(1, 0)).Log(LogEntry{Cmd: "a"})
+	if !errors.Is(unreachable, ErrServiceUnavailable) || !Retryable(unreachable) {
+		t.Fatalf("unreachable service: %v", unreachable)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	canceled := NewClient(srv.URL).LogContext(ctx, LogEntry{Cmd: "a"})
+	if canceled == nil || errors.Is(canceled, ErrServiceUnavailable) || Retryable(canceled) {
+		t.Fatalf("cancelled request: %v", canceled)
+	}
+
+	for err, want := range map[error]bool{
+		nil:                                false,
+		ErrQueueFull:                       true,
+		ErrCircuitOpen:                     true,
+		fmt.Errorf("flush: %w", ErrClosed): false,
+		fmt.Errorf("%w: bad url", ErrInvalidConfig): false,
+		ErrRateLimited:                 false,
+		errors.New("connection reset"): true,
+	} {
+		if Retryable(err) != want {
+			t.Errorf("Retryable(%v) = %v", err, !want)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxOutput caps how much of each stream RunCommand keeps.
+const DefaultMaxOutput = 64 << 10
+
+// RunOptions tunes RunCommandWith.
+type RunOptions struct {
+	// MaxOutput caps the captured bytes of stdout and of stderr
+	// (DefaultMaxOutput if <= 0). The tail of each stream is kept.
+	MaxOutput int
+	// Stdout and Stderr, if set, receive the command's output live while
+	// it is captured, e.g. os.Stdout for long-running commands.
+	Stdout io.Writer
+	Stderr io.Writer
+	// Dir and Env are passed to exec.Cmd.
+	Dir string
+	Env []string
+}
+
+// CommandResult describes a finished command.
+type CommandResult struct {
+	ExitCode  int
+	Stdout    string
+	Stderr    string
+	Duration  time.Duration
+	Truncated bool
+}
+
+// RunCommand executes name with args via os/exec, captures its output,
+// exit code and duration, and logs the run as a LogEntry. The returned
+// error is the command's own error (e.g. *exec.ExitError) joined with any
+// logging error.
+func (c *NfoClient) RunCommand(ctx context.Context, name string, args ...string) (*CommandResult, error) {
+	return runCommand(ctx, c, RunOptions{}, name, args)
+}
+
+// RunCommandWith is RunCommand with explicit options.
+func (c *NfoClient) RunCommandWith(ctx context.Context, opts RunOptions, name string, args ...string) (*CommandResult, error) {
+	return runCommand(ctx, c, opts, name, args)
+}
+
+// RunCommand executes a command and enqueues the resulting entry.
+func (a *AsyncClient) RunCommand(ctx context.Context, name string, args ...string) (*CommandResult, error) {
+	return runCommand(ctx, a, RunOptions{}, name, args)
+}
+
+// RunCommandWith is RunCommand with explicit options.
+func (a *AsyncClient) RunCommandWith(ctx context.Context, opts RunOptions, name string, args ...string) (*CommandResult, error) {
+	return runCommand(ctx, a, opts, name, args)
+}
+
+func runCommand(ctx context.Context, logger Logger, opts RunOptions, name string, args []string) (*CommandResult, error) {
+	if opts.MaxOutput <= 0 {
+		opts.MaxOutput = DefaultMaxOutput
+	}
+	stdout := &tailBuffer{max: opts.MaxOutput}
+	stderr := &tailBuffer{max: opts.MaxOutput}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = opts.Dir
+	cmd.Env = opts.Env
+	cmd.Stdout = teeWriter(stdout, opts.Stdout)
+	cmd.Stderr = teeWriter(stderr, opts.Stderr)
+
+	start := time.Now()
+	runErr := cmd.Run()
+	elapsed := time.Since(start)
+
+	result := &CommandResult{
+		ExitCode:  exitCode(cmd, runErr),
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Duration:  elapsed,
+		Truncated: stdout.truncated() || stderr.truncated(),
+	}
+
+	success := runErr == nil
+	durationMs := float64(elapsed.Milliseconds())
+	entry := LogEntry{
+		Cmd:        name,
+		Args:       args,
+		Language:   "shell",
+		Success:    &success,
+		DurationMs: &durationMs,
+		Output:     result.Stdout,
+	}
+	if runErr != nil {
+		entry.Error = strings.TrimSpace(fmt.Sprintf("%v\n%s", runErr, result.Stderr))
+	}
+
+	// The command may have been stopped by ctx; log it regardless.
+	if err := logContext(context.WithoutCancel(ctx), logger, entry); err != nil {
+		return result, errors.Join(runErr, err)
+	}
+	return result, runErr
+}
+
+// exitCode returns the process exit code, or -1 if it never ran.
+func exitCode(cmd *exec.Cmd, err error) int {
+	if cmd.ProcessState != nil {
+		return cmd.ProcessState.ExitCode()
+	}
+	if err != nil {
+		return -1
+	}
+	return 0
+}
+
+func teeWriter(buf io.Writer, live io.Writer) io.Writer {
+	if live == nil {
+		return buf
+	}
+	return io.MultiWriter(buf, live)
+}
+
+// tailBuffer keeps the last max bytes written to it.
+type tailBuffer struct {
+	mu      sync.Mutex
+	max     int
+	buf     []byte
+	dropped int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+		b.dropped += over
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.dropped > 0 {
+		return fmt.Sprintf("[... %d bytes truncated ...]\n%s", b.dropped, b.buf)
+	}
+	return string(b.buf)
+}
+
+func (b *tailBuffer) truncated() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dropped > 0
+}
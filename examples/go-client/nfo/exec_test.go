@@ -0,0 +1,71 @@
+package nfo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRunCommandSuccess(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	res, err := client.RunCommand(context.Background(), "sh", "-c", "echo hello")
+	if err != nil {
+		t.Fatalf("RunCommand: %v", err)
+	}
+	if res.ExitCode != 0 || res.Stdout != "hello\n" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	e := rec.Entries()[0]
+	if e.Cmd != "sh" || e.Language != "shell" || e.Success == nil || !*e.Success || e.Output != "hello\n" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+}
+
+func TestRunCommandFailure(t *testing.T) {
+	mem := &memLogger{}
+	res, err := runCommand(context.Background(), mem, RunOptions{}, "sh", []string{"-c", "echo oops >&2; exit 3"})
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected *exec.ExitError, got %v", err)
+	}
+	if res.ExitCode != 3 || res.Stderr != "oops\n" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	e := mem.Entries()[0]
+	if *e.Success || !strings.Contains(e.Error, "exit status 3") || !strings.Contains(e.Error, "oops") {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+}
+
+func TestRunCommandStreamsAndTruncates(t *testing.T) {
+	mem := &memLogger{}
+	var live bytes.Buffer
+	res, err := runCommand(context.Background(), mem, RunOptions{MaxOutput: 4, Stdout: &live},
+		"sh", []string{"-c", "printf 0123456789"})
+	if err != nil {
+		t.Fatalf("runCommand: %v", err)
+	}
+	if live.String() != "0123456789" {
+		t.Errorf("live stream = %q", live.String())
+	}
+	if !res.Truncated || !strings.HasSuffix(res.Stdout, "6789") || !strings.Contains(res.Stdout, "6 bytes truncated") {
+		t.Errorf("unexpected capture: %+v", res)
+	}
+}
+
+func TestRunCommandNotFound(t *testing.T) {
+	mem := &memLogger{}
+	res, err := runCommand(context.Background(), mem, RunOptions{}, "nfo-no-such-binary", nil)
+	if err == nil || res.ExitCode != -1 {
+		t.Fatalf("expected start failure, got %+v, %v", res, err)
+	}
+	if len(mem.Entries()) != 1 {
+		t.Fatal("failed start should still be logged")
+	}
+}
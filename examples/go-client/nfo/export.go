@@ -0,0 +1,164 @@
+package nfo
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFormat encodes the entries written by Export. CSV and JSONL are
+// built in; the nfoparquet module adds Parquet.
+type ExportFormat interface {
+	// NewEncoder starts an export to w, writing any header.
+	NewEncoder(w io.Writer) (ExportEncoder, error)
+}
+
+// ExportEncoder writes entries in one ExportFormat.
+type ExportEncoder interface {
+	Encode(entry LogEntry) error
+	// Close writes any trailer and flushes buffered data. It does not
+	// close the underlying writer.
+	Close() error
+}
+
+// Built-in export formats. CSV writes one row per entry with the columns
+// of ExportColumns; JSONL writes each entry as the JSON object sent to
+// nfo-service, one per line.
+var (
+	CSV   ExportFormat = csvFormat{}
+	JSONL ExportFormat = jsonlFormat{}
+)
+
+// ExportColumns are the CSV header and the columns of flat export formats.
+// Timestamps are RFC 3339 in UTC; args and fields are JSON.
+var ExportColumns = []string{
+	"timestamp", "level", "env", "cmd", "args", "language", "success",
+	"duration_ms", "output", "error", "correlation_id", "trace_id",
+	"span_id", "fingerprint", "repeat_count", "hostname", "fields",
+}
+
+// ExportFormatByName returns the built-in format called name: "csv" or
+// "jsonl" (also "ndjson").
+func ExportFormatByName(name string) (ExportFormat, bool) {
+	switch strings.ToLower(name) {
+	case "csv":
+		return CSV, true
+	case "jsonl", "ndjson":
+		return JSONL, true
+	}
+	return nil, false
+}
+
+// Export streams every entry matching params to w in format, fetching
+// pages of params.Limit entries (DefaultPageSize if unset) as it goes, so
+// exports of any size run in constant memory. It returns the number of
+// entries written.
+//
+//	f, _ := os.Create("failures.csv")
+//	defer f.Close()
+//	n, err := client.Export(ctx, nfo.QueryParams{Env: "prod", Level: nfo.LevelError}, f, nfo.CSV)
+func (c *NfoClient) Export(ctx context.Context, params QueryParams, w io.Writer, format ExportFormat) (int, error) {
+	enc, err := format.NewEncoder(w)
+	if err != nil {
+		return 0, fmt.Errorf("nfo: export: %w", err)
+	}
+	var n int
+	for entry, err := range c.QueryAll(ctx, params) {
+		if err != nil {
+			if cerr := enc.Close(); cerr != nil {
+				err = errors.Join(err, fmt.Errorf("nfo: export: %w", cerr))
+			}
+			return n, err
+		}
+		if err := enc.Encode(entry); err != nil {
+			return n, fmt.Errorf("nfo: export: %w", errors.Join(err, enc.Close()))
+		}
+		n++
+	}
+	if err := enc.Close(); err != nil {
+		return n, fmt.Errorf("nfo: export: %w", err)
+	}
+	return n, nil
+}
+
+// ExportRecord returns entry as the values of ExportColumns.
+func ExportRecord(entry LogEntry) []string {
+	var ts, level, success, duration, repeats, hostname string
+	if entry.Timestamp != nil {
+		ts = entry.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	if entry.Level != 0 {
+		level = entry.Level.String()
+	}
+	if entry.Success != nil {
+		success = strconv.FormatBool(*entry.Success)
+	}
+	if entry.DurationMs != nil {
+		duration = strconv.FormatFloat(*entry.DurationMs, 'f', -1, 64)
+	}
+	if entry.RepeatCount != 0 {
+		repeats = strconv.Itoa(entry.RepeatCount)
+	}
+	if entry.Meta != nil {
+		hostname = entry.Meta.Hostname
+	}
+	return []string{
+		ts, level, entry.Env, entry.Cmd, jsonString(entry.Args), entry.Language, success,
+		duration, entry.Output, entry.Error, entry.CorrelationID, entry.TraceID,
+		entry.SpanID, entry.Fingerprint, repeats, hostname, jsonString(entry.Fields),
+	}
+}
+
+// jsonString encodes v as JSON, or "" if it is empty.
+func jsonString[T any](v T) string {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return ""
+	}
+	return string(data)
+}
+
+type csvFormat struct{}
+
+func (csvFormat) NewEncoder(w io.Writer) (ExportEncoder, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ExportColumns); err != nil {
+		return nil, err
+	}
+	return csvEncoder{cw}, nil
+}
+
+type csvEncoder struct {
+	w *csv.Writer
+}
+
+func (e csvEncoder) Encode(entry LogEntry) error {
+	return e.w.Write(ExportRecord(entry))
+}
+
+func (e csvEncoder) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlFormat struct{}
+
+func (jsonlFormat) NewEncoder(w io.Writer) (ExportEncoder, error) {
+	return jsonlEncoder{json.NewEncoder(w)}, nil
+}
+
+type jsonlEncoder struct {
+	enc *json.Encoder
+}
+
+func (e jsonlEncoder) Encode(entry LogEntry) error {
+	return e.enc.Encode(entry)
+}
+
+func (jsonlEncoder) Close() error { return nil }
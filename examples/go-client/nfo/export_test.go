@@ -0,0 +1,133 @@
+package nfo
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportPages(t *testing.T) {
+	var requests int
+	srv := newLogsServer(t, 250, func(*http.Request) { requests++ })
+	client := NewClient(srv.URL)
+
+	var buf bytes.Buffer
+	n, err := client.Export(context.Background(), QueryParams{}, &buf, CSV)
+	if err != nil || n != 250 {
+		t.Fatalf("Export = %d, %v", n, err)
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 pages, got %d requests", requests)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 251 || strings.Join(records[0], ",") != strings.Join(ExportColumns, ",") || records[250][3] != "cmd-249" {
+		t.Fatalf("unexpected CSV: %d records, header %v", len(records), records[0])
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("CEST", 2*3600))
+	ok, ms := false, 12.5
+	stored := []LogEntry{{
+		Timestamp: &ts, Level: LevelError, Env: "prod", Cmd: "deploy", Args: []string{"--tag", "v 2"},
+		Success: &ok, DurationMs: &ms, Error: "exit 1\nrollback", RepeatCount: 3,
+		Meta: &Metadata{Hostname: "web-1"}, Fields: map[string]any{"team": "core"},
+	}, {Cmd: "plain"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") != "" {
+			w.Write([]byte("[]"))
+			return
+		}
+		json.NewEncoder(w).Encode(stored)
+	}))
+	t.Cleanup(srv.Close)
+	client := NewClient(srv.URL)
+
+	var buf bytes.Buffer
+	if _, err := client.Export(context.Background(), QueryParams{}, &buf, CSV); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(records) != 3 {
+		t.Fatalf("records = %v, %v", records, err)
+	}
+	want := []string{"2024-05-06T05:08:09Z", "error", "prod", "deploy", `["--tag","v 2"]`, "", "false",
+		"12.5", "", "exit 1\nrollback", "", "", "", "", "3", "web-1", `{"team":"core"}`}
+	if strings.Join(records[1], "|") != strings.Join(want, "|") {
+		t.Fatalf("row = %q\nwant %q", records[1], want)
+	}
+	if records[2][1] != "" || records[2][4] != "" || records[2][16] != "" {
+		t.Fatalf("empty values not left blank: %q", records[2])
+	}
+
+	format, found := ExportFormatByName("JSONL")
+	if !found {
+		t.Fatal("jsonl format not found")
+	}
+	buf.Reset()
+	if n, err := client.Export(context.Background(), QueryParams{}, &buf, format); err != nil || n != 2 {
+		t.Fatalf("Export = %d, %v", n, err)
+	}
+	var lines []LogEntry
+	for sc := bufio.NewScanner(&buf); sc.Scan(); {
+		var e LogEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		lines = append(lines, e)
+	}
+	if len(lines) != 2 || lines[0].Error != "exit 1\nrollback" || lines[0].Meta.Hostname != "web-1" || lines[1].Cmd != "plain" {
+		t.Fatalf("lines = %+v", lines)
+	}
+}
+
+// failingFormat's encoder fails every Encode and records Close.
+type failingFormat struct{ closed *bool }
+
+func (f failingFormat) NewEncoder(io.Writer) (ExportEncoder, error) { return f, nil }
+func (f failingFormat) Encode(LogEntry) error                       { return errors.New("disk full") }
+func (f failingFormat) Close() error                                { *f.closed = true; return nil }
+
+func TestExportEncodeError(t *testing.T) {
+	srv := newLogsServer(t, 3, nil)
+	var closed bool
+	n, err := NewClient(srv.URL).Export(context.Background(), QueryParams{}, io.Discard, failingFormat{&closed})
+	if n != 0 || err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("Export = %d, %v", n, err)
+	}
+	if !closed {
+		t.Fatal("encoder not closed after a failed Encode")
+	}
+}
+
+func TestExportError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("offset") != "" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode([]LogEntry{{Cmd: "a"}, {Cmd: "b"}})
+	}))
+	t.Cleanup(srv.Close)
+
+	var buf bytes.Buffer
+	n, err := NewClient(srv.URL, WithRetry(1, 0)).Export(context.Background(), QueryParams{Limit: 2}, &buf, CSV)
+	if err == nil || n != 2 {
+		t.Fatalf("Export = %d, %v; want 2 entries and an error", n, err)
+	}
+	if _, found := ExportFormatByName("xlsx"); found {
+		t.Fatal("unknown format found")
+	}
+}
@@ -0,0 +1,246 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FailoverConfig configures WithFailover. Zero values select defaults.
+type FailoverConfig struct {
+	// Endpoints lists replica base URLs, tried in order after the URL
+	// passed to NewClient, which is the primary.
+	Endpoints []string
+	// Resolver, if set, returns the full endpoint list, primary first,
+	// replacing the primary and Endpoints. It is called before the first
+	// request and again every ResolveInterval; on error or an empty
+	// result the previous list is kept.
+	Resolver func(ctx context.Context) ([]string, error)
+	// ResolveInterval is how often Resolver is called again (default 1m).
+	ResolveInterval time.Duration
+	// FailureThreshold is the number of consecutive failed attempts that
+	// marks an endpoint unhealthy (default 3).
+	FailureThreshold int
+	// ProbeInterval is how often an unhealthy endpoint is checked with
+	// GET /health (default 10s).
+	ProbeInterval time.Duration
+	// OnFailover, if set, is called whenever requests move to another
+	// endpoint, including back to a recovered primary.
+	OnFailover func(from, to string)
+}
+
+// WithFailover spreads the client over several nfo-service endpoints.
+// Requests go to the first healthy endpoint in priority order; one that
+// fails FailureThreshold attempts in a row is skipped until a probe finds
+// it healthy again, and traffic then moves back to it. While every
+// endpoint is unhealthy, attempts rotate through all of them.
+//
+// Only failures that WithRetry would retry count, so pair it with
+// WithRetry to let a failing request continue on the next endpoint.
+// Probes run in the background while the client is in use. Failover
+// applies to HTTP only; it has no effect with WithTransport.
+func WithFailover(cfg FailoverConfig) Option {
+	return func(c *clientConfig) {
+		c.client.failover = newFailover(c.client, cfg)
+	}
+}
+
+// Endpoint reports the base URL requests are currently sent to.
+func (c *NfoClient) Endpoint() string {
+	if c.failover == nil {
+		return c.BaseURL
+	}
+	return c.failover.active()
+}
+
+// baseURL picks the endpoint for the next attempt.
+func (c *NfoClient) baseURL(ctx context.Context) string {
+	if c.failover == nil {
+		return c.BaseURL
+	}
+	return c.failover.pick(ctx)
+}
+
+type failover struct {
+	cfg    FailoverConfig
+	client *NfoClient
+	now    func() time.Time
+
+	mu         sync.Mutex
+	endpoints  []*endpoint
+	current    string
+	next       int
+	resolved   bool
+	resolving  bool
+	resolvedAt time.Time
+}
+
+// endpoint is the health record of one base URL.
+type endpoint struct {
+	url      string
+	failures int
+	down     bool
+	checked  time.Time
+	probing  bool
+}
+
+func newFailover(client *NfoClient, cfg FailoverConfig) *failover {
+	if cfg.ResolveInterval <= 0 {
+		cfg.ResolveInterval = time.Minute
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+	if cfg.ProbeInterval <= 0 {
+		cfg.ProbeInterval = 10 * time.Second
+	}
+	return &failover{cfg: cfg, client: client, now: time.Now}
+}
+
+// active returns the endpoint of the latest attempt, or the primary
+// before any.
+func (f *failover) active() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.current == "" {
+		return f.client.BaseURL
+	}
+	return f.current
+}
+
+// pick returns the endpoint for the next attempt and starts any probes or
+// resolver refreshes that are due.
+func (f *failover) pick(ctx context.Context) string {
+	f.resolve(ctx)
+
+	f.mu.Lock()
+	notify := func() {}
+	defer func() {
+		f.mu.Unlock()
+		notify()
+	}()
+
+	if f.endpoints == nil {
+		f.endpoints = endpointsOf(append([]string{f.client.BaseURL}, f.cfg.Endpoints...))
+	}
+	now := f.now()
+	if f.cfg.Resolver != nil && !f.resolving && now.Sub(f.resolvedAt) >= f.cfg.ResolveInterval {
+		f.resolving = true
+		go f.refresh()
+	}
+
+	var chosen *endpoint
+	for _, e := range f.endpoints {
+		if !e.down {
+			if chosen == nil {
+				chosen = e
+			}
+			continue
+		}
+		if !e.probing && now.Sub(e.checked) >= f.cfg.ProbeInterval {
+			e.probing = true
+			go f.probe(e)
+		}
+	}
+	if chosen == nil {
+		chosen = f.endpoints[f.next%len(f.endpoints)]
+		f.next++
+	}
+
+	if from := f.current; from != chosen.url {
+		f.current = chosen.url
+		if from != "" && f.cfg.OnFailover != nil {
+			notify = func() { f.cfg.OnFailover(from, chosen.url) }
+		}
+	}
+	return chosen.url
+}
+
+// record reports the outcome of an attempt against url. Like the circuit
+// breaker, only failures worth retrying count against the endpoint.
+func (f *failover) record(url string, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	ok := err == nil || !retryable(err)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, e := range f.endpoints {
+		if e.url != url {
+			continue
+		}
+		if ok {
+			e.failures, e.down = 0, false
+			return
+		}
+		e.failures++
+		if !e.down && e.failures >= f.cfg.FailureThreshold {
+			e.down, e.checked = true, f.now()
+		}
+		return
+	}
+}
+
+// probe checks an unhealthy endpoint and marks it healthy if it answers.
+func (f *failover) probe(e *endpoint) {
+	_, err := f.client.send(context.Background(), e.url, http.MethodGet, "/health", "", nil, "")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e.probing, e.checked = false, f.now()
+	if err == nil {
+		e.failures, e.down = 0, false
+	}
+}
+
+// resolve runs the first resolution synchronously, so the first request
+// already uses the resolved list.
+func (f *failover) resolve(ctx context.Context) {
+	if f.cfg.Resolver == nil {
+		return
+	}
+	f.mu.Lock()
+	done := f.resolved
+	f.resolved = true
+	f.mu.Unlock()
+	if !done {
+		f.update(f.cfg.Resolver(ctx))
+	}
+}
+
+// refresh calls the resolver again in the background.
+func (f *failover) refresh() {
+	f.update(f.cfg.Resolver(context.Background()))
+}
+
+// update installs a resolved list, keeping the health of known endpoints.
+func (f *failover) update(urls []string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resolving, f.resolvedAt = false, f.now()
+	if err != nil || len(urls) == 0 {
+		return
+	}
+	known := make(map[string]*endpoint, len(f.endpoints))
+	for _, e := range f.endpoints {
+		known[e.url] = e
+	}
+	fresh := endpointsOf(urls)
+	for i, e := range fresh {
+		if old, ok := known[e.url]; ok {
+			fresh[i] = old
+		}
+	}
+	f.endpoints = fresh
+}
+
+func endpointsOf(urls []string) []*endpoint {
+	endpoints := make([]*endpoint, len(urls))
+	for i, url := range urls {
+		endpoints[i] = &endpoint{url: url}
+	}
+	return endpoints
+}
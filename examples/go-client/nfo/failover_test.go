@@ -0,0 +1,144 @@
+package nfo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// replica is an nfo-service stand-in whose status can be switched.
+type replica struct {
+	*httptest.Server
+	status atomic.Int32
+	logs   atomic.Int32
+	probes atomic.Int32
+}
+
+func newReplica(t *testing.T) *replica {
+	t.Helper()
+	r := &replica{}
+	r.status.Store(http.StatusOK)
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/health" {
+			r.probes.Add(1)
+		} else {
+			r.logs.Add(1)
+		}
+		w.WriteHeader(int(r.status.Load()))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestFailoverToReplicaAndBack(t *testing.T) {
+	primary, standby := newReplica(t), newReplica(t)
+	primary.status.Store(http.StatusServiceUnavailable)
+
+	var mu sync.Mutex
+	var moves []string
+	client := NewClient(primary.URL, WithRetry(3, time.Millisecond), WithFailover(FailoverConfig{
+		Endpoints:        []string{standby.URL},
+		FailureThreshold: 2,
+		ProbeInterval:    time.Hour,
+		OnFailover: func(from, to string) {
+			mu.Lock()
+			defer mu.Unlock()
+			moves = append(moves, from+" -> "+to)
+		},
+	}))
+	now := time.Now()
+	client.failover.now = func() time.Time { return now }
+
+	if err := client.Log(LogEntry{Cmd: "a"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if primary.logs.Load() != 2 || standby.logs.Load() != 1 {
+		t.Fatalf("primary=%d standby=%d", primary.logs.Load(), standby.logs.Load())
+	}
+	if client.Endpoint() != standby.URL {
+		t.Fatalf("Endpoint = %s, want standby", client.Endpoint())
+	}
+
+	// Unhealthy endpoints are skipped without a request until probed.
+	client.Log(LogEntry{Cmd: "b"})
+	if primary.logs.Load() != 2 || primary.probes.Load() != 0 {
+		t.Fatalf("primary contacted while unhealthy: logs=%d probes=%d",
+			primary.logs.Load(), primary.probes.Load())
+	}
+
+	primary.status.Store(http.StatusOK)
+	now = now.Add(time.Hour)
+	deadline := time.Now().Add(2 * time.Second)
+	for client.Endpoint() != primary.URL {
+		if time.Now().After(deadline) {
+			t.Fatal("traffic never returned to the recovered primary")
+		}
+		client.Log(LogEntry{Cmd: "c"})
+		time.Sleep(5 * time.Millisecond)
+	}
+	if primary.probes.Load() == 0 {
+		t.Fatal("primary was not probed")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{primary.URL + " -> " + standby.URL, standby.URL + " -> " + primary.URL}
+	if len(moves) != 2 || moves[0] != want[0] || moves[1] != want[1] {
+		t.Fatalf("OnFailover calls = %v", moves)
+	}
+}
+
+func TestFailoverAllUnhealthyRotates(t *testing.T) {
+	a, b := newReplica(t), newReplica(t)
+	a.status.Store(http.StatusBadGateway)
+	b.status.Store(http.StatusBadGateway)
+	client := NewClient(a.URL, WithFailover(FailoverConfig{
+		Endpoints:        []string{b.URL},
+		FailureThreshold: 1,
+		ProbeInterval:    time.Hour,
+	}))
+
+	for i := 0; i < 4; i++ {
+		client.Log(LogEntry{Cmd: "x"})
+	}
+	if a.logs.Load() != 2 || b.logs.Load() != 2 {
+		t.Fatalf("a=%d b=%d, want attempts spread evenly", a.logs.Load(), b.logs.Load())
+	}
+}
+
+func TestFailoverClientErrorsKeepEndpoint(t *testing.T) {
+	primary, standby := newReplica(t), newReplica(t)
+	primary.status.Store(http.StatusBadRequest)
+	client := NewClient(primary.URL, WithFailover(FailoverConfig{
+		Endpoints:        []string{standby.URL},
+		FailureThreshold: 1,
+	}))
+
+	client.Log(LogEntry{Cmd: "a"})
+	client.Log(LogEntry{Cmd: "b"})
+	if primary.logs.Load() != 2 || standby.logs.Load() != 0 {
+		t.Fatalf("primary=%d standby=%d", primary.logs.Load(), standby.logs.Load())
+	}
+}
+
+func TestFailoverResolver(t *testing.T) {
+	resolved := newReplica(t)
+	calls := 0
+	client := NewClient("http://unused.invalid", WithFailover(FailoverConfig{
+		Resolver: func(context.Context) ([]string, error) {
+			calls++
+			return []string{resolved.URL}, nil
+		},
+	}))
+
+	if err := client.Log(LogEntry{Cmd: "a"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if calls != 1 || resolved.logs.Load() != 1 || client.Endpoint() != resolved.URL {
+		t.Fatalf("calls=%d logs=%d endpoint=%s", calls, resolved.logs.Load(), client.Endpoint())
+	}
+}
@@ -0,0 +1,29 @@
+package nfo
+
+// reservedKeys are the top-level JSON names of LogEntry. Flattened fields
+// with these names are skipped rather than emitted as duplicate keys.
+var reservedKeys = map[string]bool{
+	"cmd": true, "args": true, "language": true, "env": true,
+	"success": true, "duration_ms": true, "output": true, "error": true,
+	"level": true, "timestamp": true, "clock_skew_ms": true, "correlation_id": true,
+	"truncated_bytes": true, "attachments": true,
+	"fingerprint": true, "repeat_count": true, "id": true,
+	"fields": true, "meta": true,
+}
+
+// WithFields adds fields to every entry. Keys set on the entry itself win.
+func WithFields(fields map[string]any) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.defaults.Fields = mergeFields(fields, cfg.client.defaults.Fields)
+	}
+}
+
+// WithFlattenFields sends Fields as top-level keys named prefix+key
+// instead of a nested "fields" object, for services that only index flat
+// documents. Keys that would clash with a LogEntry field are dropped.
+func WithFlattenFields(prefix string) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.flatten = true
+		cfg.client.flattenPrefix = prefix
+	}
+}
@@ -0,0 +1,58 @@
+package nfo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFieldsNested(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithFields(map[string]any{"region": "eu", "team": "infra"}))
+
+	client.Log(LogEntry{Cmd: "build", Fields: map[string]any{"request_id": "r-1", "team": "payments"}})
+	f := rec.Entries()[0].Fields
+	if f["region"] != "eu" || f["request_id"] != "r-1" || f["team"] != "payments" {
+		t.Fatalf("unexpected fields: %v", f)
+	}
+}
+
+func TestFieldsDefaultsNotShared(t *testing.T) {
+	defaults := map[string]any{"region": "eu"}
+	_, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithFields(defaults))
+
+	own := map[string]any{"user_id": 7}
+	client.Log(LogEntry{Cmd: "build", Fields: own})
+	if len(defaults) != 1 || len(own) != 1 {
+		t.Fatalf("caller maps were modified: %v %v", defaults, own)
+	}
+}
+
+func TestFieldsFlattened(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithoutMetadata(), WithFlattenFields("f_"))
+
+	client.Log(LogEntry{Cmd: "build", Fields: map[string]any{"region": "eu", "attempt": 2}})
+	var doc map[string]any
+	if err := json.Unmarshal(rec.Body(), &doc); err != nil {
+		t.Fatalf("invalid JSON %s: %v", rec.Body(), err)
+	}
+	if doc["f_region"] != "eu" || doc["f_attempt"] != float64(2) {
+		t.Fatalf("fields not flattened: %s", rec.Body())
+	}
+	if _, nested := doc["fields"]; nested {
+		t.Fatalf("nested fields still present: %s", rec.Body())
+	}
+}
+
+func TestFieldsFlattenedSkipsReserved(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithFlattenFields(""))
+
+	client.Log(LogEntry{Cmd: "build", Fields: map[string]any{"cmd": "evil", "ok": true}})
+	var doc map[string]any
+	json.Unmarshal(rec.Body(), &doc)
+	if doc["cmd"] != "build" || doc["ok"] != true {
+		t.Fatalf("unexpected document: %s", rec.Body())
+	}
+}
@@ -0,0 +1,284 @@
+package nfo
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultFileMaxBytes is the rotation size used when FileSinkConfig.MaxBytes
+// is 0.
+const DefaultFileMaxBytes = 100 << 20
+
+// rotateLayout timestamps rotated files; it sorts chronologically.
+const rotateLayout = "20060102T150405.000"
+
+// FileSinkConfig configures OpenFileSink. Zero values select the defaults.
+type FileSinkConfig struct {
+	// MaxBytes rotates the file before a write would take it past this
+	// size (default DefaultFileMaxBytes).
+	MaxBytes int64
+	// MaxAge rotates the file once it is older than this (0 disables
+	// age-based rotation).
+	MaxAge time.Duration
+	// MaxBackups is how many rotated files are kept; older ones are
+	// deleted (0 keeps all).
+	MaxBackups int
+	// Compress gzips rotated files in the background.
+	Compress bool
+}
+
+// FileSink writes entries as JSON Lines to a local file and rotates it by
+// size and age, so air-gapped hosts can log through the same API and ship
+// the files later. A file app.jsonl is rotated to
+// app-20240501T120000.000.jsonl (plus .gz when compressed).
+//
+// Like WriterSink it is both a Logger, writing entries as given, and a
+// Transport for NewClient(url, WithTransport(sink)).
+type FileSink struct {
+	path string
+	cfg  FileSinkConfig
+	now  func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+	closed bool
+
+	bg sync.WaitGroup
+	// bgMu serializes compression and pruning of rotated files.
+	bgMu sync.Mutex
+}
+
+var (
+	_ Logger    = (*FileSink)(nil)
+	_ Transport = (*FileSink)(nil)
+)
+
+// OpenFileSink opens (or creates) the log file at path, appending to
+// existing content.
+func OpenFileSink(path string, cfg FileSinkConfig) (*FileSink, error) {
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = DefaultFileMaxBytes
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("file sink: %w", err)
+	}
+	s := &FileSink{path: path, cfg: cfg, now: time.Now}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Log writes entry as one JSON line.
+func (s *FileSink) Log(entry LogEntry) error {
+	return s.Send(context.Background(), []LogEntry{entry})
+}
+
+// Send writes entries as JSON lines, rotating first if needed. A batch is
+// never split across files. Entries without a Timestamp are stamped with
+// the current time.
+func (s *FileSink) Send(_ context.Context, entries []LogEntry) error {
+	var buf []byte
+	for _, entry := range entries {
+		data, err := json.Marshal(stamp(entry, time.Now))
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+		buf = append(append(buf, data...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if s.due(int64(len(buf))) {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(buf)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("file sink: %w", err)
+	}
+	return nil
+}
+
+// Rotate closes the current file, renames it with a timestamp and starts a
+// new one, e.g. from a SIGHUP handler or before shipping files.
+func (s *FileSink) Rotate() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	return s.rotate()
+}
+
+// Close closes the file and waits for background compression to finish.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	err := s.file.Close()
+	s.mu.Unlock()
+
+	s.bg.Wait()
+	return err
+}
+
+// due reports whether the file must be rotated before writing n bytes.
+// Callers hold s.mu.
+func (s *FileSink) due(n int64) bool {
+	if s.size == 0 {
+		return false
+	}
+	if s.size+n > s.cfg.MaxBytes {
+		return true
+	}
+	return s.cfg.MaxAge > 0 && s.now().Sub(s.opened) >= s.cfg.MaxAge
+}
+
+// open opens s.path for appending. Callers hold s.mu or own s exclusively.
+func (s *FileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("file sink: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("file sink: %w", err)
+	}
+	s.file, s.size, s.opened = f, info.Size(), s.now()
+	if s.size > 0 {
+		// Age an existing file from its last write, the best estimate
+		// available.
+		s.opened = info.ModTime()
+	}
+	return nil
+}
+
+// rotate moves the current file aside and opens a fresh one. Callers hold
+// s.mu.
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("file sink: %w", err)
+	}
+	rotated := s.rotatedName(s.now())
+	if err := os.Rename(s.path, rotated); err != nil {
+		return fmt.Errorf("file sink: %w", err)
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	s.bg.Add(1)
+	go func() {
+		defer s.bg.Done()
+		s.bgMu.Lock()
+		defer s.bgMu.Unlock()
+		if s.cfg.Compress {
+			// A failed compression leaves the plain file in place.
+			gzipFile(rotated)
+		}
+		s.prune()
+	}()
+	return nil
+}
+
+// rotatedName returns an unused backup name for time t, moving t forward
+// by a millisecond while a backup with that stamp exists.
+func (s *FileSink) rotatedName(t time.Time) string {
+	ext := filepath.Ext(s.path)
+	base := strings.TrimSuffix(s.path, ext)
+	for {
+		name := fmt.Sprintf("%s-%s%s", base, t.UTC().Format(rotateLayout), ext)
+		if !exists(name) && !exists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Backups lists rotated files, oldest first.
+func (s *FileSink) Backups() ([]string, error) {
+	ext := filepath.Ext(s.path)
+	prefix := strings.TrimSuffix(s.path, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*")
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(m, prefix), ".gz"), ext)
+		if _, err := time.Parse(rotateLayout, stamp); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	slices.Sort(backups)
+	return backups, nil
+}
+
+// prune deletes the oldest backups beyond MaxBackups.
+func (s *FileSink) prune() {
+	if s.cfg.MaxBackups <= 0 {
+		return
+	}
+	backups, err := s.Backups()
+	if err != nil || len(backups) <= s.cfg.MaxBackups {
+		return
+	}
+	for _, old := range backups[:len(backups)-s.cfg.MaxBackups] {
+		os.Remove(old)
+	}
+}
+
+// gzipFile replaces path with path.gz.
+func gzipFile(path string) (err error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(path + ".gz")
+		}
+	}()
+	zw := gzip.NewWriter(out)
+	if _, err = io.Copy(zw, in); err != nil {
+		return err
+	}
+	if err = errors.Join(zw.Close(), out.Close()); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,122 @@
+package nfo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	}
+	var lines []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines
+}
+
+func TestFileSinkRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.jsonl")
+	sink, err := OpenFileSink(path, FileSinkConfig{MaxBytes: 150, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 8; i++ {
+		if err := sink.Log(LogEntry{Cmd: "entry-with-some-padding"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := sink.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected MaxBackups=2 files, got %v", backups)
+	}
+	for _, b := range append(backups, path) {
+		lines := readLines(t, b)
+		if len(lines) == 0 {
+			t.Fatalf("%s is empty", b)
+		}
+		var entry LogEntry
+		if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil || entry.Cmd != "entry-with-some-padding" {
+			t.Fatalf("%s: bad line %q: %v", b, lines[0], err)
+		}
+		if info, _ := os.Stat(b); info.Size() > 150 {
+			t.Fatalf("%s exceeds MaxBytes: %d", b, info.Size())
+		}
+	}
+	if err := sink.Log(LogEntry{}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
+
+func TestFileSinkRotatesByAgeAndCompresses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.jsonl")
+	sink, err := OpenFileSink(path, FileSinkConfig{MaxAge: time.Hour, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sink.now = func() time.Time { return now }
+	sink.opened = now
+
+	sink.Log(LogEntry{Cmd: "old"})
+	now = now.Add(time.Hour)
+	sink.Log(LogEntry{Cmd: "new"})
+	sink.Close()
+
+	backups, _ := sink.Backups()
+	want := filepath.Join(filepath.Dir(path), "app-20240501T130000.000.jsonl.gz")
+	if len(backups) != 1 || backups[0] != want {
+		t.Fatalf("backups = %v, want [%s]", backups, want)
+	}
+	if lines := readLines(t, backups[0]); len(lines) != 1 || !strings.Contains(lines[0], `"old"`) {
+		t.Fatalf("rotated content = %q", lines)
+	}
+	if lines := readLines(t, path); len(lines) != 1 || !strings.Contains(lines[0], `"new"`) {
+		t.Fatalf("current content = %q", lines)
+	}
+}
+
+func TestFileSinkAsTransport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.jsonl")
+	sink, err := OpenFileSink(path, FileSinkConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient("", WithTransport(sink), WithEnv("airgap"))
+	client.Log(LogEntry{Cmd: "a"})
+	sink.Close()
+
+	var entry LogEntry
+	json.Unmarshal([]byte(readLines(t, path)[0]), &entry)
+	if entry.Env != "airgap" || entry.Meta == nil {
+		t.Fatalf("entry not prepared by the client: %+v", entry)
+	}
+}
@@ -0,0 +1,178 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUnhealthy is returned by Ping when nfo-service answers but reports a
+// status other than "ok".
+var ErrUnhealthy = errors.New("nfo: service unhealthy")
+
+// Health is the answer of nfo-service's GET /health.
+type Health struct {
+	// Status is "ok" when the service is ready to accept entries.
+	Status string `json:"status"`
+	// Version is the service version, if it reports one.
+	Version string `json:"version,omitempty"`
+	// Latency is the round-trip time of the probe.
+	Latency time.Duration `json:"-"`
+	// Endpoint is the base URL that answered.
+	Endpoint string `json:"-"`
+}
+
+// Ping calls GET /health once, without retries or the circuit breaker, and
+// reports the service's status, version and latency. It fails with
+// ErrUnhealthy if the service answers with a status other than "ok". Ping
+// always uses HTTP, even when a Transport is configured.
+func (c *NfoClient) Ping(ctx context.Context) (Health, error) {
+	base := c.baseURL(ctx)
+	start := time.Now()
+	data, err := c.send(ctx, base, http.MethodGet, "/health", "", nil, "")
+	if c.failover != nil {
+		c.failover.record(base, err)
+	}
+	h := Health{Latency: time.Since(start), Endpoint: base}
+	if err != nil {
+		return h, err
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return h, fmt.Errorf("nfo: invalid health response: %w", err)
+	}
+	if h.Status != "ok" {
+		return h, fmt.Errorf("%w: status %q", ErrUnhealthy, h.Status)
+	}
+	return h, nil
+}
+
+// Healthy reports whether Ping succeeds.
+func (c *NfoClient) Healthy(ctx context.Context) bool {
+	_, err := c.Ping(ctx)
+	return err == nil
+}
+
+// HealthConfig configures MonitorHealth.
+type HealthConfig struct {
+	// Interval is the time between probes (default 15s).
+	Interval time.Duration
+	// Timeout bounds each probe (default 2s).
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failed probes after
+	// which the service counts as unhealthy (default 1).
+	FailureThreshold int
+	// OnChange, if set, is called from the monitor's goroutine whenever
+	// the service turns healthy or unhealthy; err is nil when healthy.
+	OnChange func(h Health, err error)
+}
+
+// HealthMonitor probes nfo-service in the background; see MonitorHealth.
+type HealthMonitor struct {
+	client *NfoClient
+	cfg    HealthConfig
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+
+	mu       sync.Mutex
+	last     Health
+	err      error
+	failures int
+}
+
+// MonitorHealth probes the service every cfg.Interval until Stop is
+// called, so an application can report "logging backend unreachable" in
+// its own readiness check:
+//
+//	mon := client.MonitorHealth(nfo.HealthConfig{})
+//	defer mon.Stop()
+//	if err := mon.Err(); err != nil { ... }
+//
+// The first probe runs before MonitorHealth returns.
+func (c *NfoClient) MonitorHealth(cfg HealthConfig) *HealthMonitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 15 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 1
+	}
+	m := &HealthMonitor{
+		client: c,
+		cfg:    cfg,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	m.check()
+	go m.run()
+	return m
+}
+
+func (m *HealthMonitor) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+// check runs one probe and notifies OnChange on a transition.
+func (m *HealthMonitor) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.Timeout)
+	h, err := m.client.Ping(ctx)
+	cancel()
+
+	m.mu.Lock()
+	wasHealthy := m.err == nil
+	m.last = h
+	if err == nil {
+		m.failures, m.err = 0, nil
+	} else if m.failures++; m.failures >= m.cfg.FailureThreshold {
+		m.err = err
+	}
+	changed := wasHealthy != (m.err == nil)
+	reported := m.err
+	m.mu.Unlock()
+
+	if changed && m.cfg.OnChange != nil {
+		m.cfg.OnChange(h, reported)
+	}
+}
+
+// Healthy reports the outcome of the latest probes.
+func (m *HealthMonitor) Healthy() bool {
+	return m.Err() == nil
+}
+
+// Err returns nil while the service is healthy and the error of the last
+// failed probe otherwise.
+func (m *HealthMonitor) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// Last returns the result of the most recent probe.
+func (m *HealthMonitor) Last() Health {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+// Stop ends monitoring and waits for a running probe to finish.
+func (m *HealthMonitor) Stop() {
+	m.once.Do(func() { close(m.stop) })
+	<-m.done
+}
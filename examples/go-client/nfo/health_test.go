@@ -0,0 +1,94 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func healthServer(t *testing.T, status *atomic.Value) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch s := status.Load().(string); s {
+		case "down":
+			http.Error(w, "down", http.StatusServiceUnavailable)
+		default:
+			w.Write([]byte(`{"status": "` + s + `", "version": "1.4.0"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestPing(t *testing.T) {
+	var status atomic.Value
+	status.Store("ok")
+	srv := healthServer(t, &status)
+	client := NewClient(srv.URL, WithRetry(3, time.Millisecond))
+
+	h, err := client.Ping(context.Background())
+	if err != nil || h.Status != "ok" || h.Version != "1.4.0" || h.Latency <= 0 || h.Endpoint != srv.URL {
+		t.Fatalf("Ping = %+v, %v", h, err)
+	}
+
+	status.Store("degraded")
+	if _, err := client.Ping(context.Background()); !errors.Is(err, ErrUnhealthy) {
+		t.Fatalf("degraded: %v", err)
+	}
+	status.Store("down")
+	if client.Healthy(context.Background()) {
+		t.Fatal("Healthy while the service returns 503")
+	}
+}
+
+func TestMonitorHealth(t *testing.T) {
+	var status atomic.Value
+	status.Store("ok")
+	srv := healthServer(t, &status)
+
+	var mu sync.Mutex
+	var changes []bool
+	mon := NewClient(srv.URL).MonitorHealth(HealthConfig{
+		Interval:         5 * time.Millisecond,
+		FailureThreshold: 2,
+		OnChange: func(h Health, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, err == nil)
+		},
+	})
+	defer mon.Stop()
+	if !mon.Healthy() || mon.Last().Version != "1.4.0" {
+		t.Fatalf("first probe: healthy=%v last=%+v", mon.Healthy(), mon.Last())
+	}
+
+	waitFor := func(healthy bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for mon.Healthy() != healthy {
+			if time.Now().After(deadline) {
+				t.Fatalf("monitor never became healthy=%v", healthy)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	status.Store("down")
+	waitFor(false)
+	if mon.Err() == nil {
+		t.Fatal("Err is nil while unhealthy")
+	}
+	status.Store("ok")
+	waitFor(true)
+
+	mon.Stop()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 2 || changes[0] || !changes[1] {
+		t.Fatalf("OnChange calls = %v, want [false true]", changes)
+	}
+}
@@ -0,0 +1,63 @@
+package nfo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// Hook inspects or rewrites an entry before it is sent. Returning false
+// drops the entry silently; returning an error drops it and makes the
+// logging call fail with that error.
+//
+// Hooks run when an entry is logged, in the order they were added and
+// before level filtering, sampling, rate limiting and redaction, so they
+// may set Level or add fields that are then scrubbed. For AsyncClient they
+// run on the caller's goroutine, with the caller's context.
+type Hook func(ctx context.Context, entry *LogEntry) (keep bool, err error)
+
+// WithHook appends h to the client's hook chain. It is the extension point
+// for enrichment, scrubbing, routing or drop rules.
+//
+//	nfo.WithHook(func(ctx context.Context, e *nfo.LogEntry) (bool, error) {
+//	    if e.Cmd == "healthcheck" {
+//	        return false, nil
+//	    }
+//	    e.Fields["tenant"] = tenantFrom(ctx)
+//	    return true, nil
+//	})
+func WithHook(h Hook) Option {
+	return func(cfg *clientConfig) {
+		if h != nil {
+			cfg.client.hooks = append(cfg.client.hooks, h)
+		}
+	}
+}
+
+// runHooks passes entry through every hook. Args and Fields are copied
+// first so hooks can modify them without touching the caller's values;
+// Fields is never nil inside a hook.
+func (c *NfoClient) runHooks(ctx context.Context, entry LogEntry) (LogEntry, bool, error) {
+	if len(c.hooks) == 0 {
+		return entry, true, nil
+	}
+	entry.Args = slices.Clone(entry.Args)
+	entry.Fields = maps.Clone(entry.Fields)
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]any)
+	}
+	for _, h := range c.hooks {
+		keep, err := h(ctx, &entry)
+		if err != nil {
+			return entry, false, fmt.Errorf("hook: %w", err)
+		}
+		if !keep {
+			return entry, false, nil
+		}
+	}
+	if len(entry.Fields) == 0 {
+		entry.Fields = nil
+	}
+	return entry, true, nil
+}
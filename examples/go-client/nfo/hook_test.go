@@ -0,0 +1,81 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestHooksRunInOrder(t *testing.T) {
+	rec, srv := newRecorder(t)
+	var order []string
+	client := NewClient(srv.URL,
+		WithHook(func(ctx context.Context, e *LogEntry) (bool, error) {
+			order = append(order, "first")
+			e.Fields["tenant"] = ctx.Value(tenantKey{})
+			return e.Cmd != "healthcheck", nil
+		}),
+		WithHook(func(_ context.Context, e *LogEntry) (bool, error) {
+			order = append(order, "second")
+			e.Level = LevelWarn
+			return true, nil
+		}),
+	)
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	fields := map[string]any{"k": "v"}
+	if err := client.LogContext(ctx, LogEntry{Cmd: "deploy", Fields: fields}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Log(LogEntry{Cmd: "healthcheck"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got := rec.Entries()
+	if len(got) != 1 || got[0].Fields["tenant"] != "acme" || got[0].Level != LevelWarn {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+	if len(order) != 3 || order[0] != "first" || order[1] != "second" || order[2] != "first" {
+		t.Fatalf("hook order = %v", order)
+	}
+	if _, ok := fields["tenant"]; ok {
+		t.Fatal("hooks must not modify the caller's fields")
+	}
+}
+
+func TestHookError(t *testing.T) {
+	rec, srv := newRecorder(t)
+	boom := errors.New("boom")
+	client := NewClient(srv.URL, WithHook(func(context.Context, *LogEntry) (bool, error) {
+		return true, boom
+	}))
+
+	if err := client.Log(LogEntry{Cmd: "x"}); !errors.Is(err, boom) {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+	if err := client.LogBatch([]LogEntry{{Cmd: "a"}, {Cmd: "b"}}); !errors.Is(err, boom) {
+		t.Fatalf("expected hook error from batch, got %v", err)
+	}
+	if n := len(rec.Entries()); n != 0 {
+		t.Fatalf("expected nothing sent, got %d entries", n)
+	}
+}
+
+func TestHookBeforeRedaction(t *testing.T) {
+	_, srv := newRecorder(t)
+	client := NewClient(srv.URL,
+		WithRedaction(RedactConfig{}),
+		WithHook(func(_ context.Context, e *LogEntry) (bool, error) {
+			e.Output = "token=abc"
+			return true, nil
+		}),
+	)
+	async := newAsyncClient(client, AsyncConfig{})
+	async.Log(LogEntry{Cmd: "x"})
+
+	if got := async.queue[0].Output; got != "token=[REDACTED]" {
+		t.Fatalf("output = %q", got)
+	}
+}
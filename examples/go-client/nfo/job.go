@@ -0,0 +1,264 @@
+package nfo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// JobConfig tunes NewJobWith and RunJobWith.
+type JobConfig struct {
+	// Heartbeat is the interval at which a running job logs a heartbeat
+	// entry, so a job that hangs or is killed shows up as one whose
+	// heartbeats stopped. Zero disables heartbeats.
+	Heartbeat time.Duration
+	// Expected is how long the job should take. A job still running after
+	// Expected logs a warning entry right away, and its completion entry
+	// is marked overdue. Zero disables the check.
+	Expected time.Duration
+	// Args and Fields are added to every entry of the job.
+	Args   []string
+	Fields map[string]any
+}
+
+// Job events, recorded in Fields["job_event"] of every job entry.
+const (
+	JobStarted   = "start"
+	JobHeartbeat = "heartbeat"
+	JobOverdue   = "overdue"
+	JobFinished  = "finish"
+)
+
+// Job instruments one run of a cron or other periodic job:
+//
+//	job := client.NewJobWith("nightly-backup", nfo.JobConfig{
+//	    Heartbeat: time.Minute,
+//	    Expected:  30 * time.Minute,
+//	})
+//	job.Start()
+//	err := backup()
+//	job.Finish(err)
+//
+// All entries of a run share Cmd (the job name) and a correlation ID, see
+// ID, so one run can be queried as a whole. They carry Fields["job_event"]
+// set to JobStarted, JobHeartbeat, JobOverdue or JobFinished and, except
+// for the start entry, the time elapsed so far in DurationMs.
+//
+// A Job is safe for concurrent use; Finish may be called from another
+// goroutine than Start.
+type Job struct {
+	logger Logger
+	name   string
+	cfg    JobConfig
+	ctx    context.Context
+	id     string
+
+	// logMu serialises the job's entries, so that none is logged after
+	// the finish entry.
+	logMu sync.Mutex
+
+	mu       sync.Mutex
+	started  time.Time
+	overdue  bool
+	finished bool
+	stop     chan struct{}
+	timer    *time.Timer
+}
+
+// NewJob returns a Job named name that logs through c.
+func (c *NfoClient) NewJob(name string) *Job {
+	return newJob(context.Background(), c, name, JobConfig{})
+}
+
+// NewJobWith is NewJob with heartbeats and an expected duration.
+func (c *NfoClient) NewJobWith(name string, cfg JobConfig) *Job {
+	return newJob(context.Background(), c, name, cfg)
+}
+
+// RunJob runs fn as a Job named name: it logs the start, fn's outcome and
+// duration, and returns fn's error unchanged. fn receives ctx with the
+// job's correlation ID. A panic in fn is logged as a failed run and then
+// re-raised.
+func (c *NfoClient) RunJob(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return runJob(ctx, c, name, JobConfig{}, fn)
+}
+
+// RunJobWith is RunJob with heartbeats and an expected duration.
+func (c *NfoClient) RunJobWith(ctx context.Context, name string, cfg JobConfig, fn func(ctx context.Context) error) error {
+	return runJob(ctx, c, name, cfg, fn)
+}
+
+// NewJob returns a Job named name whose entries are enqueued on a.
+func (a *AsyncClient) NewJob(name string) *Job {
+	return newJob(context.Background(), a, name, JobConfig{})
+}
+
+// NewJobWith is NewJob with heartbeats and an expected duration.
+func (a *AsyncClient) NewJobWith(name string, cfg JobConfig) *Job {
+	return newJob(context.Background(), a, name, cfg)
+}
+
+// RunJob runs fn as a Job named name; see NfoClient.RunJob.
+func (a *AsyncClient) RunJob(ctx context.Context, name string, fn func(ctx context.Context) error) error {
+	return runJob(ctx, a, name, JobConfig{}, fn)
+}
+
+// RunJobWith is RunJob with heartbeats and an expected duration.
+func (a *AsyncClient) RunJobWith(ctx context.Context, name string, cfg JobConfig, fn func(ctx context.Context) error) error {
+	return runJob(ctx, a, name, cfg, fn)
+}
+
+// newJob creates a Job whose entries use the correlation ID of ctx, or a
+// new one.
+func newJob(ctx context.Context, logger Logger, name string, cfg JobConfig) *Job {
+	id, ok := CorrelationID(ctx)
+	if !ok {
+		ctx = WithCorrelationID(ctx, "")
+		id, _ = CorrelationID(ctx)
+	}
+	return &Job{logger: logger, name: name, cfg: cfg, ctx: context.WithoutCancel(ctx), id: id}
+}
+
+func runJob(ctx context.Context, logger Logger, name string, cfg JobConfig, fn func(ctx context.Context) error) error {
+	job := newJob(ctx, logger, name, cfg)
+	job.Start()
+	defer func() {
+		if r := recover(); r != nil {
+			job.Finish(fmt.Errorf("panic: %v", r))
+			panic(r)
+		}
+	}()
+	err := fn(WithCorrelationID(ctx, job.id))
+	job.Finish(err)
+	return err
+}
+
+// ID returns the correlation ID shared by the job's entries.
+func (j *Job) ID() string { return j.id }
+
+// Start logs the start entry and begins heartbeats and the overdue check.
+// Calling it again has no effect.
+func (j *Job) Start() error {
+	j.logMu.Lock()
+	defer j.logMu.Unlock()
+	j.mu.Lock()
+	if !j.started.IsZero() || j.finished {
+		j.mu.Unlock()
+		return nil
+	}
+	j.started = time.Now()
+	if j.cfg.Heartbeat > 0 {
+		j.stop = make(chan struct{})
+		go j.heartbeat(j.stop)
+	}
+	if j.cfg.Expected > 0 {
+		j.timer = time.AfterFunc(j.cfg.Expected, j.markOverdue)
+	}
+	j.mu.Unlock()
+	return j.log(j.entry(JobStarted, LevelInfo, 0))
+}
+
+// Finish logs the completion entry: failed with err's message if err is
+// non-nil, and at LevelWarn with Fields["overdue"] set if the job ran
+// longer than Expected. A Job finished without Start is logged with zero
+// duration. Only the first call logs.
+func (j *Job) Finish(err error) error {
+	j.logMu.Lock()
+	defer j.logMu.Unlock()
+	j.mu.Lock()
+	if j.finished {
+		j.mu.Unlock()
+		return nil
+	}
+	j.finished = true
+	if j.started.IsZero() {
+		j.started = time.Now()
+	}
+	if j.stop != nil {
+		close(j.stop)
+	}
+	if j.timer != nil {
+		j.timer.Stop()
+	}
+	elapsed := time.Since(j.started)
+	overdue := j.overdue || (j.cfg.Expected > 0 && elapsed > j.cfg.Expected)
+	j.mu.Unlock()
+
+	level := LevelInfo
+	if overdue {
+		level = LevelWarn
+	}
+	if err != nil {
+		level = LevelError
+	}
+	entry := j.entry(JobFinished, level, elapsed)
+	success := err == nil
+	entry.Success = &success
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if overdue {
+		entry.Fields["overdue"] = true
+	}
+	return j.log(entry)
+}
+
+// heartbeat logs a heartbeat entry every cfg.Heartbeat until stop closes.
+func (j *Job) heartbeat(stop <-chan struct{}) {
+	ticker := time.NewTicker(j.cfg.Heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			j.logMu.Lock()
+			if elapsed, ok := j.running(); ok {
+				j.log(j.entry(JobHeartbeat, LevelInfo, elapsed))
+			}
+			j.logMu.Unlock()
+		}
+	}
+}
+
+// markOverdue logs the overdue warning if the job is still running.
+func (j *Job) markOverdue() {
+	j.logMu.Lock()
+	defer j.logMu.Unlock()
+	j.mu.Lock()
+	if j.finished {
+		j.mu.Unlock()
+		return
+	}
+	j.overdue = true
+	elapsed := time.Since(j.started)
+	j.mu.Unlock()
+	j.log(j.entry(JobOverdue, LevelWarn, elapsed))
+}
+
+// running reports the time elapsed since Start, or false once the job has
+// finished.
+func (j *Job) running() (time.Duration, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return time.Since(j.started), !j.finished
+}
+
+// entry builds a job entry for event; elapsed is omitted for JobStarted.
+func (j *Job) entry(event string, level Level, elapsed time.Duration) LogEntry {
+	fields := mergeFields(map[string]any{"job_event": event}, j.cfg.Fields)
+	if j.cfg.Expected > 0 {
+		fields["expected_ms"] = j.cfg.Expected.Milliseconds()
+	}
+	entry := LogEntry{Cmd: j.name, Args: j.cfg.Args, Level: level, Fields: fields}
+	if event != JobStarted {
+		ms := float64(elapsed.Milliseconds())
+		entry.DurationMs = &ms
+	}
+	return entry
+}
+
+func (j *Job) log(entry LogEntry) error {
+	return logContext(j.ctx, j.logger, entry)
+}
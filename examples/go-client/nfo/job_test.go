@@ -0,0 +1,127 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestJob(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	job := client.NewJobWith("nightly-backup", JobConfig{
+		Heartbeat: 10 * time.Millisecond,
+		Expected:  25 * time.Millisecond,
+		Fields:    map[string]any{"target": "s3"},
+	})
+	job.Start()
+	time.Sleep(60 * time.Millisecond)
+	job.Finish(errors.New("disk full"))
+	job.Finish(nil)
+
+	got := rec.Entries()
+	events := map[any]int{}
+	for _, e := range got {
+		events[e.Fields["job_event"]]++
+		if e.Cmd != "nightly-backup" || e.CorrelationID != job.ID() || e.Fields["target"] != "s3" {
+			t.Fatalf("unexpected job entry: %+v", e)
+		}
+	}
+	if got[0].Fields["job_event"] != JobStarted || got[0].DurationMs != nil {
+		t.Fatalf("first entry = %+v", got[0])
+	}
+	if events[JobHeartbeat] == 0 || events[JobOverdue] != 1 || events[JobFinished] != 1 {
+		t.Fatalf("events = %v", events)
+	}
+	last := got[len(got)-1]
+	if last.Fields["job_event"] != JobFinished || *last.Success || last.Error != "disk full" || last.Level != LevelError ||
+		last.Fields["overdue"] != true || *last.DurationMs < 60 {
+		t.Fatalf("finish entry = %+v", last)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if n := len(rec.Entries()); n != len(got) {
+		t.Fatalf("%d entries logged after Finish", n-len(got))
+	}
+}
+
+func TestRunJob(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	ctx := WithCorrelationID(context.Background(), "cron-1")
+	var inner string
+	err := client.RunJob(ctx, "rotate-keys", func(ctx context.Context) error {
+		inner, _ = CorrelationID(ctx)
+		return nil
+	})
+	if err != nil || inner != "cron-1" {
+		t.Fatalf("err=%v correlation=%q", err, inner)
+	}
+	got := rec.Entries()
+	if len(got) != 2 || got[1].CorrelationID != "cron-1" || !*got[1].Success || got[1].Level != LevelInfo || got[1].Fields["overdue"] != nil {
+		t.Fatalf("entries = %+v", got)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("recovered %v, want the original panic", r)
+			}
+		}()
+		client.RunJob(context.Background(), "explode", func(context.Context) error { panic("boom") })
+	}()
+	if got := rec.Entries(); len(got) != 4 || got[3].Error != "panic: boom" || *got[3].Success {
+		t.Fatalf("panicking job not logged: %+v", got)
+	}
+}
+
+func TestRunJobWith(t *testing.T) {
+	rec, srv := newRecorder(t)
+	async := NewAsyncClient(NewClient(srv.URL), AsyncConfig{})
+	err := async.RunJobWith(context.Background(), "compact", JobConfig{Expected: time.Hour, Args: []string{"db"}},
+		func(context.Context) error { return nil })
+	async.Close()
+	got := rec.Entries()
+	if err != nil || len(got) != 2 || got[1].Args[0] != "db" || got[1].Fields["expected_ms"] != float64(time.Hour.Milliseconds()) {
+		t.Fatalf("err=%v entries=%+v", err, got)
+	}
+}
+
+// slowLogger records entries, taking a while over heartbeats.
+type slowLogger struct {
+	beating chan struct{}
+	once    sync.Once
+
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+func (l *slowLogger) Log(entry LogEntry) error {
+	if entry.Fields["job_event"] == JobHeartbeat {
+		l.once.Do(func() { close(l.beating) })
+		time.Sleep(20 * time.Millisecond)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func TestJobFinishIsLast(t *testing.T) {
+	l := &slowLogger{beating: make(chan struct{})}
+	job := newJob(context.Background(), l, "sync", JobConfig{Heartbeat: time.Millisecond})
+	job.Start()
+	<-l.beating // a heartbeat is being logged
+	job.Finish(nil)
+	time.Sleep(10 * time.Millisecond)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if last := l.entries[len(l.entries)-1]; last.Fields["job_event"] != JobFinished {
+		t.Fatalf("entry after finish: %+v", l.entries)
+	}
+}
@@ -0,0 +1,141 @@
+package nfo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of an entry. The zero value means unset: the
+// client then derives it from the outcome, LevelError for failures and
+// LevelInfo otherwise, and sends the result.
+type Level int
+
+const (
+	LevelDebug Level = iota + 1
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int(l))
+}
+
+// ParseLevel parses "debug", "info", "warn" (or "warning") and "error",
+// ignoring case.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return 0, fmt.Errorf("nfo: unknown level %q", s)
+}
+
+// MarshalText encodes l as its name.
+func (l Level) MarshalText() ([]byte, error) {
+	if l < LevelDebug || l > LevelError {
+		return nil, fmt.Errorf("nfo: invalid level %d", int(l))
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText decodes a level name as accepted by ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// WithMinLevel discards entries below min before they are queued or sent.
+// Entries without a Level are judged by their derived level.
+func WithMinLevel(min Level) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.minLevel = min
+	}
+}
+
+// levelOf returns entry's level, deriving it from the outcome if unset.
+func levelOf(entry LogEntry) Level {
+	if entry.Level != 0 {
+		return entry.Level
+	}
+	if failed(entry) {
+		return LevelError
+	}
+	return LevelInfo
+}
+
+// failed reports whether entry records a failure.
+func failed(entry LogEntry) bool {
+	return (entry.Success != nil && !*entry.Success) || entry.Error != ""
+}
+
+// levelEntry builds the entry logged by Debugf and friends. Error entries
+// are marked as failed.
+func levelEntry(level Level, format string, args []any) LogEntry {
+	entry := LogEntry{Cmd: fmt.Sprintf(format, args...), Level: level}
+	if level >= LevelError {
+		success := false
+		entry.Success = &success
+	}
+	return entry
+}
+
+// Debugf logs a formatted message at LevelDebug.
+func (c *NfoClient) Debugf(format string, args ...any) error {
+	return c.Log(levelEntry(LevelDebug, format, args))
+}
+
+// Infof logs a formatted message at LevelInfo.
+func (c *NfoClient) Infof(format string, args ...any) error {
+	return c.Log(levelEntry(LevelInfo, format, args))
+}
+
+// Warnf logs a formatted message at LevelWarn.
+func (c *NfoClient) Warnf(format string, args ...any) error {
+	return c.Log(levelEntry(LevelWarn, format, args))
+}
+
+// Errorf logs a formatted message at LevelError as a failed entry.
+func (c *NfoClient) Errorf(format string, args ...any) error {
+	return c.Log(levelEntry(LevelError, format, args))
+}
+
+// Debugf enqueues a formatted message at LevelDebug.
+func (a *AsyncClient) Debugf(format string, args ...any) error {
+	return a.Log(levelEntry(LevelDebug, format, args))
+}
+
+// Infof enqueues a formatted message at LevelInfo.
+func (a *AsyncClient) Infof(format string, args ...any) error {
+	return a.Log(levelEntry(LevelInfo, format, args))
+}
+
+// Warnf enqueues a formatted message at LevelWarn.
+func (a *AsyncClient) Warnf(format string, args ...any) error {
+	return a.Log(levelEntry(LevelWarn, format, args))
+}
+
+// Errorf enqueues a formatted message at LevelError as a failed entry.
+func (a *AsyncClient) Errorf(format string, args ...any) error {
+	return a.Log(levelEntry(LevelError, format, args))
+}
@@ -0,0 +1,74 @@
+package nfo
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLevelJSON(t *testing.T) {
+	data, err := json.Marshal(LogEntry{Cmd: "x", Level: LevelWarn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	json.Unmarshal(data, &doc)
+	if doc["level"] != "warn" {
+		t.Fatalf("level = %v in %s", doc["level"], data)
+	}
+
+	var entry LogEntry
+	if err := json.Unmarshal([]byte(`{"cmd":"x","level":"WARNING"}`), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Level != LevelWarn {
+		t.Fatalf("decoded level = %v", entry.Level)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+}
+
+func TestLevelDerived(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	ok := true
+	client.Log(LogEntry{Cmd: "ok", Success: &ok})
+	client.Log(LogEntry{Cmd: "failed", Error: "boom"})
+	client.Warnf("disk at %d%%", 91)
+	client.Errorf("lost %s", "leader")
+
+	got := rec.Entries()
+	want := []Level{LevelInfo, LevelError, LevelWarn, LevelError}
+	for i, level := range want {
+		if got[i].Level != level {
+			t.Errorf("entry %d (%s): level = %v, want %v", i, got[i].Cmd, got[i].Level, level)
+		}
+	}
+	if got[2].Cmd != "disk at 91%" {
+		t.Errorf("cmd = %q", got[2].Cmd)
+	}
+	if got[3].Success == nil || *got[3].Success {
+		t.Error("Errorf must mark the entry as failed")
+	}
+}
+
+func TestWithMinLevel(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithMinLevel(LevelWarn))
+
+	client.Debugf("noise")
+	client.Infof("chatter")
+	client.Log(LogEntry{Cmd: "failed", Error: "boom"})
+	client.LogBatch([]LogEntry{{Cmd: "a"}, {Cmd: "b", Level: LevelWarn}})
+
+	if got := rec.Entries(); len(got) != 2 || got[0].Cmd != "failed" || got[1].Cmd != "b" {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+
+	async := newAsyncClient(client, AsyncConfig{})
+	async.Debugf("noise")
+	if async.Len() != 0 {
+		t.Fatal("filtered entries must not be queued")
+	}
+}
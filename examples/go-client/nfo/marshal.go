@@ -0,0 +1,331 @@
+package nfo
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// bufPool holds scratch buffers for encoding entries, so that marshalling
+// allocates only the returned slice.
+var bufPool = sync.Pool{New: func() any { b := make([]byte, 0, 1024); return &b }}
+
+// maxPooledBuf keeps unusually large buffers out of bufPool.
+const maxPooledBuf = 64 << 10
+
+func getBuf() *[]byte { return bufPool.Get().(*[]byte) }
+
+func putBuf(b *[]byte) {
+	if cap(*b) > maxPooledBuf {
+		return
+	}
+	*b = (*b)[:0]
+	bufPool.Put(b)
+}
+
+// marshalEntry encodes entry as JSON, byte for byte as json.Marshal would,
+// without reflection. Field values of types other than strings, numbers,
+// booleans and nil are delegated to json.Marshal.
+func marshalEntry(entry *LogEntry) ([]byte, error) {
+	buf := getBuf()
+	defer putBuf(buf)
+	var err error
+	if *buf, err = appendEntry(*buf, entry); err != nil {
+		return nil, err
+	}
+	return slices.Clone(*buf), nil
+}
+
+// appendEntry appends the JSON encoding of entry to dst. Keys follow the
+// field order of LogEntry and honour its omitempty tags.
+func appendEntry(dst []byte, e *LogEntry) ([]byte, error) {
+	var err error
+	dst = append(dst, `{"cmd":`...)
+	dst = appendJSONString(dst, e.Cmd)
+	dst = append(dst, `,"args":`...)
+	dst = appendStrings(dst, e.Args)
+	dst = append(dst, `,"language":`...)
+	dst = appendJSONString(dst, e.Language)
+	dst = append(dst, `,"env":`...)
+	dst = appendJSONString(dst, e.Env)
+	if e.Success != nil {
+		dst = append(dst, `,"success":`...)
+		dst = strconv.AppendBool(dst, *e.Success)
+	}
+	if e.DurationMs != nil {
+		dst = append(dst, `,"duration_ms":`...)
+		if dst, err = appendFloat(dst, *e.DurationMs, 64); err != nil {
+			return nil, err
+		}
+	}
+	dst = appendStringField(dst, "output", e.Output)
+	dst = appendStringField(dst, "error", e.Error)
+	if e.Level != 0 {
+		if e.Level < LevelDebug || e.Level > LevelError {
+			_, err := e.Level.MarshalText()
+			return nil, &json.MarshalerError{Type: reflect.TypeFor[Level](), Err: err}
+		}
+		dst = append(dst, `,"level":`...)
+		dst = appendJSONString(dst, e.Level.String())
+	}
+	if e.Timestamp != nil {
+		dst = append(dst, `,"timestamp":`...)
+		if dst, err = appendTime(dst, *e.Timestamp); err != nil {
+			return nil, err
+		}
+	}
+	if e.ClockSkewMs != nil {
+		dst = append(dst, `,"clock_skew_ms":`...)
+		if dst, err = appendFloat(dst, *e.ClockSkewMs, 64); err != nil {
+			return nil, err
+		}
+	}
+	dst = appendStringField(dst, "trace_id", e.TraceID)
+	dst = appendStringField(dst, "span_id", e.SpanID)
+	dst = appendStringField(dst, "correlation_id", e.CorrelationID)
+	if e.TruncatedBytes != 0 {
+		dst = append(dst, `,"truncated_bytes":`...)
+		dst = strconv.AppendInt(dst, int64(e.TruncatedBytes), 10)
+	}
+	if len(e.Attachments) > 0 {
+		dst = append(dst, `,"attachments":`...)
+		dst = appendStringMap(dst, e.Attachments)
+	}
+	dst = appendStringField(dst, "fingerprint", e.Fingerprint)
+	if e.RepeatCount != 0 {
+		dst = append(dst, `,"repeat_count":`...)
+		dst = strconv.AppendInt(dst, int64(e.RepeatCount), 10)
+	}
+	dst = appendStringField(dst, "id", e.ID)
+	if len(e.Fields) > 0 {
+		dst = append(dst, `,"fields":`...)
+		if dst, err = appendFields(dst, e.Fields); err != nil {
+			return nil, err
+		}
+	}
+	if e.Meta != nil {
+		dst = append(dst, `,"meta":`...)
+		dst = appendMetadata(dst, e.Meta)
+	}
+	return append(dst, '}'), nil
+}
+
+// appendMetadata appends the JSON encoding of m.
+func appendMetadata(dst []byte, m *Metadata) []byte {
+	start := len(dst)
+	dst = appendStringField(dst, "hostname", m.Hostname)
+	if m.PID != 0 {
+		dst = append(dst, `,"pid":`...)
+		dst = strconv.AppendInt(dst, int64(m.PID), 10)
+	}
+	dst = appendStringField(dst, "go_version", m.GoVersion)
+	dst = appendStringField(dst, "binary", m.Binary)
+	dst = appendStringField(dst, "os", m.OS)
+	dst = appendStringField(dst, "arch", m.Arch)
+	dst = appendStringField(dst, "container_id", m.ContainerID)
+	dst = appendStringField(dst, "pod_name", m.PodName)
+	dst = appendStringField(dst, "pod_namespace", m.PodNamespace)
+	dst = appendStringField(dst, "node_name", m.NodeName)
+	if len(dst) == start {
+		return append(dst, "{}"...)
+	}
+	dst[start] = '{' // replaces the comma before the first field
+	return append(dst, '}')
+}
+
+// appendStringField appends ,"key":value unless value is empty.
+func appendStringField(dst []byte, key, value string) []byte {
+	if value == "" {
+		return dst
+	}
+	dst = append(dst, ',', '"')
+	dst = append(dst, key...)
+	dst = append(dst, '"', ':')
+	return appendJSONString(dst, value)
+}
+
+func appendStrings(dst []byte, values []string) []byte {
+	if values == nil {
+		return append(dst, "null"...)
+	}
+	dst = append(dst, '[')
+	for i, v := range values {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, v)
+	}
+	return append(dst, ']')
+}
+
+func appendStringMap(dst []byte, m map[string]string) []byte {
+	var scratch [16]string
+	dst = append(dst, '{')
+	for i, k := range sortedMapKeys(scratch[:0], m) {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, k)
+		dst = append(dst, ':')
+		dst = appendJSONString(dst, m[k])
+	}
+	return append(dst, '}')
+}
+
+func appendFields(dst []byte, fields map[string]any) ([]byte, error) {
+	var scratch [16]string
+	var err error
+	dst = append(dst, '{')
+	for i, k := range sortedMapKeys(scratch[:0], fields) {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, k)
+		dst = append(dst, ':')
+		if dst, err = appendValue(dst, fields[k]); err != nil {
+			return nil, err
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+// sortedMapKeys appends the keys of m to keys in the order json.Marshal
+// writes them.
+func sortedMapKeys[V any](keys []string, m map[string]V) []string {
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// appendValue appends the JSON encoding of a field value.
+func appendValue(dst []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(dst, "null"...), nil
+	case string:
+		return appendJSONString(dst, v), nil
+	case bool:
+		return strconv.AppendBool(dst, v), nil
+	case float64:
+		return appendFloat(dst, v, 64)
+	case float32:
+		return appendFloat(dst, float64(v), 32)
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10), nil
+	case int64:
+		return strconv.AppendInt(dst, v, 10), nil
+	case int32:
+		return strconv.AppendInt(dst, int64(v), 10), nil
+	case uint:
+		return strconv.AppendUint(dst, uint64(v), 10), nil
+	case uint64:
+		return strconv.AppendUint(dst, v, 10), nil
+	case uint32:
+		return strconv.AppendUint(dst, uint64(v), 10), nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(dst, data...), nil
+}
+
+// appendFloat formats f like encoding/json: the shortest representation,
+// in exponent form only for very large or small magnitudes.
+func appendFloat(dst []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, &json.UnsupportedValueError{Value: reflect.ValueOf(f), Str: strconv.FormatFloat(f, 'g', -1, bits)}
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+	if format == 'e' {
+		// Shorten e-09 to e-9, as encoding/json does.
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst, nil
+}
+
+// appendTime formats t like time.Time.MarshalJSON.
+func appendTime(dst []byte, t time.Time) ([]byte, error) {
+	if y := t.Year(); y < 0 || y > 9999 {
+		data, err := t.MarshalJSON()
+		if err != nil {
+			return nil, &json.MarshalerError{Type: reflect.TypeFor[time.Time](), Err: err}
+		}
+		return append(dst, data...), nil
+	}
+	dst = append(dst, '"')
+	dst = t.AppendFormat(dst, time.RFC3339Nano)
+	return append(dst, '"'), nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s as a JSON string, escaped like encoding/json with
+// HTML escaping: invalid UTF-8 becomes U+FFFD and <, >, &, U+2028 and
+// U+2029 are written as \u escapes.
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' && b != '<' && b != '>' && b != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				dst = append(dst, '\\', b)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
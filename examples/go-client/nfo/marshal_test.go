@@ -0,0 +1,145 @@
+package nfo
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// benchEntry is a typical entry as prepared for sending.
+func benchEntry() LogEntry {
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	ok, ms := true, 42.0
+	return LogEntry{
+		Cmd: "deploy", Args: []string{"--env", "prod", "api"}, Language: "go", Env: "prod",
+		Success: &ok, DurationMs: &ms, Output: "deployed 3 replicas", Level: LevelInfo, Timestamp: &ts,
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", CorrelationID: "01HX3J6Y0Q",
+		Fields: map[string]any{"user_id": "u-1", "attempt": 2, "latency": 0.25, "cached": false},
+		Meta:   &Metadata{Hostname: "web-1", PID: 4242, GoVersion: "go1.23.0", OS: "linux", Arch: "amd64"},
+	}
+}
+
+func TestMarshalEntryMatchesEncodingJSON(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("", -7*3600))
+	fail := false
+	cases := map[string]LogEntry{
+		"zero":    {},
+		"typical": benchEntry(),
+		"escaping": {
+			Cmd:    "<script>&\"quote\"\\ \x00\x1f\b\f\n\r\t",
+			Args:   []string{"", "  ", "\xff\xfeinvalid", "日本語 😀"},
+			Output: "line1\nline2",
+			Error:  "a < b && c > d",
+		},
+		"numbers": {
+			Cmd: "n", DurationMs: ptr(1e21), Success: &fail, Timestamp: &ts, ClockSkewMs: ptr(-2.5e-7),
+			TruncatedBytes: 7, RepeatCount: 3, ID: "01J0000000000000000000000",
+			Fields: map[string]any{
+				"tiny": 1e-7, "big": 1e21, "neg": -0.0, "f32": float32(3.14), "f32tiny": float32(1e-7),
+				"i": -5, "i64": int64(math.MaxInt64), "u": uint(7), "u64": uint64(math.MaxUint64),
+				"i32": int32(-1), "u32": uint32(1), "nil": nil, "bool": true,
+			},
+		},
+		"fallback": {
+			Cmd: "f",
+			Fields: map[string]any{
+				"list": []string{"a", "b"}, "nested": map[string]any{"z": 1, "a": []any{1.5, "x", nil}},
+				"time": ts, "level": LevelWarn, "err": errors.New("boom"), "raw": json.RawMessage(`{ "k" : 1 }`),
+				"i8": int8(-8), "b": []byte("hi"),
+			},
+		},
+		"maps": {
+			Cmd:         "m",
+			Attachments: map[string]string{"output": "att-1", "error": "att-2", "<": ">"},
+			Fingerprint: "abc",
+			Meta:        &Metadata{},
+		},
+		"skew":    {Cmd: "s", Timestamp: &ts, ClockSkewMs: ptr(-1500.25)},
+		"meta":    {Cmd: "m", Meta: &Metadata{PID: 1, ContainerID: "c", PodName: "p", PodNamespace: "ns", NodeName: "n"}},
+		"nilArgs": {Cmd: "x", Args: []string{}, Fields: map[string]any{}, Attachments: map[string]string{}},
+	}
+	for name, entry := range cases {
+		t.Run(name, func(t *testing.T) {
+			want, err := json.Marshal(entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := marshalEntry(&entry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Fatalf("got  %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+func TestMarshalEntryErrors(t *testing.T) {
+	nan := math.NaN()
+	for name, entry := range map[string]LogEntry{
+		"nan duration": {DurationMs: &nan},
+		"inf skew":     {ClockSkewMs: ptr(math.Inf(-1))},
+		"inf field":    {Fields: map[string]any{"x": math.Inf(1)}},
+		"bad level":    {Level: 9},
+		"bad field":    {Fields: map[string]any{"ch": make(chan int)}},
+		"bad time":     {Timestamp: ptr(time.Date(10000, 1, 1, 0, 0, 0, 0, time.UTC))},
+	} {
+		if _, err := json.Marshal(entry); err == nil {
+			t.Fatalf("%s: encoding/json accepted the entry", name)
+		}
+		if _, err := marshalEntry(&entry); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMarshalEntryAllocs(t *testing.T) {
+	entry := benchEntry()
+	marshalEntry(&entry) // warm the buffer pool
+	if allocs := testing.AllocsPerRun(100, func() { marshalEntry(&entry) }); allocs > 1 {
+		t.Fatalf("marshalEntry allocates %.0f times, want only the result", allocs)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func BenchmarkMarshal(b *testing.B) {
+	entry := benchEntry()
+	b.Run("encoding/json", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			json.Marshal(entry)
+		}
+	})
+	b.Run("JSONCodec", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			JSONCodec.Marshal(entry)
+		}
+	})
+	b.Run("flat", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			marshalFlatJSON(entry, "")
+		}
+	})
+	b.Run("msgpack", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			MsgpackCodec.Marshal(entry)
+		}
+	})
+}
+
+func BenchmarkMarshalParallel(b *testing.B) {
+	entry := benchEntry()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			JSONCodec.Marshal(entry)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package nfo
+
+import "time"
+
+// DropReason says why the client discarded an entry. It is passed to
+// Metrics.EntriesDropped and to the WithOnDrop callback.
+type DropReason string
+
+// Reasons for discarding entries.
+const (
+	// DropQueueFull counts entries rejected or evicted by a full
+	// AsyncClient queue.
+	DropQueueFull DropReason = "queue_full"
+	// DropSendFailed counts entries whose delivery failed after all
+	// retries, with no Spill configured.
+	DropSendFailed DropReason = "send_failed"
+	// DropOversized counts entries that failed because they were too large
+	// for the service (413) or the transport (ErrTooLarge).
+	DropOversized DropReason = "oversized"
+	// DropSpillFull counts entries rejected by the Spill's size cap.
+	DropSpillFull DropReason = "spill_full"
+	// DropSampled and DropRateLimited count entries filtered out by
+	// WithSampling and WithRateLimit before they were sent.
+	DropSampled     DropReason = "sampled"
+	DropRateLimited DropReason = "rate_limited"
+	// DropShutdown counts entries still queued when the deadline passed to
+	// AsyncClient.CloseContext expired.
+	DropShutdown DropReason = "shutdown"
+)
+
+// Metrics receives client health signals. Implementations must be safe for
+// concurrent use; the nfoprom module provides a Prometheus collector.
+type Metrics interface {
+	// EntriesSent counts entries accepted by nfo-service.
+	EntriesSent(n int)
+	// EntriesDropped counts entries that were discarded, by reason.
+	EntriesDropped(n int, reason DropReason)
+	// BatchFlushed records a successful batch request of n entries.
+	BatchFlushed(n int)
+	// RequestRetried counts each retry after a failed attempt.
+	RequestRetried()
+	// RequestCompleted records the latency and outcome of one HTTP attempt.
+	RequestCompleted(latency time.Duration, err error)
+	// QueueDepth reports the number of entries buffered by an AsyncClient.
+	QueueDepth(n int)
+}
+
+// WithMetrics reports client activity to m. An AsyncClient built on the
+// client reports its queue depth and drops there too.
+func WithMetrics(m Metrics) Option {
+	return func(cfg *clientConfig) {
+		if m != nil {
+			cfg.client.metrics = m
+		}
+	}
+}
+
+// nopMetrics is the default Metrics and discards everything.
+type nopMetrics struct{}
+
+func (nopMetrics) EntriesSent(int)                       {}
+func (nopMetrics) EntriesDropped(int, DropReason)        {}
+func (nopMetrics) BatchFlushed(int)                      {}
+func (nopMetrics) RequestRetried()                       {}
+func (nopMetrics) RequestCompleted(time.Duration, error) {}
+func (nopMetrics) QueueDepth(int)                        {}
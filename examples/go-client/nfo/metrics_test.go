@@ -0,0 +1,102 @@
+package nfo
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingMetrics records every Metrics call.
+type countingMetrics struct {
+	mu        sync.Mutex
+	sent      int
+	dropped   map[DropReason]int
+	batches   int
+	retries   int
+	requests  int
+	failures  int
+	lastDepth int
+}
+
+func newCountingMetrics() *countingMetrics {
+	return &countingMetrics{dropped: make(map[DropReason]int)}
+}
+
+func (m *countingMetrics) EntriesSent(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent += n
+}
+
+func (m *countingMetrics) EntriesDropped(n int, reason DropReason) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dropped[reason] += n
+}
+
+func (m *countingMetrics) BatchFlushed(int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.batches++
+}
+
+func (m *countingMetrics) RequestRetried() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func (m *countingMetrics) RequestCompleted(_ time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests++
+	if err != nil {
+		m.failures++
+	}
+}
+
+func (m *countingMetrics) QueueDepth(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastDepth = n
+}
+
+func TestMetricsClient(t *testing.T) {
+	rec, srv := newRecorder(t)
+	m := newCountingMetrics()
+	client := NewClient(srv.URL, WithMetrics(m), WithRetry(2, time.Millisecond))
+
+	rec.FailNext(http.StatusServiceUnavailable)
+	client.Log(LogEntry{Cmd: "a"})
+	client.LogBatch([]LogEntry{{Cmd: "b"}, {Cmd: "c"}})
+
+	if m.sent != 3 || m.batches != 1 || m.retries != 1 {
+		t.Fatalf("sent=%d batches=%d retries=%d", m.sent, m.batches, m.retries)
+	}
+	if m.requests != 3 || m.failures != 1 {
+		t.Fatalf("requests=%d failures=%d", m.requests, m.failures)
+	}
+}
+
+func TestMetricsAsyncClient(t *testing.T) {
+	rec, srv := newRecorder(t)
+	m := newCountingMetrics()
+	async := newAsyncClient(NewClient(srv.URL, WithMetrics(m)), AsyncConfig{QueueSize: 2})
+
+	async.Log(LogEntry{Cmd: "a"})
+	async.Log(LogEntry{Cmd: "b"})
+	if m.lastDepth != 2 {
+		t.Fatalf("queue depth = %d, want 2", m.lastDepth)
+	}
+	async.Log(LogEntry{Cmd: "c"})
+	if m.dropped[DropQueueFull] != 1 {
+		t.Fatalf("dropped = %v", m.dropped)
+	}
+
+	rec.SetStatus(http.StatusInternalServerError)
+	async.Flush()
+	if m.lastDepth != 0 || m.dropped[DropSendFailed] != 2 {
+		t.Fatalf("depth=%d dropped=%v", m.lastDepth, m.dropped)
+	}
+}
@@ -0,0 +1,478 @@
+package nfo
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// msgpackCodec encodes entries as MessagePack maps keyed by their JSON
+// names, so a server can decode them into the same schema. Values with a
+// MarshalText method (Level, time.Time) are sent as strings.
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string { return ContentTypeMsgpack }
+
+func (msgpackCodec) Marshal(entry LogEntry) ([]byte, error) {
+	data, err := appendMsgpack(nil, reflect.ValueOf(entry))
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+	return data, nil
+}
+
+func (msgpackCodec) marshalFlat(entry LogEntry, prefix string) ([]byte, error) {
+	fields := entry.Fields
+	entry.Fields = nil
+	pairs := structPairs(reflect.ValueOf(entry))
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		if name := prefix + key; !reservedKeys[name] {
+			pairs = append(pairs, msgpackPair{name, reflect.ValueOf(fields[key])})
+		}
+	}
+	data, err := appendPairs(nil, pairs)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+	return data, nil
+}
+
+func (msgpackCodec) Batch(encoded [][]byte) []byte {
+	body := appendArrayHeader(nil, len(encoded))
+	for _, data := range encoded {
+		body = append(body, data...)
+	}
+	return body
+}
+
+func (msgpackCodec) Unmarshal(data []byte) ([]LogEntry, error) {
+	r := msgpackReader{data: data}
+	v, err := r.value()
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	if r.pos != len(data) {
+		return nil, fmt.Errorf("unmarshal: %d trailing bytes", len(data)-r.pos)
+	}
+	// The decoded tree only holds JSON-compatible values, so the JSON
+	// decoder can map it onto LogEntry.
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	if _, ok := v.([]any); ok {
+		var entries []LogEntry
+		if err := json.Unmarshal(doc, &entries); err != nil {
+			return nil, fmt.Errorf("unmarshal: %w", err)
+		}
+		return entries, nil
+	}
+	var entry LogEntry
+	if err := json.Unmarshal(doc, &entry); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	return []LogEntry{entry}, nil
+}
+
+var (
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// appendMsgpack appends the MessagePack encoding of v to b, following the
+// rules of encoding/json for struct tags and omitempty.
+func appendMsgpack(b []byte, v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return append(b, 0xc0), nil
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return append(b, 0xc0), nil
+	}
+	if v.Type().Implements(jsonMarshalerType) {
+		data, err := v.Interface().(json.Marshaler).MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		var decoded any
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, err
+		}
+		return appendMsgpack(b, reflect.ValueOf(decoded))
+	}
+	if v.Type().Implements(textMarshalerType) {
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return appendString(b, string(text)), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return appendMsgpack(b, v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			return append(b, 0xc3), nil
+		}
+		return append(b, 0xc2), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendInt(b, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUint(b, v.Uint()), nil
+	case reflect.Float32:
+		return binary.BigEndian.AppendUint32(append(b, 0xca), math.Float32bits(float32(v.Float()))), nil
+	case reflect.Float64:
+		return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v.Float())), nil
+	case reflect.String:
+		return appendString(b, v.String()), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return append(b, 0xc0), nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return appendBinary(b, v.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Array:
+		b = appendArrayHeader(b, v.Len())
+		for i := range v.Len() {
+			var err error
+			if b, err = appendMsgpack(b, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return append(b, 0xc0), nil
+		}
+		pairs := make([]msgpackPair, 0, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			key, err := mapKey(iter.Key())
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, msgpackPair{key, iter.Value()})
+		}
+		slices.SortFunc(pairs, func(a, b msgpackPair) int { return strings.Compare(a.key, b.key) })
+		return appendPairs(b, pairs)
+	case reflect.Struct:
+		return appendPairs(b, structPairs(v))
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type %s", v.Type())
+}
+
+// msgpackPair is one key and value of an encoded map.
+type msgpackPair struct {
+	key   string
+	value reflect.Value
+}
+
+func appendPairs(b []byte, pairs []msgpackPair) ([]byte, error) {
+	b = appendMapHeader(b, len(pairs))
+	for _, p := range pairs {
+		b = appendString(b, p.key)
+		var err error
+		if b, err = appendMsgpack(b, p.value); err != nil {
+			return nil, fmt.Errorf("%s: %w", p.key, err)
+		}
+	}
+	return b, nil
+}
+
+// mapKey formats a map key the way encoding/json does.
+func mapKey(k reflect.Value) (string, error) {
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("msgpack: unsupported map key type %s", k.Type())
+}
+
+// msgpackField describes an encoded struct field.
+type msgpackField struct {
+	index     int
+	name      string
+	omitEmpty bool
+}
+
+// msgpackFields caches the encoded fields of each struct type.
+var msgpackFields sync.Map // reflect.Type -> []msgpackField
+
+func structFields(t reflect.Type) []msgpackField {
+	if fields, ok := msgpackFields.Load(t); ok {
+		return fields.([]msgpackField)
+	}
+	var fields []msgpackField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, msgpackField{index: i, name: name, omitEmpty: strings.Contains(opts, "omitempty")})
+	}
+	msgpackFields.Store(t, fields)
+	return fields
+}
+
+// structPairs returns the fields of v that are encoded.
+func structPairs(v reflect.Value) []msgpackPair {
+	fields := structFields(v.Type())
+	pairs := make([]msgpackPair, 0, len(fields))
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		pairs = append(pairs, msgpackPair{f.name, fv})
+	}
+	return pairs
+}
+
+// isEmptyValue reports whether omitempty drops v, as in encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func appendInt(b []byte, n int64) []byte {
+	switch {
+	case n >= 0:
+		return appendUint(b, uint64(n))
+	case n >= -32:
+		return append(b, byte(n))
+	case n >= math.MinInt8:
+		return append(b, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(n))
+	case n >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(n))
+}
+
+func appendUint(b []byte, n uint64) []byte {
+	switch {
+	case n < 128:
+		return append(b, byte(n))
+	case n <= math.MaxUint8:
+		return append(b, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), n)
+}
+
+func appendString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendBinary(b []byte, data []byte) []byte {
+	switch n := len(data); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, data...)
+}
+
+func appendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+}
+
+func appendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+}
+
+var errMsgpackShort = errors.New("msgpack: unexpected end of data")
+
+// msgpackReader decodes MessagePack into the types encoding/json produces
+// for an any: map[string]any, []any, string, float64, bool and nil, plus
+// int64, uint64 and []byte.
+type msgpackReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *msgpackReader) next(n int) ([]byte, error) {
+	if n < 0 || len(r.data)-r.pos < n {
+		return nil, errMsgpackShort
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// bigEndian reads an n-byte big-endian length or number.
+func (r *msgpackReader) bigEndian(n int) (uint64, error) {
+	b, err := r.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (r *msgpackReader) value() (any, error) {
+	b, err := r.next(1)
+	if err != nil {
+		return nil, err
+	}
+	switch c := b[0]; {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return r.mapOf(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return r.arrayOf(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return r.str(int(c & 0x1f))
+	case c == 0xc0:
+		return nil, nil
+	case c == 0xc2, c == 0xc3:
+		return c == 0xc3, nil
+	case c >= 0xc4 && c <= 0xc6:
+		n, err := r.bigEndian(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		data, err := r.next(int(n))
+		return append([]byte(nil), data...), err
+	case c == 0xca:
+		n, err := r.bigEndian(4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case c == 0xcb:
+		n, err := r.bigEndian(8)
+		return math.Float64frombits(n), err
+	case c >= 0xcc && c <= 0xcf:
+		return r.bigEndian(1 << (c - 0xcc))
+	case c >= 0xd0 && c <= 0xd3:
+		size := 1 << (c - 0xd0)
+		n, err := r.bigEndian(size)
+		shift := 64 - 8*size
+		return int64(n<<shift) >> shift, err
+	case c >= 0xd9 && c <= 0xdb:
+		n, err := r.bigEndian(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return r.str(int(n))
+	case c == 0xdc, c == 0xdd:
+		n, err := r.bigEndian(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return r.arrayOf(int(n))
+	case c == 0xde, c == 0xdf:
+		n, err := r.bigEndian(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return r.mapOf(int(n))
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported type byte 0x%02x", c)
+	}
+}
+
+func (r *msgpackReader) str(n int) (any, error) {
+	b, err := r.next(n)
+	return string(b), err
+}
+
+func (r *msgpackReader) arrayOf(n int) (any, error) {
+	if n > len(r.data)-r.pos {
+		return nil, errMsgpackShort
+	}
+	arr := make([]any, n)
+	for i := range arr {
+		v, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		arr[i] = v
+	}
+	return arr, nil
+}
+
+func (r *msgpackReader) mapOf(n int) (any, error) {
+	if n > len(r.data)-r.pos {
+		return nil, errMsgpackShort
+	}
+	m := make(map[string]any, n)
+	for range n {
+		k, err := r.value()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("msgpack: map key of type %T", k)
+		}
+		if m[key], err = r.value(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,64 @@
+package nfo
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMsgpackEncoding(t *testing.T) {
+	tests := []struct {
+		v    any
+		want []byte
+	}{
+		{nil, []byte{0xc0}},
+		{true, []byte{0xc3}},
+		{5, []byte{0x05}},
+		{-3, []byte{0xfd}},
+		{200, []byte{0xcc, 0xc8}},
+		{-200, []byte{0xd1, 0xff, 0x38}},
+		{70000, []byte{0xce, 0x00, 0x01, 0x11, 0x70}},
+		{1.5, []byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}},
+		{"hi", []byte{0xa2, 'h', 'i'}},
+		{[]string{"a"}, []byte{0x91, 0xa1, 'a'}},
+		{map[string]int{"b": 2, "a": 1}, []byte{0x82, 0xa1, 'a', 0x01, 0xa1, 'b', 0x02}},
+		{LevelError, []byte{0xa5, 'e', 'r', 'r', 'o', 'r'}},
+	}
+	for _, tt := range tests {
+		got, err := appendMsgpack(nil, reflect.ValueOf(tt.v))
+		if err != nil || !bytes.Equal(got, tt.want) {
+			t.Errorf("%#v: got % x, %v; want % x", tt.v, got, err, tt.want)
+		}
+	}
+	if _, err := appendMsgpack(nil, reflect.ValueOf(make(chan int))); err == nil {
+		t.Error("expected an error for a channel")
+	}
+}
+
+func TestMsgpackDecoding(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	values := []any{
+		int64(-1), int64(math.MinInt64), uint64(math.MaxUint64), 3.25, long,
+		[]any{"a", nil, false}, map[string]any{"k": []byte{1, 2}},
+	}
+	for _, v := range values {
+		data, _ := appendMsgpack(nil, reflect.ValueOf(v))
+		r := msgpackReader{data: data}
+		got, err := r.value()
+		if err != nil || !reflect.DeepEqual(got, v) {
+			t.Errorf("%#v: decoded %#v, %v", v, got, err)
+		}
+	}
+
+	truncated, _ := appendMsgpack(nil, reflect.ValueOf(long))
+	r := msgpackReader{data: truncated[:10]}
+	if _, err := r.value(); !errors.Is(err, errMsgpackShort) {
+		t.Errorf("truncated input: %v", err)
+	}
+	if _, err := MsgpackCodec.Unmarshal([]byte{0xc0, 0xc0}); err == nil {
+		t.Error("expected an error for trailing bytes")
+	}
+}
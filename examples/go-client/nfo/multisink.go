@@ -0,0 +1,177 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SinkFilter selects the entries a Route receives. Zero fields match
+// everything.
+type SinkFilter struct {
+	// Envs lists the environments to accept. An entry without Env is
+	// compared as $NFO_ENV, or "prod" if that is unset.
+	Envs []string
+	// MinLevel drops entries below this level (see Level for how an unset
+	// level is derived).
+	MinLevel Level
+	// Success, if set, accepts only successful (true) or only failed
+	// (false) entries.
+	Success *bool
+	// Match, if set, must also return true.
+	Match func(LogEntry) bool
+}
+
+// matches reports whether entry passes f.
+func (f SinkFilter) matches(entry LogEntry) bool {
+	if len(f.Envs) > 0 {
+		env := entry.Env
+		if env == "" {
+			env = getEnv("NFO_ENV", "prod")
+		}
+		if !slices.Contains(f.Envs, env) {
+			return false
+		}
+	}
+	if levelOf(entry) < f.MinLevel {
+		return false
+	}
+	if f.Success != nil && *f.Success == failed(entry) {
+		return false
+	}
+	return f.Match == nil || f.Match(entry)
+}
+
+// Route is one destination of a MultiSink.
+type Route struct {
+	// Name identifies the sink in errors (default its index).
+	Name string
+	// Sink receives the matching entries, e.g. an NfoClient, an
+	// AsyncClient or a WriterSink.
+	Sink   Logger
+	Filter SinkFilter
+}
+
+// MultiSink fans entries out to several loggers, each with its own filter.
+// Matching sinks are called in parallel and fail independently: one sink's
+// error never keeps an entry from the others.
+//
+//	sink := nfo.NewMultiSink(
+//	    nfo.Route{Name: "service", Sink: async},
+//	    nfo.Route{Name: "stdout", Sink: nfo.NewWriterSink(os.Stdout),
+//	        Filter: nfo.SinkFilter{Envs: []string{"dev"}}},
+//	)
+type MultiSink struct {
+	routes []Route
+}
+
+var _ Logger = (*MultiSink)(nil)
+
+// NewMultiSink returns a MultiSink dispatching to routes.
+func NewMultiSink(routes ...Route) *MultiSink {
+	m := &MultiSink{routes: slices.Clone(routes)}
+	for i := range m.routes {
+		if m.routes[i].Name == "" {
+			m.routes[i].Name = strconv.Itoa(i)
+		}
+	}
+	return m
+}
+
+// Log sends entry to every matching sink.
+func (m *MultiSink) Log(entry LogEntry) error {
+	return m.LogContext(context.Background(), entry)
+}
+
+// LogContext sends entry to every matching sink, passing ctx to sinks that
+// accept one. The result joins the errors of failed sinks, each prefixed
+// with the sink's name.
+func (m *MultiSink) LogContext(ctx context.Context, entry LogEntry) error {
+	entry = stamp(entry, time.Now) // every sink sees the same time
+	var matched []Route
+	for _, r := range m.routes {
+		if r.Filter.matches(entry) {
+			matched = append(matched, r)
+		}
+	}
+	if len(matched) == 1 {
+		return sinkError(matched[0].Name, logContext(ctx, matched[0].Sink, entry))
+	}
+
+	errs := make([]error, len(matched))
+	var wg sync.WaitGroup
+	for i, r := range matched {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = sinkError(r.Name, logContext(ctx, r.Sink, entry))
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Close closes every sink that implements io.Closer, such as AsyncClient.
+func (m *MultiSink) Close() error {
+	var errs []error
+	for _, r := range m.routes {
+		if c, ok := r.Sink.(io.Closer); ok {
+			errs = append(errs, sinkError(r.Name, c.Close()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sinkError(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("sink %s: %w", name, err)
+}
+
+// WriterSink writes entries as JSON Lines to an io.Writer such as
+// os.Stdout. It is both a Logger, writing entries as given, and a
+// Transport, so NewClient(url, WithTransport(sink)) writes them after
+// defaults, metadata, hooks and redaction have been applied.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var (
+	_ Logger    = (*WriterSink)(nil)
+	_ Transport = (*WriterSink)(nil)
+)
+
+// NewWriterSink returns a sink writing to w. Writes are serialized.
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Log writes entry as one JSON line.
+func (s *WriterSink) Log(entry LogEntry) error {
+	return s.Send(context.Background(), []LogEntry{entry})
+}
+
+// Send writes entries as JSON lines in one write. Entries without a
+// Timestamp are stamped with the current time.
+func (s *WriterSink) Send(_ context.Context, entries []LogEntry) error {
+	var buf []byte
+	for _, entry := range entries {
+		data, err := json.Marshal(stamp(entry, time.Now))
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+		buf = append(append(buf, data...), '\n')
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(buf)
+	return err
+}
@@ -0,0 +1,82 @@
+package nfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type failingLogger struct{ err error }
+
+func (f failingLogger) Log(LogEntry) error { return f.err }
+
+func TestMultiSinkRouting(t *testing.T) {
+	all, errorsOnly, dev := &memLogger{}, &memLogger{}, &memLogger{}
+	failed := false
+	sink := NewMultiSink(
+		Route{Name: "all", Sink: all},
+		Route{Name: "errors", Sink: errorsOnly, Filter: SinkFilter{Success: &failed}},
+		Route{Name: "dev", Sink: dev, Filter: SinkFilter{Envs: []string{"dev"}, MinLevel: LevelInfo}},
+	)
+
+	sink.Log(LogEntry{Cmd: "ok", Env: "dev"})
+	sink.Log(LogEntry{Cmd: "boom", Env: "prod", Error: "boom"})
+	sink.Log(LogEntry{Cmd: "noise", Env: "dev", Level: LevelDebug})
+
+	if n := len(all.Entries()); n != 3 {
+		t.Errorf("all: got %d entries", n)
+	}
+	if got := errorsOnly.Entries(); len(got) != 1 || got[0].Cmd != "boom" {
+		t.Errorf("errors: got %+v", got)
+	}
+	if got := dev.Entries(); len(got) != 1 || got[0].Cmd != "ok" {
+		t.Errorf("dev: got %+v", got)
+	}
+}
+
+func TestMultiSinkIndependentFailures(t *testing.T) {
+	good := &memLogger{}
+	down := errors.New("down")
+	sink := NewMultiSink(Route{Sink: failingLogger{down}}, Route{Name: "good", Sink: good})
+
+	err := sink.Log(LogEntry{Cmd: "x"})
+	if !errors.Is(err, down) || !strings.Contains(err.Error(), "sink 0: down") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(good.Entries()) != 1 {
+		t.Fatal("a failing sink must not block the others")
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	client := NewClient("", WithTransport(NewWriterSink(&buf)), WithEnv("dev"))
+	client.LogBatch([]LogEntry{{Cmd: "a"}, {Cmd: "b"}})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	var entry LogEntry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Cmd != "b" || entry.Env != "dev" || entry.Level != LevelInfo {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestMultiSinkClose(t *testing.T) {
+	_, srv := newRecorder(t)
+	async := NewAsyncClient(NewClient(srv.URL), AsyncConfig{})
+	sink := NewMultiSink(Route{Sink: async}, Route{Sink: &memLogger{}})
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := async.Log(LogEntry{Cmd: "late"}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected the async sink to be closed, got %v", err)
+	}
+}
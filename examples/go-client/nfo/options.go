@@ -0,0 +1,173 @@
+package nfo
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DefaultUserAgent is sent with every request unless WithUserAgent overrides it.
+const DefaultUserAgent = "nfo-go-client"
+
+// Option configures an NfoClient built by NewClient.
+type Option func(*clientConfig)
+
+// clientConfig collects option values before the client is assembled, so
+// options can be given in any order.
+type clientConfig struct {
+	client     *NfoClient
+	timeout    time.Duration
+	pool       *PoolConfig
+	tls        *tls.Config
+	proxy      func(*http.Request) (*url.URL, error)
+	customHTTP bool
+}
+
+// NewClient creates a client pointing at the given nfo-service URL.
+//
+//	client := nfo.NewClient("http://localhost:8080",
+//	    nfo.WithTimeout(2*time.Second),
+//	    nfo.WithRetry(3, 100*time.Millisecond),
+//	    nfo.WithEnv("staging"),
+//	)
+func NewClient(baseURL string, opts ...Option) *NfoClient {
+	meta := DetectMetadata(false)
+	cfg := &clientConfig{
+		client: &NfoClient{
+			BaseURL:       baseURL,
+			HTTPClient:    &http.Client{Timeout: 5 * time.Second},
+			MaxBatchSize:  DefaultMaxBatchSize,
+			MaxBatchBytes: DefaultMaxBatchBytes,
+			headers:       make(http.Header),
+			userAgent:     DefaultUserAgent,
+			defaults: LogEntry{
+				Language: "go",
+				Env:      getEnv("NFO_ENV", "prod"),
+			},
+			meta:           &meta,
+			retry:          retryPolicy{attempts: 1},
+			metrics:        nopMetrics{},
+			traceExtractor: TraceFromContext,
+			clock:          time.Now,
+			codec:          JSONCodec,
+		},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	c := cfg.client
+	hc, changed := *c.HTTPClient, false
+	if cfg.timeout > 0 {
+		hc.Timeout, changed = cfg.timeout, true
+	}
+	if cfg.pool != nil || cfg.tls != nil || cfg.proxy != nil || !cfg.customHTTP {
+		var pool PoolConfig
+		if cfg.pool != nil {
+			pool = *cfg.pool
+		}
+		if t, ok := pool.httpTransport(hc.Transport); ok {
+			if cfg.tls != nil {
+				t.TLSClientConfig = cfg.tls
+			}
+			if cfg.proxy != nil {
+				t.Proxy = cfg.proxy
+			}
+			hc.Transport, changed = t, true
+		}
+		if pool.MaxInFlight > 0 {
+			c.inflight = make(chan struct{}, pool.MaxInFlight)
+		}
+	}
+	if changed {
+		c.HTTPClient = &hc
+	}
+	return c
+}
+
+// WithHTTPClient sends requests through hc instead of a private client.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(cfg *clientConfig) {
+		if hc != nil {
+			cfg.client.HTTPClient = hc
+			cfg.customHTTP = true
+		}
+	}
+}
+
+// WithTimeout sets the per-request timeout (default 5s). It does not
+// modify an http.Client passed to WithHTTPClient; a copy is used instead.
+func WithTimeout(d time.Duration) Option {
+	return func(cfg *clientConfig) {
+		cfg.timeout = d
+	}
+}
+
+// WithHeaders adds headers to every request.
+func WithHeaders(headers map[string]string) Option {
+	return func(cfg *clientConfig) {
+		for key, value := range headers {
+			cfg.client.headers.Set(key, value)
+		}
+	}
+}
+
+// WithUserAgent overrides DefaultUserAgent.
+func WithUserAgent(ua string) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.userAgent = ua
+	}
+}
+
+// WithRetry retries failed requests up to maxAttempts times in total,
+// doubling the wait after each attempt starting from backoff. Transport
+// errors, 429 and 5xx responses are retried; other failures are not.
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(cfg *clientConfig) {
+		if maxAttempts < 1 {
+			maxAttempts = 1
+		}
+		cfg.client.retry = retryPolicy{attempts: maxAttempts, backoff: backoff}
+	}
+}
+
+// WithEnv sets the environment stamped on entries that leave Env empty
+// (default $NFO_ENV or "prod").
+func WithEnv(env string) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.defaults.Env = env
+	}
+}
+
+// WithDefaultFields fills every zero-valued field of an outgoing entry from
+// defaults. Fields the entry sets itself always win.
+func WithDefaultFields(defaults LogEntry) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.defaults = mergeEntry(defaults, cfg.client.defaults)
+	}
+}
+
+// WithBatchLimits overrides DefaultMaxBatchSize and DefaultMaxBatchBytes.
+// Zero leaves the corresponding limit unchanged.
+func WithBatchLimits(maxSize, maxBytes int) Option {
+	return func(cfg *clientConfig) {
+		if maxSize > 0 {
+			cfg.client.MaxBatchSize = maxSize
+		}
+		if maxBytes > 0 {
+			cfg.client.MaxBatchBytes = maxBytes
+		}
+	}
+}
+
+// retryPolicy is the exponential backoff configured by WithRetry.
+type retryPolicy struct {
+	attempts int
+	backoff  time.Duration
+}
+
+// delay returns the wait before the attempt following attempt n (1-based).
+func (p retryPolicy) delay(n int) time.Duration {
+	return p.backoff << (n - 1)
+}
@@ -0,0 +1,75 @@
+package nfo
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewClientHeaders(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL,
+		WithHeaders(map[string]string{"X-Team": "infra"}),
+		WithUserAgent("builder/1.0"),
+	)
+
+	if err := client.Log(LogEntry{Cmd: "build"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	h := rec.Header()
+	if h.Get("X-Team") != "infra" || h.Get("User-Agent") != "builder/1.0" {
+		t.Fatalf("unexpected headers: %v", h)
+	}
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL,
+		WithEnv("staging"),
+		WithDefaultFields(LogEntry{Language: "golang", Output: "n/a"}),
+	)
+
+	client.Log(LogEntry{Cmd: "build", Output: "ok"})
+	got := rec.Entries()[0]
+	if got.Env != "staging" || got.Language != "golang" || got.Output != "ok" {
+		t.Fatalf("defaults not applied: %+v", got)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	rec, srv := newRecorder(t)
+	rec.FailNext(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	client := NewClient(srv.URL, WithRetry(3, time.Millisecond))
+
+	if err := client.Log(LogEntry{Cmd: "build"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if rec.Attempts() != 3 || len(rec.Entries()) != 1 {
+		t.Fatalf("expected success on 3rd attempt, got %d attempts", rec.Attempts())
+	}
+}
+
+func TestWithRetrySkipsClientErrors(t *testing.T) {
+	rec, srv := newRecorder(t)
+	rec.FailNext(http.StatusBadRequest)
+	client := NewClient(srv.URL, WithRetry(3, time.Millisecond))
+
+	if err := client.Log(LogEntry{Cmd: "build"}); err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+	if rec.Attempts() != 1 {
+		t.Fatalf("400 must not be retried, got %d attempts", rec.Attempts())
+	}
+}
+
+func TestWithTimeoutCopiesHTTPClient(t *testing.T) {
+	hc := &http.Client{Timeout: time.Minute}
+	client := NewClient("http://unused", WithTimeout(time.Second), WithHTTPClient(hc))
+
+	if client.HTTPClient.Timeout != time.Second {
+		t.Errorf("timeout not applied: %v", client.HTTPClient.Timeout)
+	}
+	if hc.Timeout != time.Minute {
+		t.Errorf("caller's http.Client was modified: %v", hc.Timeout)
+	}
+}
@@ -0,0 +1,63 @@
+package nfo
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// CapturePanic logs a panic in progress and then re-panics with the same
+// value. It must be deferred directly:
+//
+//	defer client.CapturePanic(ctx, "worker", []string{jobID})
+//
+// The entry has success=false, the panic value in Error, and the goroutine
+// stack in Fields["stack"].
+func (c *NfoClient) CapturePanic(ctx context.Context, cmd string, args []string) {
+	if r := recover(); r != nil {
+		c.LogContext(ctx, panicEntry(cmd, args, r))
+		panic(r)
+	}
+}
+
+// Recover is CapturePanic without re-panicking: the panic is logged and the
+// deferring function returns normally.
+func (c *NfoClient) Recover(ctx context.Context, cmd string, args []string) {
+	if r := recover(); r != nil {
+		c.LogContext(ctx, panicEntry(cmd, args, r))
+	}
+}
+
+// CapturePanic logs a panic in progress, flushes the queue so the entry is
+// not lost when the process dies, and re-panics. It must be deferred directly.
+func (a *AsyncClient) CapturePanic(ctx context.Context, cmd string, args []string) {
+	if r := recover(); r != nil {
+		a.LogContext(ctx, panicEntry(cmd, args, r))
+		a.Flush()
+		panic(r)
+	}
+}
+
+// Recover is CapturePanic without re-panicking. The entry is queued like any
+// other, since the process keeps running.
+func (a *AsyncClient) Recover(ctx context.Context, cmd string, args []string) {
+	if r := recover(); r != nil {
+		a.LogContext(ctx, panicEntry(cmd, args, r))
+	}
+}
+
+// panicEntry describes a recovered panic value r.
+func panicEntry(cmd string, args []string, r any) LogEntry {
+	failed := false
+	return LogEntry{
+		Cmd:      cmd,
+		Args:     args,
+		Language: "go",
+		Success:  &failed,
+		Error:    fmt.Sprintf("panic: %v", r),
+		Fields: map[string]any{
+			"panic_type": fmt.Sprintf("%T", r),
+			"stack":      string(debug.Stack()),
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package nfo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCapturePanicRepanics(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("expected re-panic with original value, got %v", r)
+			}
+		}()
+		defer client.CapturePanic(context.Background(), "worker", []string{"job-1"})
+		panic("boom")
+	}()
+
+	e := rec.Entries()[0]
+	if e.Cmd != "worker" || e.Success == nil || *e.Success || e.Error != "panic: boom" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	stack, _ := e.Fields["stack"].(string)
+	if !strings.Contains(stack, "TestCapturePanicRepanics") {
+		t.Fatalf("stack does not include the panicking frame:\n%s", stack)
+	}
+	if e.Fields["panic_type"] != "string" {
+		t.Fatalf("unexpected panic_type: %v", e.Fields["panic_type"])
+	}
+}
+
+func TestRecoverSwallows(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	func() {
+		defer client.Recover(context.Background(), "worker", nil)
+		var m map[string]int
+		m["x"] = 1 // nil map write
+	}()
+
+	if got := rec.Entries(); len(got) != 1 || !strings.Contains(got[0].Error, "nil map") {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+}
+
+func TestRecoverNoPanic(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	func() {
+		defer client.Recover(context.Background(), "worker", nil)
+	}()
+	if len(rec.Entries()) != 0 {
+		t.Fatal("nothing should be logged without a panic")
+	}
+}
+
+func TestAsyncCapturePanicFlushes(t *testing.T) {
+	rec, srv := newRecorder(t)
+	async := NewAsyncClient(NewClient(srv.URL), AsyncConfig{FlushInterval: time.Hour})
+	defer async.Close()
+
+	func() {
+		defer func() { recover() }()
+		defer async.CapturePanic(context.Background(), "worker", nil)
+		panic("boom")
+	}()
+	if len(rec.Entries()) != 1 {
+		t.Fatal("panic entry should be flushed before re-panicking")
+	}
+}
@@ -0,0 +1,93 @@
+package nfo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultMaxIdleConnsPerHost is the number of idle keep-alive connections
+// a client built by NewClient keeps to nfo-service. net/http's default of 2
+// forces new connections as soon as more than two goroutines log at once.
+const DefaultMaxIdleConnsPerHost = 16
+
+// PoolConfig tunes the HTTP connection pool and request concurrency of a
+// client. Zero values keep the defaults.
+type PoolConfig struct {
+	// MaxIdleConnsPerHost bounds the idle connections kept open to each
+	// nfo-service host (default DefaultMaxIdleConnsPerHost).
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost bounds all connections to each host, idle or not
+	// (default unlimited).
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections idle for longer (default 90s).
+	IdleConnTimeout time.Duration
+	// KeepAlive is the TCP keep-alive period of new connections (default
+	// 30s); negative disables TCP keep-alives.
+	KeepAlive time.Duration
+	// DisableKeepAlives opens a new connection for every request.
+	DisableKeepAlives bool
+	// MaxInFlight caps the requests the client has in flight at once,
+	// across all goroutines; further Log, LogBatch and Query calls wait for
+	// a slot or for their context to end. Zero means no limit.
+	MaxInFlight int
+}
+
+// WithConnectionPool applies cfg to the client's HTTP transport. An
+// http.Client passed to WithHTTPClient is not modified: its *http.Transport
+// is cloned, and a Transport of any other type is left as is, in which case
+// only MaxInFlight takes effect.
+func WithConnectionPool(cfg PoolConfig) Option {
+	return func(c *clientConfig) {
+		c.pool = &cfg
+	}
+}
+
+// httpTransport returns a copy of rt with cfg applied, or false if rt is not
+// an *http.Transport. A nil rt stands for http.DefaultTransport.
+func (cfg PoolConfig) httpTransport(rt http.RoundTripper) (*http.Transport, bool) {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	base, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, false
+	}
+	t := base.Clone()
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	} else if t.MaxIdleConnsPerHost == 0 {
+		t.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if t.MaxIdleConns > 0 && t.MaxIdleConns < t.MaxIdleConnsPerHost {
+		t.MaxIdleConns = t.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.KeepAlive != 0 {
+		t.DialContext = (&net.Dialer{Timeout: 30 * time.Second, KeepAlive: cfg.KeepAlive}).DialContext
+	}
+	if cfg.DisableKeepAlives {
+		t.DisableKeepAlives = true
+	}
+	return t, true
+}
+
+// acquire takes an in-flight slot, waiting until one is free or ctx ends.
+// The returned func gives the slot back.
+func (c *NfoClient) acquire(ctx context.Context) (func(), error) {
+	if c.inflight == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.inflight <- struct{}{}:
+		return func() { <-c.inflight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
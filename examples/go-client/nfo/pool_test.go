@@ -0,0 +1,136 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConnectionPool(t *testing.T) {
+	transport := func(c *NfoClient) *http.Transport {
+		t.Helper()
+		tr, ok := c.HTTPClient.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("transport is %T", c.HTTPClient.Transport)
+		}
+		return tr
+	}
+
+	if tr := transport(NewClient("http://x")); tr.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || tr == http.DefaultTransport {
+		t.Fatalf("default pool: %d idle per host", tr.MaxIdleConnsPerHost)
+	}
+
+	tr := transport(NewClient("http://x", WithConnectionPool(PoolConfig{
+		MaxIdleConnsPerHost: 256,
+		MaxConnsPerHost:     64,
+		IdleConnTimeout:     time.Minute,
+		DisableKeepAlives:   true,
+	})))
+	if tr.MaxIdleConnsPerHost != 256 || tr.MaxIdleConns < 256 || tr.MaxConnsPerHost != 64 ||
+		tr.IdleConnTimeout != time.Minute || !tr.DisableKeepAlives {
+		t.Fatalf("pool settings not applied: %+v", tr)
+	}
+
+	own := &http.Transport{MaxIdleConnsPerHost: 4}
+	hc := &http.Client{Transport: own}
+	if c := NewClient("http://x", WithHTTPClient(hc)); c.HTTPClient != hc {
+		t.Fatal("WithHTTPClient alone should keep the given client")
+	}
+	c := NewClient("http://x", WithHTTPClient(hc), WithConnectionPool(PoolConfig{MaxConnsPerHost: 8}))
+	if tr := transport(c); tr == own || tr.MaxIdleConnsPerHost != 4 || tr.MaxConnsPerHost != 8 {
+		t.Fatalf("expected a tuned clone of the given transport, got %+v", tr)
+	}
+	if own.MaxConnsPerHost != 0 || hc.Transport != own {
+		t.Fatal("the given client was modified")
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	var active, peak atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+		}
+		<-release
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithConnectionPool(PoolConfig{MaxInFlight: 2}))
+	var wg sync.WaitGroup
+	for i := range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.Log(LogEntry{Cmd: fmt.Sprint("job-", i)})
+		}()
+	}
+	for active.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.LogContext(ctx, LogEntry{Cmd: "waits"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected to time out waiting for a slot, got %v", err)
+	}
+	close(release)
+	wg.Wait()
+	if p := peak.Load(); p != 2 {
+		t.Fatalf("peak in-flight requests = %d, want 2", p)
+	}
+}
+
+// TestConcurrentUse shares one fully configured client between goroutines;
+// run with -race.
+func TestConcurrentUse(t *testing.T) {
+	rec, srv := newRecorder(t)
+	var hooked atomic.Int64
+	client := NewClient(srv.URL,
+		WithRetry(2, time.Millisecond),
+		WithCircuitBreaker(BreakerConfig{FailureThreshold: 100}),
+		WithRateLimit(RateLimitConfig{PerSecond: 1e6, Burst: 1000}),
+		WithRedaction(RedactConfig{Fields: []string{"password"}}),
+		WithFields(map[string]any{"service": "api"}),
+		WithHook(func(ctx context.Context, e *LogEntry) (bool, error) {
+			hooked.Add(1)
+			return true, nil
+		}),
+		WithConnectionPool(PoolConfig{MaxInFlight: 4}),
+	)
+
+	const goroutines, perGoroutine = 8, 25
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perGoroutine {
+				entry := LogEntry{Cmd: "work", Fields: map[string]any{"g": g, "i": i, "password": "x"}}
+				if i%5 == 0 {
+					client.LogBatch([]LogEntry{entry, entry})
+				} else if err := client.Log(entry); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := goroutines * (perGoroutine + perGoroutine/5)
+	if got := len(rec.Entries()); got != want || hooked.Load() != int64(want) {
+		t.Fatalf("got %d entries, %d hook calls; want %d", got, hooked.Load(), want)
+	}
+	for _, e := range rec.Entries() {
+		if e.Fields["password"] != RedactMask || e.Fields["service"] != "api" {
+			t.Fatalf("entry not processed: %+v", e.Fields)
+		}
+	}
+}
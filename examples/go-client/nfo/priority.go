@@ -0,0 +1,110 @@
+package nfo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Priority decides which entries an AsyncClient sheds first when its queue
+// is full and sends first when it flushes. The zero value means unset: the
+// priority is then derived from the level, PriorityLow for LevelDebug,
+// PriorityHigh for LevelError and PriorityNormal otherwise.
+//
+// Priority is local to the client and is not sent to nfo-service; a Spill
+// keeps it for replay.
+type Priority int
+
+const (
+	PriorityLow Priority = iota + 1
+	PriorityNormal
+	PriorityHigh
+	// PriorityCritical entries, such as audit records, are never evicted
+	// or rejected by a full queue: logging them waits for room instead,
+	// whatever the OverflowPolicy.
+	PriorityCritical
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("priority(%d)", int(p))
+}
+
+// ParsePriority parses "low", "normal", "high" and "critical", ignoring
+// case.
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(s) {
+	case "low":
+		return PriorityLow, nil
+	case "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	case "critical":
+		return PriorityCritical, nil
+	}
+	return 0, fmt.Errorf("nfo: unknown priority %q", s)
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx carrying p, which context-aware calls
+// (LogContext, Call, CapturePanic, RunCommand, the slog handler) give to
+// every entry that does not set its own Priority:
+//
+//	ctx = nfo.WithPriority(ctx, nfo.PriorityCritical)
+//	async.LogContext(ctx, nfo.LogEntry{Cmd: "grant-role", Args: []string{user, role}})
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// ContextPriority returns the priority stored by WithPriority.
+func ContextPriority(ctx context.Context) (Priority, bool) {
+	p, ok := ctx.Value(priorityKey{}).(Priority)
+	return p, ok && p != 0
+}
+
+// PriorityMetrics is implemented by Metrics that also count drops by
+// priority. The client calls EntriesDroppedByPriority next to
+// Metrics.EntriesDropped, once for each priority among the entries.
+type PriorityMetrics interface {
+	EntriesDroppedByPriority(n int, reason DropReason, priority Priority)
+}
+
+// priorityOf returns entry's priority, deriving it from the level if unset.
+func priorityOf(entry LogEntry) Priority {
+	if entry.Priority != 0 {
+		return entry.Priority
+	}
+	switch levelOf(entry) {
+	case LevelDebug:
+		return PriorityLow
+	case LevelError:
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// countByPriority reports entries to fn grouped by priority.
+func countByPriority(entries []LogEntry, fn func(n int, p Priority)) {
+	var counts [PriorityCritical + 1]int
+	for _, entry := range entries {
+		if p := priorityOf(entry); p >= PriorityLow && p <= PriorityCritical {
+			counts[p]++
+		}
+	}
+	for p, n := range counts {
+		if n > 0 {
+			fn(n, Priority(p))
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityOf(t *testing.T) {
+	failed := false
+	for _, tc := range []struct {
+		entry LogEntry
+		want  Priority
+	}{
+		{LogEntry{}, PriorityNormal},
+		{LogEntry{Level: LevelDebug}, PriorityLow},
+		{LogEntry{Level: LevelWarn}, PriorityNormal},
+		{LogEntry{Success: &failed}, PriorityHigh},
+		{LogEntry{Level: LevelDebug, Priority: PriorityCritical}, PriorityCritical},
+	} {
+		if got := priorityOf(tc.entry); got != tc.want {
+			t.Errorf("priorityOf(%+v) = %v, want %v", tc.entry, got, tc.want)
+		}
+	}
+	if p, err := ParsePriority("Critical"); err != nil || p != PriorityCritical || p.String() != "critical" {
+		t.Fatalf("ParsePriority = %v, %v", p, err)
+	}
+	if _, err := ParsePriority("urgent"); err == nil {
+		t.Fatal("expected an unknown priority to fail")
+	}
+}
+
+func queuedCmds(a *AsyncClient) string {
+	var s string
+	for _, e := range a.queue {
+		s += e.Cmd
+	}
+	return s
+}
+
+func TestAsyncClientPriorityDropNewest(t *testing.T) {
+	async := newAsyncClient(NewNfoClient("http://unused"), AsyncConfig{QueueSize: 3, Overflow: DropNewest})
+
+	async.Log(LogEntry{Cmd: "a", Level: LevelDebug})
+	async.Log(LogEntry{Cmd: "b"})
+	async.Log(LogEntry{Cmd: "c", Level: LevelDebug})
+	if err := async.Log(LogEntry{Cmd: "d"}); err != nil {
+		t.Fatalf("a normal entry should evict a low one: %v", err)
+	}
+	if err := async.Log(LogEntry{Cmd: "e", Level: LevelDebug}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+	if err := async.Log(LogEntry{Cmd: "f", Level: LevelError}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if got := queuedCmds(async); got != "bdf" {
+		t.Fatalf("queue = %s, want bdf", got)
+	}
+	if async.Dropped() != 3 || async.DroppedByPriority(PriorityLow) != 3 || async.DroppedByPriority(PriorityNormal) != 0 {
+		t.Fatalf("dropped = %d, low %d", async.Dropped(), async.DroppedByPriority(PriorityLow))
+	}
+}
+
+func TestAsyncClientPriorityDropOldest(t *testing.T) {
+	async := newAsyncClient(NewNfoClient("http://unused"), AsyncConfig{QueueSize: 3, Overflow: DropOldest})
+
+	async.Log(LogEntry{Cmd: "a", Priority: PriorityHigh})
+	async.Log(LogEntry{Cmd: "b"})
+	async.Log(LogEntry{Cmd: "c"})
+	async.Log(LogEntry{Cmd: "d"})
+	if got := queuedCmds(async); got != "acd" {
+		t.Fatalf("queue = %s, want acd", got)
+	}
+	if err := async.Log(LogEntry{Cmd: "e", Level: LevelDebug}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("a low entry should not evict normal ones: %v", err)
+	}
+	async.Log(LogEntry{Cmd: "f", Priority: PriorityHigh})
+	async.Log(LogEntry{Cmd: "g", Priority: PriorityHigh})
+	if got := queuedCmds(async); got != "afg" {
+		t.Fatalf("queue = %s, want afg", got)
+	}
+	if async.DroppedByPriority(PriorityNormal) != 3 || async.DroppedByPriority(PriorityLow) != 1 {
+		t.Fatalf("dropped normal %d, low %d", async.DroppedByPriority(PriorityNormal), async.DroppedByPriority(PriorityLow))
+	}
+}
+
+func TestAsyncClientCriticalWaits(t *testing.T) {
+	rec, srv := newRecorder(t)
+	async := NewAsyncClient(NewNfoClient(srv.URL), AsyncConfig{QueueSize: 1, FlushInterval: time.Hour, Overflow: DropNewest})
+
+	ctx := WithPriority(context.Background(), PriorityCritical)
+	done := make(chan error, 1)
+	go func() {
+		async.LogContext(ctx, LogEntry{Cmd: "grant"})
+		done <- async.LogContext(ctx, LogEntry{Cmd: "revoke"})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("LogContext: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("critical entry was never admitted")
+	}
+	if err := async.Log(LogEntry{Cmd: "noise"}); err != nil && !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Log: %v", err)
+	}
+	async.Close()
+	sent := make(map[string]bool)
+	for _, e := range rec.Entries() {
+		sent[e.Cmd] = true
+	}
+	if !sent["grant"] || !sent["revoke"] {
+		t.Fatalf("sent %v", sent)
+	}
+	if async.DroppedByPriority(PriorityCritical) != 0 {
+		t.Fatal("a critical entry was dropped")
+	}
+}
+
+func TestAsyncClientFlushesByPriority(t *testing.T) {
+	rec, srv := newRecorder(t)
+	async := newAsyncClient(NewNfoClient(srv.URL), AsyncConfig{})
+
+	async.Log(LogEntry{Cmd: "low", Level: LevelDebug})
+	async.Log(LogEntry{Cmd: "normal-1"})
+	async.Log(LogEntry{Cmd: "critical", Priority: PriorityCritical})
+	async.Log(LogEntry{Cmd: "error", Level: LevelError})
+	async.Log(LogEntry{Cmd: "normal-2"})
+	if err := async.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	var got []string
+	for _, e := range rec.Entries() {
+		got = append(got, e.Cmd)
+	}
+	want := []string{"critical", "error", "normal-1", "normal-2", "low"}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("sent %v, want %v", got, want)
+		}
+	}
+}
+
+// priorityMetrics counts drops by priority.
+type priorityMetrics struct {
+	nopMetrics
+	mu      sync.Mutex
+	dropped map[Priority]int
+}
+
+func (m *priorityMetrics) EntriesDroppedByPriority(n int, reason DropReason, p Priority) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if reason == DropQueueFull {
+		m.dropped[p] += n
+	}
+}
+
+func TestPriorityMetrics(t *testing.T) {
+	m := &priorityMetrics{dropped: make(map[Priority]int)}
+	async := newAsyncClient(NewClient("http://unused", WithMetrics(m)), AsyncConfig{QueueSize: 1, Overflow: DropNewest})
+
+	async.Log(LogEntry{Cmd: "a", Level: LevelDebug})
+	async.Log(LogEntry{Cmd: "b", Level: LevelDebug})
+	async.Log(LogEntry{Cmd: "c", Level: LevelError})
+	if m.dropped[PriorityLow] != 2 || len(m.dropped) != 1 {
+		t.Fatalf("dropped = %v", m.dropped)
+	}
+}
@@ -0,0 +1,45 @@
+package nfo
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// WithProxy sends requests through the HTTP(S) proxy at proxyURL, e.g.
+// "http://proxy.corp:3128", instead of the one named by HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY. Credentials in the URL are sent to the proxy
+// as Proxy-Authorization. An empty proxyURL connects directly, ignoring
+// the environment. An invalid URL makes every request fail.
+//
+// Like WithConnectionPool, it clones the *http.Transport of a client passed
+// to WithHTTPClient and has no effect on a Transport of any other type.
+func WithProxy(proxyURL string) Option {
+	return func(c *clientConfig) {
+		c.proxy = proxyFunc(proxyURL)
+	}
+}
+
+// proxyFunc returns an http.Transport.Proxy that always picks proxyURL.
+func proxyFunc(proxyURL string) func(*http.Request) (*url.URL, error) {
+	if proxyURL == "" {
+		return func(*http.Request) (*url.URL, error) { return nil, nil }
+	}
+	u, err := parseProxyURL(proxyURL)
+	if err != nil {
+		err = fmt.Errorf("nfo: %w", err)
+	}
+	return func(*http.Request) (*url.URL, error) { return u, err }
+}
+
+func parseProxyURL(proxyURL string) (*url.URL, error) {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q", proxyURL)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+		return u, nil
+	}
+	return nil, fmt.Errorf("invalid proxy URL %q: unsupported scheme %q", proxyURL, u.Scheme)
+}
@@ -0,0 +1,39 @@
+package nfo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		if r.Header.Get("Proxy-Authorization") == "" {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+		}
+	}))
+	defer proxy.Close()
+
+	user := strings.Replace(proxy.URL, "http://", "http://u:p@", 1)
+	client := NewClient("http://nfo.internal:8080", WithProxy(user))
+	if err := client.Log(LogEntry{Cmd: "a"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://nfo.internal:8080/log" {
+		t.Fatalf("proxied requests: %v", proxied)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://nfo.internal", nil)
+	direct := NewClient("http://nfo.internal", WithProxy(""))
+	if u, err := direct.HTTPClient.Transport.(*http.Transport).Proxy(req); u != nil || err != nil {
+		t.Fatalf("WithProxy(\"\") should connect directly, got %v %v", u, err)
+	}
+
+	bad := NewClient("http://nfo.internal", WithProxy("proxy.corp:3128"))
+	if err := bad.Log(LogEntry{Cmd: "a"}); err == nil || !strings.Contains(err.Error(), "invalid proxy URL") {
+		t.Fatalf("expected an invalid proxy error, got %v", err)
+	}
+}
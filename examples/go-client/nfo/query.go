@@ -0,0 +1,122 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// DefaultPageSize is the page size QueryAll uses when QueryParams.Limit is 0.
+const DefaultPageSize = 100
+
+// QueryParams filters the entries returned by Query. Zero values are not
+// sent, so the service applies its own defaults.
+type QueryParams struct {
+	Cmd           string
+	Env           string
+	Success       *bool
+	Level         Level
+	CorrelationID string
+	Since         time.Time
+	Until         time.Time
+	Limit         int
+	Offset        int
+}
+
+// values encodes p as a /logs query string.
+func (p QueryParams) values() url.Values {
+	v := url.Values{}
+	if p.Cmd != "" {
+		v.Set("cmd", p.Cmd)
+	}
+	if p.Env != "" {
+		v.Set("env", p.Env)
+	}
+	if p.Success != nil {
+		v.Set("success", strconv.FormatBool(*p.Success))
+	}
+	if p.Level != 0 {
+		v.Set("level", p.Level.String())
+	}
+	if p.CorrelationID != "" {
+		v.Set("correlation_id", p.CorrelationID)
+	}
+	if !p.Since.IsZero() {
+		v.Set("since", p.Since.UTC().Format(time.RFC3339Nano))
+	}
+	if !p.Until.IsZero() {
+		v.Set("until", p.Until.UTC().Format(time.RFC3339Nano))
+	}
+	if p.Limit > 0 {
+		v.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset > 0 {
+		v.Set("offset", strconv.Itoa(p.Offset))
+	}
+	return v
+}
+
+// Query fetches one page of entries from nfo-service's /logs endpoint.
+func (c *NfoClient) Query(ctx context.Context, params QueryParams) ([]LogEntry, error) {
+	if err := c.negotiate(ctx).require(func(caps Capabilities) bool { return caps.Query }, "query"); err != nil {
+		return nil, err
+	}
+	path := "/logs"
+	if q := params.values().Encode(); q != "" {
+		path += "?" + q
+	}
+	data, err := c.do(ctx, http.MethodGet, path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []LogEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	return entries, nil
+}
+
+// QueryAll iterates over every entry matching params, fetching pages of
+// params.Limit entries (DefaultPageSize if unset) starting at params.Offset.
+// Iteration stops after a short or empty page, a page identical to the
+// previous one (a service that ignores offset), or the first error, which
+// is yielded with a zero LogEntry.
+//
+//	for entry, err := range client.QueryAll(ctx, nfo.QueryParams{Env: "prod"}) {
+//	    if err != nil { ... }
+//	}
+func (c *NfoClient) QueryAll(ctx context.Context, params QueryParams) iter.Seq2[LogEntry, error] {
+	return func(yield func(LogEntry, error) bool) {
+		if params.Limit <= 0 {
+			params.Limit = DefaultPageSize
+		}
+		var prev []LogEntry
+		for {
+			page, err := c.Query(ctx, params)
+			if err != nil {
+				yield(LogEntry{}, err)
+				return
+			}
+			if len(page) == 0 || reflect.DeepEqual(page, prev) {
+				return
+			}
+			prev = page
+			for _, entry := range page {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+			if len(page) < params.Limit {
+				return
+			}
+			params.Offset += len(page)
+		}
+	}
+}
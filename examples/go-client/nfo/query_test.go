@@ -0,0 +1,122 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newLogsServer serves n stored entries from GET /logs honouring limit/offset.
+func newLogsServer(t *testing.T, n int, seen func(r *http.Request)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if seen != nil {
+			seen(r)
+		}
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		if limit == 0 {
+			limit = 50
+		}
+		page := []LogEntry{}
+		for i := offset; i < n && i < offset+limit; i++ {
+			page = append(page, LogEntry{Cmd: fmt.Sprintf("cmd-%d", i)})
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestQueryParams(t *testing.T) {
+	var got http.Header
+	var query string
+	srv := newLogsServer(t, 3, func(r *http.Request) {
+		got = r.Header
+		query = r.URL.RawQuery
+	})
+	client := NewClient(srv.URL, WithHeaders(map[string]string{"X-Team": "infra"}))
+
+	failed := false
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries, err := client.Query(context.Background(), QueryParams{
+		Cmd:           "deploy",
+		Env:           "prod",
+		Success:       &failed,
+		CorrelationID: "job-7",
+		Since:         since,
+		Limit:         10,
+	})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(entries))
+	}
+	want := "cmd=deploy&correlation_id=job-7&env=prod&limit=10&since=2024-01-02T03%3A04%3A05Z&success=false"
+	if query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	if got.Get("X-Team") != "infra" {
+		t.Errorf("headers not applied to query: %v", got)
+	}
+}
+
+func TestQueryAllPaginates(t *testing.T) {
+	requests := 0
+	srv := newLogsServer(t, 7, func(*http.Request) { requests++ })
+	client := NewClient(srv.URL)
+
+	var cmds []string
+	for entry, err := range client.QueryAll(context.Background(), QueryParams{Limit: 3}) {
+		if err != nil {
+			t.Fatalf("QueryAll: %v", err)
+		}
+		cmds = append(cmds, entry.Cmd)
+	}
+	if len(cmds) != 7 || cmds[6] != "cmd-6" {
+		t.Fatalf("unexpected entries: %v", cmds)
+	}
+	if requests != 3 {
+		t.Fatalf("expected 3 page requests, got %d", requests)
+	}
+}
+
+func TestQueryAllStopsEarly(t *testing.T) {
+	requests := 0
+	srv := newLogsServer(t, 100, func(*http.Request) { requests++ })
+	client := NewClient(srv.URL)
+
+	for range client.QueryAll(context.Background(), QueryParams{Limit: 10}) {
+		break
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 page request after break, got %d", requests)
+	}
+}
+
+func TestQueryAllIgnoredOffset(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		json.NewEncoder(w).Encode([]LogEntry{{Cmd: "a"}, {Cmd: "b"}})
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL)
+
+	n := 0
+	for _, err := range client.QueryAll(context.Background(), QueryParams{Limit: 2}) {
+		if err != nil {
+			t.Fatalf("QueryAll: %v", err)
+		}
+		n++
+	}
+	if n != 2 || requests != 2 {
+		t.Fatalf("got %d entries in %d requests, want 2 in 2", n, requests)
+	}
+}
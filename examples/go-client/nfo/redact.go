@@ -0,0 +1,142 @@
+package nfo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactMask replaces redacted values unless RedactConfig.Hash is set.
+const RedactMask = "[REDACTED]"
+
+// DefaultRedactPatterns are the detectors WithRedaction applies unless
+// RedactConfig.NoDefaults is set. When a pattern has a capture group only
+// the group is redacted, so "password=hunter2" becomes
+// "password=[REDACTED]".
+var DefaultRedactPatterns = []*regexp.Regexp{
+	// AWS access key IDs.
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),
+	// AWS secret access keys in key=value form.
+	regexp.MustCompile(`(?i)aws_secret_access_key["']?\s*[=:]\s*["']?([A-Za-z0-9/+=]{40})`),
+	// Bearer tokens in headers or arguments.
+	regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]+=*)`),
+	// Passwords, secrets, tokens and API keys in key=value form.
+	regexp.MustCompile(`(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key)["']?\s*[=:]\s*["']?([^\s"'&,;]+)`),
+	// Email addresses.
+	regexp.MustCompile(`\b[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}\b`),
+}
+
+// RedactConfig configures WithRedaction.
+type RedactConfig struct {
+	// Patterns are additional regular expressions to redact in Cmd, Args,
+	// Output, Error and string Fields values.
+	Patterns []*regexp.Regexp
+	// Fields are keys of LogEntry.Fields whose values are always
+	// redacted, whatever their type (e.g. "password", "card_number").
+	// Matching is case-insensitive.
+	Fields []string
+	// Hash replaces values with "sha256:" and the first 12 hex digits of
+	// their SHA-256 digest instead of RedactMask, so equal values can
+	// still be correlated across entries.
+	Hash bool
+	// NoDefaults disables DefaultRedactPatterns.
+	NoDefaults bool
+}
+
+// WithRedaction scrubs secrets and PII from every entry before it is
+// queued, spilled or serialized. Defaults, host metadata and trace IDs
+// added by the client are not scanned.
+func WithRedaction(cfg RedactConfig) Option {
+	return func(c *clientConfig) {
+		r := &redactor{hash: cfg.Hash, fields: make(map[string]bool, len(cfg.Fields))}
+		if !cfg.NoDefaults {
+			r.patterns = append(r.patterns, DefaultRedactPatterns...)
+		}
+		r.patterns = append(r.patterns, cfg.Patterns...)
+		for _, f := range cfg.Fields {
+			r.fields[strings.ToLower(f)] = true
+		}
+		c.client.redactor = r
+	}
+}
+
+// redactor applies a RedactConfig.
+type redactor struct {
+	patterns []*regexp.Regexp
+	fields   map[string]bool
+	hash     bool
+}
+
+// entry returns a redacted copy of e. The caller's Args and Fields are not
+// modified.
+func (r *redactor) entry(e LogEntry) LogEntry {
+	e.Cmd = r.text(e.Cmd)
+	e.Output = r.text(e.Output)
+	e.Error = r.text(e.Error)
+	if e.Args != nil {
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = r.text(arg)
+		}
+		e.Args = args
+	}
+	if e.Fields != nil {
+		fields := make(map[string]any, len(e.Fields))
+		for k, v := range e.Fields {
+			if r.fields[strings.ToLower(k)] {
+				v = r.value(fmt.Sprint(v))
+			} else if s, ok := v.(string); ok {
+				v = r.text(s)
+			}
+			fields[k] = v
+		}
+		e.Fields = fields
+	}
+	return e
+}
+
+// text redacts every pattern match in s.
+func (r *redactor) text(s string) string {
+	if s == "" {
+		return s
+	}
+	for _, re := range r.patterns {
+		if re.NumSubexp() == 0 {
+			s = re.ReplaceAllStringFunc(s, r.value)
+			continue
+		}
+		s = replaceGroup(re, s, r.value)
+	}
+	return s
+}
+
+// value returns the replacement for a secret.
+func (r *redactor) value(secret string) string {
+	if !r.hash {
+		return RedactMask
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+// replaceGroup replaces the first capture group of every match of re in s.
+func replaceGroup(re *regexp.Regexp, s string, repl func(string) string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		start, end := m[2], m[3]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(repl(s[start:end]))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
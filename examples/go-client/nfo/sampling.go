@@ -0,0 +1,138 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRateLimited is returned when an entry exceeds the client's rate limit
+// and RateLimitConfig.Block is false.
+var ErrRateLimited = errors.New("nfo: rate limited")
+
+// WithSampling keeps one in every n successful entries and every failure
+// (Success false or Error set). Sampled-out entries are discarded without
+// an error and reported to Metrics as DropSampled. n <= 1 keeps everything.
+func WithSampling(n int) Option {
+	return func(cfg *clientConfig) {
+		if n > 1 {
+			cfg.client.sampler = &sampler{every: uint64(n)}
+		} else {
+			cfg.client.sampler = nil
+		}
+	}
+}
+
+// RateLimitConfig configures WithRateLimit.
+type RateLimitConfig struct {
+	// PerSecond is the sustained number of entries allowed per second.
+	PerSecond float64
+	// Burst is how many entries may be sent at once after an idle period
+	// (default 1).
+	Burst int
+	// Block makes callers wait for capacity instead of failing with
+	// ErrRateLimited. The wait honours the context passed to LogContext.
+	Block bool
+}
+
+// WithRateLimit caps how fast entries leave the client with a token
+// bucket. Failures are limited like any other entry; combine with
+// WithSampling to thin out successes first. A PerSecond <= 0 disables it.
+func WithRateLimit(cfg RateLimitConfig) Option {
+	return func(c *clientConfig) {
+		if cfg.PerSecond <= 0 {
+			c.client.limiter = nil
+			return
+		}
+		if cfg.Burst < 1 {
+			cfg.Burst = 1
+		}
+		c.client.limiter = &limiter{
+			rate:   cfg.PerSecond,
+			burst:  float64(cfg.Burst),
+			tokens: float64(cfg.Burst),
+			block:  cfg.Block,
+			now:    time.Now,
+		}
+	}
+}
+
+// admit applies sampling and rate limiting to entry. It reports false with
+// a nil error for sampled-out entries.
+func (c *NfoClient) admit(ctx context.Context, entry LogEntry) (bool, error) {
+	if c.sampler != nil && !c.sampler.keep(entry) {
+		c.discard(DropSampled, c.redacted(entry))
+		return false, nil
+	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			c.discard(DropRateLimited, c.redacted(entry))
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// sampler keeps one in every `every` successful entries; failures always pass.
+type sampler struct {
+	every uint64
+	n     atomic.Uint64
+}
+
+func (s *sampler) keep(entry LogEntry) bool {
+	if failed(entry) {
+		return true
+	}
+	return s.n.Add(1)%s.every == 1
+}
+
+// limiter is a token bucket refilled at rate tokens per second.
+type limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	block  bool
+	now    func() time.Time
+}
+
+// wait takes a token, sleeping for one if the limiter blocks.
+func (l *limiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return nil
+	}
+	if !l.block {
+		l.mu.Unlock()
+		return ErrRateLimited
+	}
+	// Reserve a future token so concurrent waiters queue up fairly.
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
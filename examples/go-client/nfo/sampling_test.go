@@ -0,0 +1,92 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSamplingKeepsFailures(t *testing.T) {
+	rec, srv := newRecorder(t)
+	m := newCountingMetrics()
+	client := NewClient(srv.URL, WithSampling(3), WithMetrics(m))
+
+	ok, failed := true, false
+	for i := 0; i < 6; i++ {
+		if err := client.Log(LogEntry{Cmd: "ok", Success: &ok}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	client.Log(LogEntry{Cmd: "failed", Success: &failed})
+	client.Log(LogEntry{Cmd: "error", Error: "boom"})
+
+	if got := len(rec.Entries()); got != 4 {
+		t.Fatalf("expected 2 sampled successes and 2 failures, got %d entries", got)
+	}
+	if m.dropped[DropSampled] != 4 {
+		t.Fatalf("dropped = %v", m.dropped)
+	}
+}
+
+func TestRateLimitDrop(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithRateLimit(RateLimitConfig{PerSecond: 1, Burst: 2}))
+	now := time.Now()
+	client.limiter.now = func() time.Time { return now }
+
+	client.Log(LogEntry{Cmd: "a"})
+	client.Log(LogEntry{Cmd: "b"})
+	if err := client.Log(LogEntry{Cmd: "c"}); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+
+	now = now.Add(time.Second)
+	err := client.LogBatch([]LogEntry{{Cmd: "d"}, {Cmd: "e"}, {Cmd: "f"}})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited from batch, got %v", err)
+	}
+	if got := len(rec.Entries()); got != 3 {
+		t.Fatalf("expected 3 entries through, got %d", got)
+	}
+}
+
+func TestRateLimitBlock(t *testing.T) {
+	l := &limiter{rate: 100, burst: 1, tokens: 1, block: true, now: time.Now}
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("expected to wait for tokens, took %v", elapsed)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	frozen := time.Now()
+	l.now = func() time.Time { return frozen }
+	l.last, l.tokens = frozen, -10
+	if err := l.wait(cancelled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if l.tokens != -10 {
+		t.Fatalf("cancelled wait must return its token, tokens = %v", l.tokens)
+	}
+}
+
+func TestAsyncAdmission(t *testing.T) {
+	_, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithSampling(2))
+	async := newAsyncClient(client, AsyncConfig{})
+
+	for i := 0; i < 4; i++ {
+		async.Log(LogEntry{Cmd: "x"})
+	}
+	if async.Len() != 2 {
+		t.Fatalf("sampled-out entries must not be queued, len = %d", async.Len())
+	}
+}
@@ -0,0 +1,145 @@
+package nfo
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScopedLogger is a lightweight view of an NfoClient or AsyncClient that
+// stamps its own defaults onto every entry: fields, an environment and a
+// Cmd prefix. It shares the parent's connections, queue and options, so
+// creating one is cheap; hand one to each subsystem instead of repeating
+// its metadata at every call:
+//
+//	billing := client.WithCmdPrefix("billing.").With("team", "payments")
+//	invoices := billing.With("component", "invoices")
+//	invoices.Log(nfo.LogEntry{Cmd: "send"}) // cmd "billing.send", both fields
+//
+// Values set on an entry win over the scope's, and the scope's win over the
+// client's WithFields and WithEnv. A ScopedLogger is immutable and safe for
+// concurrent use.
+type ScopedLogger struct {
+	parent    Logger
+	fields    map[string]any
+	env       string
+	cmdPrefix string
+}
+
+var _ Logger = (*ScopedLogger)(nil)
+
+// With returns a logger that adds fields, given as alternating keys and
+// values, to every entry: With("user", id, "region", "eu"). A final key
+// without a value is recorded under "!BADKEY", as log/slog does.
+func (c *NfoClient) With(fields ...any) *ScopedLogger {
+	return (&ScopedLogger{parent: c}).With(fields...)
+}
+
+// WithEnv returns a logger that sets Env on entries that have none.
+func (c *NfoClient) WithEnv(env string) *ScopedLogger {
+	return &ScopedLogger{parent: c, env: env}
+}
+
+// WithCmdPrefix returns a logger that prepends prefix to every Cmd.
+func (c *NfoClient) WithCmdPrefix(prefix string) *ScopedLogger {
+	return &ScopedLogger{parent: c, cmdPrefix: prefix}
+}
+
+// With returns a logger that adds fields to every entry it enqueues; see
+// NfoClient.With.
+func (a *AsyncClient) With(fields ...any) *ScopedLogger {
+	return (&ScopedLogger{parent: a}).With(fields...)
+}
+
+// WithEnv returns a logger that sets Env on entries that have none.
+func (a *AsyncClient) WithEnv(env string) *ScopedLogger {
+	return &ScopedLogger{parent: a, env: env}
+}
+
+// WithCmdPrefix returns a logger that prepends prefix to every Cmd.
+func (a *AsyncClient) WithCmdPrefix(prefix string) *ScopedLogger {
+	return &ScopedLogger{parent: a, cmdPrefix: prefix}
+}
+
+// With returns a child logger with fields added to s's; see NfoClient.With.
+// The child's value wins for keys set by both.
+func (s *ScopedLogger) With(fields ...any) *ScopedLogger {
+	child := *s
+	child.fields = mergeFields(pairs(fields), s.fields)
+	return &child
+}
+
+// WithEnv returns a child logger with its environment replaced by env.
+func (s *ScopedLogger) WithEnv(env string) *ScopedLogger {
+	child := *s
+	child.env = env
+	return &child
+}
+
+// WithCmdPrefix returns a child logger whose Cmd prefix is s's followed by
+// prefix.
+func (s *ScopedLogger) WithCmdPrefix(prefix string) *ScopedLogger {
+	child := *s
+	child.cmdPrefix = s.cmdPrefix + prefix
+	return &child
+}
+
+// Log stamps entry with the scope's defaults and passes it to the parent.
+func (s *ScopedLogger) Log(entry LogEntry) error {
+	return s.parent.Log(s.stamp(entry))
+}
+
+// LogContext is Log with a context, used for trace and correlation IDs and
+// to bound the send.
+func (s *ScopedLogger) LogContext(ctx context.Context, entry LogEntry) error {
+	return logContext(ctx, s.parent, s.stamp(entry))
+}
+
+// Debugf logs a formatted message at LevelDebug.
+func (s *ScopedLogger) Debugf(format string, args ...any) error {
+	return s.Log(levelEntry(LevelDebug, format, args))
+}
+
+// Infof logs a formatted message at LevelInfo.
+func (s *ScopedLogger) Infof(format string, args ...any) error {
+	return s.Log(levelEntry(LevelInfo, format, args))
+}
+
+// Warnf logs a formatted message at LevelWarn.
+func (s *ScopedLogger) Warnf(format string, args ...any) error {
+	return s.Log(levelEntry(LevelWarn, format, args))
+}
+
+// Errorf logs a formatted message at LevelError as a failed entry.
+func (s *ScopedLogger) Errorf(format string, args ...any) error {
+	return s.Log(levelEntry(LevelError, format, args))
+}
+
+// stamp applies the scope's defaults to a copy of entry.
+func (s *ScopedLogger) stamp(entry LogEntry) LogEntry {
+	entry.Cmd = s.cmdPrefix + entry.Cmd
+	if entry.Env == "" {
+		entry.Env = s.env
+	}
+	entry.Fields = mergeFields(entry.Fields, s.fields)
+	return entry
+}
+
+// pairs converts alternating keys and values to a map.
+func pairs(kv []any) map[string]any {
+	if len(kv) == 0 {
+		return nil
+	}
+	fields := make(map[string]any, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			fields["!BADKEY"] = kv[i]
+			break
+		}
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		fields[key] = kv[i+1]
+	}
+	return fields
+}
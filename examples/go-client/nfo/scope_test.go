@@ -0,0 +1,53 @@
+package nfo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestScopedLogger(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithEnv("prod"), WithFields(map[string]any{"service": "api", "team": "core"}))
+
+	billing := client.WithCmdPrefix("billing.").With("team", "payments")
+	invoices := billing.With("component", "invoices").WithEnv("staging")
+
+	fields := map[string]any{"component": "override"}
+	invoices.Log(LogEntry{Cmd: "send", Fields: fields})
+	billing.LogContext(WithCorrelationID(context.Background(), "req-1"), LogEntry{Cmd: "charge", Env: "test"})
+	client.With("odd").Warnf("disk %d%% full", 91)
+
+	got := rec.Entries()
+	if len(got) != 3 {
+		t.Fatalf("got %d entries", len(got))
+	}
+	if e := got[0]; e.Cmd != "billing.send" || e.Env != "staging" || e.Fields["team"] != "payments" ||
+		e.Fields["component"] != "override" || e.Fields["service"] != "api" {
+		t.Fatalf("unexpected scoped entry: %+v", e)
+	}
+	if len(fields) != 1 {
+		t.Fatalf("caller's fields modified: %v", fields)
+	}
+	if e := got[1]; e.Cmd != "billing.charge" || e.Env != "test" || e.CorrelationID != "req-1" || e.Fields["component"] != nil {
+		t.Fatalf("child scope leaked into parent: %+v", e)
+	}
+	if e := got[2]; e.Cmd != "disk 91% full" || e.Level != LevelWarn || e.Env != "prod" || e.Fields["!BADKEY"] != "odd" {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+}
+
+func TestScopedAsyncLogger(t *testing.T) {
+	rec, srv := newRecorder(t)
+	async := NewAsyncClient(NewClient(srv.URL), AsyncConfig{})
+	worker := async.WithCmdPrefix("worker/").With("shard", 3)
+
+	if _, err := Call(context.Background(), worker, "resize", nil, func() (int, error) { return 1, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := async.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.Entries(); len(got) != 1 || got[0].Cmd != "worker/resize" || got[0].Fields["shard"] != float64(3) {
+		t.Fatalf("entries = %+v", got)
+	}
+}
@@ -0,0 +1,249 @@
+package nfo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// ErrSpillFull is returned when entries do not fit under the spill size cap.
+var ErrSpillFull = errors.New("nfo: spill queue full")
+
+// DefaultSpillMaxBytes caps a spill file when OpenSpill is given no limit.
+const DefaultSpillMaxBytes = 64 << 20
+
+// spillFile is the name of the append-only file inside the spill directory.
+const spillFile = "nfo-spill.jsonl"
+
+// Spill is a disk-backed queue of entries that could not be delivered.
+//
+// Entries are appended to a single file, one per line, each prefixed with a
+// CRC32 of its JSON encoding and, if set, its Priority. Lines that are truncated or fail their checksum
+// (e.g. after a crash mid-write) are skipped when the file is opened.
+type Spill struct {
+	path     string
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	count   int
+	dropped uint64
+}
+
+// OpenSpill opens (or creates) the spill queue in dir, keeping at most
+// maxBytes on disk (DefaultSpillMaxBytes if maxBytes <= 0). Corrupt records
+// left by a previous run are discarded.
+func OpenSpill(dir string, maxBytes int64) (*Spill, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultSpillMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("spill: %w", err)
+	}
+	s := &Spill{path: filepath.Join(dir, spillFile), maxBytes: maxBytes}
+
+	entries, corrupt, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	if corrupt {
+		if err := s.rewrite(entries); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	s.count = len(entries)
+	if info, err := os.Stat(s.path); err == nil {
+		s.size = info.Size()
+	}
+	return s, nil
+}
+
+// Append stores entries at the end of the queue. Entries that would push the
+// file past its size cap are dropped and ErrSpillFull is returned.
+func (s *Spill) Append(entries []LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var buf bytes.Buffer
+	kept := 0
+	for _, entry := range entries {
+		line, err := encodeSpillLine(entry)
+		if err != nil {
+			return err
+		}
+		if s.size+int64(buf.Len()+len(line)) > s.maxBytes {
+			break
+		}
+		buf.Write(line)
+		kept++
+	}
+
+	if buf.Len() > 0 {
+		f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("spill: %w", err)
+		}
+		n, err := f.Write(buf.Bytes())
+		s.size += int64(n)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return fmt.Errorf("spill: %w", err)
+		}
+		s.count += kept
+	}
+
+	if kept < len(entries) {
+		s.dropped += uint64(len(entries) - kept)
+		return fmt.Errorf("%w: dropped %d entries", ErrSpillFull, len(entries)-kept)
+	}
+	return nil
+}
+
+// Replay hands every spilled entry to send and keeps only the entries send
+// reports as failed.
+func (s *Spill) Replay(send func([]LogEntry) ([]LogEntry, error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 {
+		return nil
+	}
+	entries, _, err := s.load()
+	if err != nil {
+		return err
+	}
+	failed, sendErr := send(entries)
+	if err := s.rewrite(failed); err != nil {
+		return errors.Join(sendErr, err)
+	}
+	return sendErr
+}
+
+// Len reports the number of entries waiting on disk.
+func (s *Spill) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Dropped reports how many entries were rejected by the size cap.
+func (s *Spill) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// load reads every intact record and reports whether any were corrupt.
+func (s *Spill) load() ([]LogEntry, bool, error) {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("spill: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		entries []LogEntry
+		corrupt bool
+	)
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			entry, ok := decodeSpillLine(line)
+			if ok {
+				entries = append(entries, entry)
+			}
+			if !ok || line[len(line)-1] != '\n' {
+				corrupt = true
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	return entries, corrupt, nil
+}
+
+// rewrite atomically replaces the spill file with entries.
+func (s *Spill) rewrite(entries []LogEntry) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := encodeSpillLine(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("spill: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("spill: %w", err)
+	}
+	s.size = int64(buf.Len())
+	s.count = len(entries)
+	return nil
+}
+
+// encodeSpillLine formats entry as "<crc32 hex> <json>\n", or as
+// "<crc32 hex> p<priority> <json>\n" if it has a Priority, which the JSON
+// encoding leaves out. The checksum covers everything after the first
+// space.
+func encodeSpillLine(entry LogEntry) ([]byte, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+	if entry.Priority != 0 {
+		data = append(fmt.Appendf(nil, "p%d ", entry.Priority), data...)
+	}
+	line := fmt.Appendf(nil, "%08x ", crc32.ChecksumIEEE(data))
+	line = append(line, data...)
+	return append(line, '\n'), nil
+}
+
+// decodeSpillLine parses a record, rejecting truncated or altered lines.
+func decodeSpillLine(line []byte) (LogEntry, bool) {
+	var entry LogEntry
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	if len(line) < 10 || line[8] != ' ' {
+		return entry, false
+	}
+	var sum uint32
+	if _, err := fmt.Sscanf(string(line[:8]), "%08x", &sum); err != nil {
+		return entry, false
+	}
+	data := line[9:]
+	if crc32.ChecksumIEEE(data) != sum {
+		return entry, false
+	}
+	var priority Priority
+	if data[0] == 'p' {
+		prefix, rest, ok := bytes.Cut(data, []byte{' '})
+		n, err := strconv.Atoi(string(prefix[1:]))
+		if !ok || err != nil {
+			return entry, false
+		}
+		priority, data = Priority(n), rest
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return entry, false
+	}
+	entry.Priority = priority
+	return entry, true
+}
@@ -0,0 +1,143 @@
+package nfo
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSpillAppendReplay(t *testing.T) {
+	spill, err := OpenSpill(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("OpenSpill: %v", err)
+	}
+	if err := spill.Append([]LogEntry{{Cmd: "a"}, {Cmd: "b"}, {Cmd: "c"}}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	var sent []string
+	err = spill.Replay(func(entries []LogEntry) ([]LogEntry, error) {
+		for _, e := range entries {
+			sent = append(sent, e.Cmd)
+		}
+		return entries[2:], errors.New("c failed")
+	})
+	if err == nil || len(sent) != 3 {
+		t.Fatalf("Replay sent %v, err %v", sent, err)
+	}
+	if spill.Len() != 1 {
+		t.Fatalf("expected 1 entry left, got %d", spill.Len())
+	}
+}
+
+func TestSpillKeepsPriority(t *testing.T) {
+	entries := []LogEntry{{Cmd: "a", Priority: PriorityCritical}, {Cmd: "b"}, {Cmd: "c", Priority: PriorityLow}}
+	dir := t.TempDir()
+	spill, _ := OpenSpill(dir, 0)
+	if err := spill.Append(entries); err != nil {
+		t.Fatal(err)
+	}
+	spill, _ = OpenSpill(dir, 0)
+	var got []LogEntry
+	spill.Replay(func(replayed []LogEntry) ([]LogEntry, error) {
+		got = append(got, replayed...)
+		return nil, nil
+	})
+	if len(got) != 3 || got[0].Priority != PriorityCritical || got[1].Priority != 0 || got[2].Priority != PriorityLow {
+		t.Fatalf("replayed %+v", got)
+	}
+
+	// A record whose priority was altered fails its checksum.
+	line, _ := encodeSpillLine(entries[0])
+	if _, ok := decodeSpillLine(bytes.Replace(line, []byte(" p4 "), []byte(" p1 "), 1)); ok {
+		t.Fatal("altered priority accepted")
+	}
+}
+
+func TestSpillPersistsAcrossOpen(t *testing.T) {
+	dir := t.TempDir()
+	first, _ := OpenSpill(dir, 0)
+	first.Append([]LogEntry{{Cmd: "a"}, {Cmd: "b"}})
+
+	second, err := OpenSpill(dir, 0)
+	if err != nil {
+		t.Fatalf("OpenSpill: %v", err)
+	}
+	if second.Len() != 2 {
+		t.Fatalf("expected 2 entries after reopen, got %d", second.Len())
+	}
+}
+
+func TestSpillRecoversFromCorruption(t *testing.T) {
+	dir := t.TempDir()
+	spill, _ := OpenSpill(dir, 0)
+	spill.Append([]LogEntry{{Cmd: "good"}})
+
+	path := filepath.Join(dir, spillFile)
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString("deadbeef {\"cmd\":\"tampered\"}\n")
+	f.WriteString("garbage\n")
+	f.WriteString("0000") // torn write
+	f.Close()
+
+	spill, err := OpenSpill(dir, 0)
+	if err != nil {
+		t.Fatalf("OpenSpill: %v", err)
+	}
+	if spill.Len() != 1 {
+		t.Fatalf("expected only the intact record, got %d", spill.Len())
+	}
+	spill.Append([]LogEntry{{Cmd: "after"}})
+
+	var cmds []string
+	spill.Replay(func(entries []LogEntry) ([]LogEntry, error) {
+		for _, e := range entries {
+			cmds = append(cmds, e.Cmd)
+		}
+		return nil, nil
+	})
+	if len(cmds) != 2 || cmds[0] != "good" || cmds[1] != "after" {
+		t.Fatalf("unexpected replay: %v", cmds)
+	}
+}
+
+func TestSpillSizeCap(t *testing.T) {
+	spill, _ := OpenSpill(t.TempDir(), 80)
+
+	err := spill.Append([]LogEntry{{Cmd: "a"}, {Cmd: "b"}, {Cmd: "c"}})
+	if !errors.Is(err, ErrSpillFull) {
+		t.Fatalf("expected ErrSpillFull, got %v", err)
+	}
+	if spill.Len()+int(spill.Dropped()) != 3 || spill.Dropped() == 0 {
+		t.Fatalf("len=%d dropped=%d", spill.Len(), spill.Dropped())
+	}
+}
+
+func TestAsyncClientSpillsAndReplays(t *testing.T) {
+	rec, srv := newRecorder(t)
+	spill, _ := OpenSpill(t.TempDir(), 0)
+	async := NewAsyncClient(NewClient(srv.URL), AsyncConfig{FlushInterval: time.Hour, Spill: spill})
+	defer async.Close()
+
+	rec.SetStatus(http.StatusServiceUnavailable)
+	async.Log(LogEntry{Cmd: "offline"})
+	if err := async.Flush(); err != nil {
+		t.Fatalf("Flush while offline should spill, got %v", err)
+	}
+	if spill.Len() != 1 {
+		t.Fatalf("expected 1 spilled entry, got %d", spill.Len())
+	}
+
+	rec.SetStatus(http.StatusOK)
+	async.Log(LogEntry{Cmd: "online"})
+	if err := async.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if spill.Len() != 0 || len(rec.Entries()) != 2 {
+		t.Fatalf("spill=%d delivered=%d", spill.Len(), len(rec.Entries()))
+	}
+}
@@ -0,0 +1,90 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// StatsGroups lists the fields Stats can group entries by.
+var StatsGroups = []string{"cmd", "env", "language", "level"}
+
+// StatsParams selects the entries summarised by Stats and how they are
+// grouped. Zero values are not sent.
+type StatsParams struct {
+	// GroupBy is the field entries are grouped by, one of StatsGroups
+	// (default "cmd").
+	GroupBy string
+	Cmd     string
+	Env     string
+	Since   time.Time
+	Until   time.Time
+}
+
+// values encodes p as a /stats query string.
+func (p StatsParams) values() url.Values {
+	v := url.Values{}
+	if p.GroupBy != "" {
+		v.Set("group_by", p.GroupBy)
+	}
+	if p.Cmd != "" {
+		v.Set("cmd", p.Cmd)
+	}
+	if p.Env != "" {
+		v.Set("env", p.Env)
+	}
+	if !p.Since.IsZero() {
+		v.Set("since", p.Since.UTC().Format(time.RFC3339Nano))
+	}
+	if !p.Until.IsZero() {
+		v.Set("until", p.Until.UTC().Format(time.RFC3339Nano))
+	}
+	return v
+}
+
+// GroupStats summarises the entries sharing one value of the grouped field.
+// An entry counts as failed if it has Success=false or LevelError.
+// Duration percentiles cover only entries with a DurationMs and are 0 if
+// there are none.
+type GroupStats struct {
+	Key         string  `json:"key"`
+	Count       int     `json:"count"`
+	Failures    int     `json:"failures"`
+	SuccessRate float64 `json:"success_rate"`
+	DurationP50 float64 `json:"duration_p50_ms"`
+	DurationP90 float64 `json:"duration_p90_ms"`
+	DurationP99 float64 `json:"duration_p99_ms"`
+}
+
+// Stats fetches per-group counts, success rates and duration percentiles
+// from nfo-service's /stats endpoint, so dashboards can chart failure rates
+// without pulling every entry. Groups are ordered by descending Count.
+//
+//	groups, err := client.Stats(ctx, nfo.StatsParams{
+//	    GroupBy: "cmd",
+//	    Env:     "prod",
+//	    Since:   time.Now().Add(-24 * time.Hour),
+//	})
+func (c *NfoClient) Stats(ctx context.Context, params StatsParams) ([]GroupStats, error) {
+	if params.GroupBy != "" && !slices.Contains(StatsGroups, params.GroupBy) {
+		return nil, fmt.Errorf("nfo: cannot group stats by %q, want one of %v", params.GroupBy, StatsGroups)
+	}
+	path := "/stats"
+	if q := params.values().Encode(); q != "" {
+		path += "?" + q
+	}
+	data, err := c.do(ctx, http.MethodGet, path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []GroupStats
+	if err := json.Unmarshal(data, &groups); err != nil {
+		return nil, fmt.Errorf("unmarshal: %w", err)
+	}
+	return groups, nil
+}
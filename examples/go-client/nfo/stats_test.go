@@ -0,0 +1,44 @@
+package nfo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stats" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.RawQuery
+		w.Write([]byte(`[{"key": "deploy", "count": 4, "failures": 1, "success_rate": 0.75,
+			"duration_p50_ms": 120, "duration_p90_ms": 300, "duration_p99_ms": 310.5}]`))
+	}))
+	defer srv.Close()
+	client := NewClient(srv.URL)
+
+	groups, err := client.Stats(context.Background(), StatsParams{
+		GroupBy: "cmd",
+		Env:     "prod",
+		Since:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if want := "env=prod&group_by=cmd&since=2024-01-02T03%3A04%3A05Z"; query != want {
+		t.Errorf("query = %q, want %q", query, want)
+	}
+	want := GroupStats{Key: "deploy", Count: 4, Failures: 1, SuccessRate: 0.75, DurationP50: 120, DurationP90: 300, DurationP99: 310.5}
+	if len(groups) != 1 || groups[0] != want {
+		t.Fatalf("groups = %+v", groups)
+	}
+
+	if _, err := client.Stats(context.Background(), StatsParams{GroupBy: "host"}); err == nil {
+		t.Fatal("expected an error for an unsupported GroupBy")
+	}
+}
@@ -0,0 +1,163 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// syslogSeverity maps levels to syslog severities.
+var syslogSeverity = map[Level]int{
+	LevelDebug: 7,
+	LevelInfo:  6,
+	LevelWarn:  4,
+	LevelError: 3,
+}
+
+// SyslogConfig configures a SyslogTransport. Zero values select the defaults.
+type SyslogConfig struct {
+	// Facility is the syslog facility code (default 16, local0).
+	Facility int
+	// AppName is the APP-NAME field (default the binary name).
+	AppName string
+	// Hostname is used when an entry has no Meta.Hostname
+	// (default os.Hostname).
+	Hostname string
+}
+
+// SyslogTransport sends entries as RFC 5424 syslog messages. Over stream
+// networks ("tcp", "unix") messages use RFC 6587 octet-counting framing;
+// over "udp" and "unixgram" each message is one datagram.
+//
+// Cmd becomes the MSGID, env, language, success and duration_ms go into
+// an [nfo@32473 ...] structured-data element, and MSG is the JSON entry.
+// The entry's level selects the severity: debug, info, warning or error.
+type SyslogTransport struct {
+	cfg    SyslogConfig
+	stream bool
+	conn   *conn
+	now    func() time.Time
+}
+
+// NewSyslogTransport returns a transport sending to a syslog receiver at
+// addr. It connects on first use.
+func NewSyslogTransport(network, addr string, cfg SyslogConfig) *SyslogTransport {
+	if cfg.Facility == 0 {
+		cfg.Facility = 16
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = filepath.Base(os.Args[0])
+	}
+	if cfg.Hostname == "" {
+		cfg.Hostname, _ = os.Hostname()
+	}
+	return &SyslogTransport{
+		cfg:    cfg,
+		stream: network == "tcp" || network == "tcp4" || network == "tcp6" || network == "unix",
+		conn:   &conn{network: network, addr: addr},
+		now:    time.Now,
+	}
+}
+
+// Send writes one syslog message per entry.
+func (t *SyslogTransport) Send(ctx context.Context, entries []LogEntry) error {
+	frames := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		msg, err := t.format(entry)
+		if err != nil {
+			return err
+		}
+		if t.stream {
+			msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+		}
+		frames = append(frames, msg)
+	}
+	return t.conn.write(ctx, frames)
+}
+
+// Close closes the connection.
+func (t *SyslogTransport) Close() error {
+	return t.conn.Close()
+}
+
+// format renders entry as an RFC 5424 message.
+func (t *SyslogTransport) format(entry LogEntry) ([]byte, error) {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	severity := syslogSeverity[levelOf(entry)]
+	hostname, pid := t.cfg.Hostname, os.Getpid()
+	if entry.Meta != nil {
+		if entry.Meta.Hostname != "" {
+			hostname = entry.Meta.Hostname
+		}
+		if entry.Meta.PID != 0 {
+			pid = entry.Meta.PID
+		}
+	}
+
+	var sd strings.Builder
+	sd.WriteString("[nfo@32473")
+	param := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&sd, ` %s="%s"`, name, sdEscape(value))
+		}
+	}
+	param("env", entry.Env)
+	param("language", entry.Language)
+	if entry.Success != nil {
+		param("success", strconv.FormatBool(*entry.Success))
+	}
+	if entry.DurationMs != nil {
+		param("duration_ms", strconv.FormatFloat(*entry.DurationMs, 'f', -1, 64))
+	}
+	param("trace_id", entry.TraceID)
+	sd.WriteString("]")
+
+	// The event time survives queueing, retries and spill replay; entries
+	// without one are stamped on send.
+	ts := t.now()
+	if entry.Timestamp != nil {
+		ts = *entry.Timestamp
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s %s ",
+		t.cfg.Facility*8+severity,
+		ts.Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogField(hostname, 255),
+		syslogField(t.cfg.AppName, 48),
+		pid,
+		syslogField(entry.Cmd, 32),
+		sd.String(),
+	)
+	return append([]byte(header), body...), nil
+}
+
+// syslogField makes s a valid header field: printable ASCII without
+// spaces, at most max bytes, or "-" when empty.
+func syslogField(s string, max int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if len(s) > max {
+		s = s[:max]
+	}
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// sdEscape escapes a structured-data parameter value.
+func sdEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
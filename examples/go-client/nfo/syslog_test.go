@@ -0,0 +1,72 @@
+package nfo
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogFormat(t *testing.T) {
+	tr := NewSyslogTransport("udp", "127.0.0.1:514", SyslogConfig{AppName: "svc", Hostname: "edge-1"})
+	tr.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	failed := false
+	msg, err := tr.format(LogEntry{Cmd: "deploy app", Env: "prod", Success: &failed, Error: `bad "quote"]`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := regexp.MustCompile(`^<131>1 2024-05-01T12:00:00\.000000Z edge-1 svc \d+ deploy_app ` +
+		`\[nfo@32473 env="prod" success="false"\] \{"cmd":"deploy app"`)
+	if !want.Match(msg) {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+
+	logged := time.Date(2024, 5, 1, 11, 59, 30, 250000000, time.UTC)
+	msg, _ = tr.format(LogEntry{Cmd: "a", Timestamp: &logged})
+	if !strings.HasPrefix(string(msg), "<134>1 2024-05-01T11:59:30.250000Z ") {
+		t.Fatalf("header should carry the entry's timestamp: %s", msg)
+	}
+
+	ok := true
+	msg, _ = tr.format(LogEntry{Success: &ok, Meta: &Metadata{Hostname: "pod-7", PID: 42}})
+	if !strings.HasPrefix(string(msg), "<134>1 ") || !strings.Contains(string(msg), " pod-7 svc 42 - ") {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+}
+
+func TestSyslogOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	frames := make(chan string, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		r := bufio.NewReader(c)
+		prefix, _ := r.ReadString(' ')
+		n, _ := strconv.Atoi(strings.TrimSpace(prefix))
+		buf := make([]byte, n)
+		io.ReadFull(r, buf)
+		frames <- string(buf)
+	}()
+
+	tr := NewSyslogTransport("tcp", ln.Addr().String(), SyslogConfig{})
+	defer tr.Close()
+	if err := tr.Send(context.Background(), []LogEntry{{Cmd: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if frame := <-frames; !strings.HasPrefix(frame, "<134>1 ") || !strings.HasSuffix(frame, "}") {
+		t.Fatalf("unexpected frame: %q", frame)
+	}
+}
@@ -0,0 +1,222 @@
+package nfo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Reconnect delays used by TailLogs. The service can override the initial
+// delay with an SSE "retry" field.
+const (
+	DefaultTailRetry = time.Second
+	MaxTailRetry     = 30 * time.Second
+)
+
+// TailFilter selects the entries TailLogs delivers. Zero values match
+// everything.
+type TailFilter struct {
+	Cmd     string
+	Env     string
+	Success *bool
+	// MinLevel drops entries below this level.
+	MinLevel Level
+	// Cursor resumes the stream after the entry whose LogEntry.Cursor it
+	// is. Entries the service no longer buffers are skipped.
+	Cursor string
+	// OnError, if set, receives errors that interrupt the stream.
+	OnError func(error)
+}
+
+// values encodes f as a /logs/stream query string.
+func (f TailFilter) values() url.Values {
+	v := url.Values{}
+	if f.Cmd != "" {
+		v.Set("cmd", f.Cmd)
+	}
+	if f.Env != "" {
+		v.Set("env", f.Env)
+	}
+	if f.Success != nil {
+		v.Set("success", strconv.FormatBool(*f.Success))
+	}
+	if f.MinLevel != 0 {
+		v.Set("min_level", f.MinLevel.String())
+	}
+	return v
+}
+
+// TailLogs follows new entries through nfo-service's Server-Sent Events
+// endpoint, /logs/stream. The first connection is made before TailLogs
+// returns; after that, dropped connections are re-established with
+// backoff, resuming from the last received entry. The channel is closed
+// when ctx is done or the service rejects the stream with a status that
+// is not worth retrying.
+//
+//	entries, err := client.TailLogs(ctx, nfo.TailFilter{MinLevel: nfo.LevelWarn})
+//	for entry := range entries {
+//	    fmt.Println(entry.Cmd, entry.Error)
+//	}
+func (c *NfoClient) TailLogs(ctx context.Context, filter TailFilter) (<-chan LogEntry, error) {
+	if err := c.negotiate(ctx).require(func(caps Capabilities) bool { return caps.Streaming }, "streaming"); err != nil {
+		return nil, err
+	}
+	hc := *c.HTTPClient
+	hc.Timeout = 0 // the stream stays open; ctx bounds it instead
+	t := &tail{client: c, hc: &hc, filter: filter, cursor: filter.Cursor, retry: DefaultTailRetry}
+
+	body, err := t.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries := make(chan LogEntry)
+	go t.run(ctx, body, entries)
+	return entries, nil
+}
+
+// tail is the state of one TailLogs subscription.
+type tail struct {
+	client *NfoClient
+	hc     *http.Client
+	filter TailFilter
+	cursor string
+	retry  time.Duration
+}
+
+func (t *tail) run(ctx context.Context, body io.ReadCloser, entries chan<- LogEntry) {
+	defer close(entries)
+
+	for {
+		err := t.read(ctx, body, entries)
+		body.Close()
+
+		delay := t.retry
+		for {
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				t.report(err)
+				if !retryable(err) {
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			if body, err = t.connect(ctx); err == nil {
+				break
+			}
+			delay = min(2*delay, MaxTailRetry)
+		}
+	}
+}
+
+// connect opens the event stream, resuming after t.cursor.
+func (t *tail) connect(ctx context.Context) (io.ReadCloser, error) {
+	c := t.client
+	path := "/logs/stream"
+	if q := t.filter.values().Encode(); q != "" {
+		path += "?" + q
+	}
+	base := c.baseURL(ctx)
+	req, err := c.newRequest(ctx, base, http.MethodGet, path, "", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if t.cursor != "" {
+		req.Header.Set("Last-Event-ID", t.cursor)
+	}
+
+	resp, err := t.hc.Do(req)
+	if err != nil {
+		err = &transportError{op: "get", err: err}
+	} else if resp.StatusCode != http.StatusOK {
+		err = newAPIError(resp)
+		resp.Body.Close()
+	}
+	if c.failover != nil {
+		c.failover.record(base, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// read delivers the events of one connection until it ends. A clean end
+// of stream returns nil.
+func (t *tail) read(ctx context.Context, body io.Reader, entries chan<- LogEntry) error {
+	r := bufio.NewReader(body)
+	var (
+		event string
+		data  []string
+	)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if len(data) > 0 && (event == "" || event == "log") {
+				if !t.dispatch(ctx, strings.Join(data, "\n"), entries) {
+					return nil
+				}
+			}
+			event, data = "", nil
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			t.cursor = value
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+				t.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// dispatch decodes one log event and hands it to the caller. It reports
+// false once ctx is done.
+func (t *tail) dispatch(ctx context.Context, data string, entries chan<- LogEntry) bool {
+	var entry LogEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		t.report(fmt.Errorf("unmarshal: %w", err))
+		return true
+	}
+	entry.Cursor = t.cursor
+	select {
+	case entries <- entry:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (t *tail) report(err error) {
+	if t.filter.OnError != nil {
+		t.filter.OnError(err)
+	}
+}
@@ -0,0 +1,153 @@
+package nfo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sseServer streams scripted events: connection n writes the events in
+// conns[n] and then ends the response.
+type sseServer struct {
+	mu       sync.Mutex
+	conns    [][]string
+	lastIDs  []string
+	queries  []string
+	statuses []int
+}
+
+func (s *sseServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	n := len(s.lastIDs)
+	s.lastIDs = append(s.lastIDs, r.Header.Get("Last-Event-ID"))
+	s.queries = append(s.queries, r.URL.RawQuery)
+	var events []string
+	if n < len(s.conns) {
+		events = s.conns[n]
+	}
+	status := http.StatusOK
+	if n < len(s.statuses) && s.statuses[n] != 0 {
+		status = s.statuses[n]
+	}
+	s.mu.Unlock()
+
+	if r.URL.Path != "/logs/stream" || r.Header.Get("Accept") != "text/event-stream" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	fmt.Fprint(w, "retry: 1\n\n")
+	w.(http.Flusher).Flush()
+	for _, event := range events {
+		fmt.Fprint(w, event)
+	}
+	if events == nil {
+		<-r.Context().Done() // hold the stream open until the client leaves
+	}
+}
+
+func logEvent(id, cmd string) string {
+	return fmt.Sprintf("id: %s\nevent: log\ndata: {\"cmd\":%q,\"level\":\"info\"}\n\n", id, cmd)
+}
+
+func TestTailLogsResumes(t *testing.T) {
+	srv := &sseServer{conns: [][]string{
+		{logEvent("1", "a"), ": keep-alive\n\n", "event: ping\ndata: {}\n\n", logEvent("2", "b")},
+		{logEvent("3", "c")},
+	}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	entries, err := NewClient(ts.URL).TailLogs(ctx, TailFilter{Env: "prod", MinLevel: LevelWarn})
+	if err != nil {
+		t.Fatalf("TailLogs: %v", err)
+	}
+
+	var got []string
+	for entry := range entries {
+		got = append(got, entry.Cmd+"@"+entry.Cursor)
+		if len(got) == 3 {
+			cancel()
+		}
+	}
+	if fmt.Sprint(got) != "[a@1 b@2 c@3]" {
+		t.Fatalf("entries = %v", got)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.lastIDs[0] != "" || srv.lastIDs[1] != "2" {
+		t.Fatalf("Last-Event-ID headers = %q", srv.lastIDs)
+	}
+	if srv.queries[0] != "env=prod&min_level=warn" {
+		t.Fatalf("query = %q", srv.queries[0])
+	}
+}
+
+func TestTailLogsCursor(t *testing.T) {
+	srv := &sseServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if _, err := NewClient(ts.URL).TailLogs(ctx, TailFilter{Cursor: "41"}); err != nil {
+		t.Fatalf("TailLogs: %v", err)
+	}
+	cancel()
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.lastIDs[0] != "41" {
+		t.Fatalf("Last-Event-ID = %q", srv.lastIDs[0])
+	}
+}
+
+func TestTailLogsErrors(t *testing.T) {
+	srv := &sseServer{statuses: []int{http.StatusNotFound}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	_, err := NewClient(ts.URL).TailLogs(context.Background(), TailFilter{})
+	var ae *APIError
+	if !errors.As(err, &ae) || ae.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 from the first connection, got %v", err)
+	}
+
+	// A retryable failure on reconnect is reported and retried; a
+	// permanent one closes the channel.
+	srv = &sseServer{
+		conns:    [][]string{{logEvent("1", "a"), "data: not json\n\n"}},
+		statuses: []int{0, http.StatusServiceUnavailable, http.StatusForbidden},
+	}
+	ts2 := httptest.NewServer(srv)
+	defer ts2.Close()
+
+	var errs []error
+	entries, err := NewClient(ts2.URL).TailLogs(context.Background(), TailFilter{
+		OnError: func(err error) { errs = append(errs, err) },
+	})
+	if err != nil {
+		t.Fatalf("TailLogs: %v", err)
+	}
+	var n int
+	for range entries {
+		n++
+	}
+	if n != 1 || len(errs) != 3 {
+		t.Fatalf("entries=%d errors=%v", n, errs)
+	}
+	if !errors.As(errs[2], &ae) || ae.StatusCode != http.StatusForbidden {
+		t.Fatalf("last error = %v", errs[2])
+	}
+}
@@ -0,0 +1,26 @@
+package nfo
+
+import "time"
+
+// WithClock makes the client read the time for entry timestamps from now
+// instead of time.Now, e.g. to get fixed timestamps in tests.
+func WithClock(now func() time.Time) Option {
+	return func(cfg *clientConfig) {
+		if now != nil {
+			cfg.client.clock = now
+		}
+	}
+}
+
+// stamp sets entry's Timestamp from now unless it has one, and converts it
+// to UTC.
+func stamp(entry LogEntry, now func() time.Time) LogEntry {
+	var t time.Time
+	if entry.Timestamp != nil {
+		t = entry.Timestamp.UTC()
+	} else {
+		t = now().UTC()
+	}
+	entry.Timestamp = &t
+	return entry
+}
@@ -0,0 +1,64 @@
+package nfo
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestamp(t *testing.T) {
+	rec, srv := newRecorder(t)
+	now := time.Date(2026, 3, 1, 10, 30, 0, 123456789, time.FixedZone("CET", 3600))
+	client := NewClient(srv.URL, WithClock(func() time.Time { return now }))
+
+	client.Log(LogEntry{Cmd: "stamped"})
+	if !strings.Contains(string(rec.Body()), `"timestamp":"2026-03-01T09:30:00.123456789Z"`) {
+		t.Fatalf("expected an RFC 3339 UTC timestamp, got %s", rec.Body())
+	}
+
+	earlier := now.Add(-time.Hour)
+	client.Log(LogEntry{Cmd: "preset", Timestamp: &earlier})
+	got := rec.Entries()[1].Timestamp
+	if got == nil || !got.Equal(earlier) || got.Location() != time.UTC {
+		t.Fatalf("preset timestamp = %v, want %v in UTC", got, earlier)
+	}
+}
+
+func TestTimestampSurvivesSpillReplay(t *testing.T) {
+	rec, srv := newRecorder(t)
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	logged := now
+	client := NewClient(srv.URL, WithClock(func() time.Time { return now }))
+	spill, _ := OpenSpill(t.TempDir(), 0)
+	async := newAsyncClient(client, AsyncConfig{Spill: spill})
+
+	rec.SetStatus(http.StatusServiceUnavailable)
+	async.Log(LogEntry{Cmd: "offline"})
+	async.Flush()
+
+	now = now.Add(time.Hour)
+	rec.SetStatus(http.StatusOK)
+	if err := async.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	got := rec.Entries()
+	if len(got) != 1 || !got[0].Timestamp.Equal(logged) {
+		t.Fatalf("replayed entry lost its timestamp: %+v", got)
+	}
+}
+
+func TestMultiSinkSharesTimestamp(t *testing.T) {
+	var a, b bytes.Buffer
+	sink := NewMultiSink(Route{Sink: NewWriterSink(&a)}, Route{Sink: NewWriterSink(&b)})
+	sink.Log(LogEntry{Cmd: "x"})
+
+	var ea, eb LogEntry
+	json.Unmarshal(a.Bytes(), &ea)
+	json.Unmarshal(b.Bytes(), &eb)
+	if ea.Timestamp == nil || eb.Timestamp == nil || !ea.Timestamp.Equal(*eb.Timestamp) {
+		t.Fatalf("sinks saw different timestamps: %v, %v", ea.Timestamp, eb.Timestamp)
+	}
+}
@@ -0,0 +1,136 @@
+package nfo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSConfig describes the TLS settings for an nfo-service that uses a
+// private CA or requires client certificates. Load turns it into a
+// *tls.Config for WithTLSConfig.
+type TLSConfig struct {
+	// CAFile is a PEM bundle of the CAs that sign nfo-service's
+	// certificate. Empty trusts the system roots.
+	CAFile string
+	// CertFile and KeyFile are the PEM client certificate and private key
+	// presented for mutual TLS. Both or neither must be set.
+	CertFile string
+	KeyFile  string
+	// MinVersion is the oldest TLS version accepted (default TLS 1.2).
+	MinVersion uint16
+	// ServerName overrides the host name checked against the server
+	// certificate.
+	ServerName string
+}
+
+// Load reads the CA bundle and client key pair and returns the resulting
+// *tls.Config, or an error if a file is missing or invalid.
+//
+// The client certificate is reloaded when CertFile or KeyFile changes, so
+// certificates rotated in place by cert-manager, Vault agent and the like
+// are picked up by the next connection without restarting. If the new pair
+// cannot be loaded, for instance because the key has not been written yet,
+// the previous one is kept. The CA bundle is read only once.
+//
+//	tc, err := nfo.TLSConfig{
+//	    CAFile:   "/etc/nfo/ca.pem",
+//	    CertFile: "/etc/nfo/client.pem",
+//	    KeyFile:  "/etc/nfo/client-key.pem",
+//	}.Load()
+//	if err != nil {
+//	    return err
+//	}
+//	client := nfo.NewClient(url, nfo.WithTLSConfig(tc))
+func (cfg TLSConfig) Load() (*tls.Config, error) {
+	tc, err := cfg.load()
+	if err != nil {
+		return nil, fmt.Errorf("nfo: tls: %w", err)
+	}
+	return tc, nil
+}
+
+func (cfg TLSConfig) load() (*tls.Config, error) {
+	tc := &tls.Config{MinVersion: cfg.MinVersion, ServerName: cfg.ServerName}
+	if tc.MinVersion == 0 {
+		tc.MinVersion = tls.VersionTLS12
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		tc.RootCAs = x509.NewCertPool()
+		if !tc.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+	}
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, errors.New("a client certificate needs both a cert and a key file")
+	}
+	if cfg.CertFile != "" {
+		kp := &keyPair{certFile: cfg.CertFile, keyFile: cfg.KeyFile}
+		if _, err := kp.certificate(); err != nil {
+			return nil, err
+		}
+		tc.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := kp.certificate()
+			if err != nil {
+				return nil, fmt.Errorf("nfo: tls: %w", err)
+			}
+			return cert, nil
+		}
+	}
+	return tc, nil
+}
+
+// WithTLSConfig sets the TLS configuration used to connect to an https://
+// nfo-service, e.g. one returned by TLSConfig.Load. tc is cloned. Like
+// WithConnectionPool, it clones the *http.Transport of a client passed to
+// WithHTTPClient and has no effect on a Transport of any other type.
+func WithTLSConfig(tc *tls.Config) Option {
+	return func(c *clientConfig) {
+		if tc != nil {
+			c.tls = tc.Clone()
+		}
+	}
+}
+
+// keyPair caches a client certificate and reloads it when its files change.
+type keyPair struct {
+	certFile, keyFile string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime [2]time.Time
+}
+
+// certificate returns the current key pair, reloading it if either file
+// has been modified since it was last read.
+func (kp *keyPair) certificate() (*tls.Certificate, error) {
+	var modTime [2]time.Time
+	for i, name := range []string{kp.certFile, kp.keyFile} {
+		if fi, err := os.Stat(name); err == nil {
+			modTime[i] = fi.ModTime()
+		}
+	}
+
+	kp.mu.Lock()
+	defer kp.mu.Unlock()
+	if kp.cert != nil && modTime == kp.modTime {
+		return kp.cert, nil
+	}
+	cert, err := tls.LoadX509KeyPair(kp.certFile, kp.keyFile)
+	if err != nil {
+		if kp.cert != nil {
+			return kp.cert, nil // mid-rotation; retry on the next handshake
+		}
+		return nil, fmt.Errorf("load client certificate: %w", err)
+	}
+	kp.cert, kp.modTime = &cert, modTime
+	return kp.cert, nil
+}
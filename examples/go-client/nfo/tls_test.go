@@ -0,0 +1,133 @@
+package nfo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeClientCert writes a self-signed client certificate for cn and its
+// key to dir and returns the certificate.
+func writeClientCert(t *testing.T, dir, cn string) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, "client.pem"), "CERTIFICATE", der)
+	writePEM(t, filepath.Join(dir, "client-key.pem"), "EC PRIVATE KEY", keyDER)
+	cert, _ := x509.ParseCertificate(der)
+	return cert
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(writeClientCert(t, dir, "job-a"))
+
+	var mu sync.Mutex
+	var seen []string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.TLS.PeerCertificates[0].Subject.CommonName)
+		mu.Unlock()
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", srv.Certificate().Raw)
+
+	if err := NewClient(srv.URL).Log(LogEntry{Cmd: "untrusted"}); err == nil {
+		t.Fatal("expected the server certificate to be rejected without the CA")
+	}
+
+	tc, err := TLSConfig{
+		CAFile:   filepath.Join(dir, "ca.pem"),
+		CertFile: filepath.Join(dir, "client.pem"),
+		KeyFile:  filepath.Join(dir, "client-key.pem"),
+	}.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("MinVersion = %x", tc.MinVersion)
+	}
+	client := NewClient(srv.URL, WithTLSConfig(tc))
+	if err := client.Log(LogEntry{Cmd: "a"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+
+	// Rotate the client certificate in place; the next connection uses it.
+	clientCAs.AddCert(writeClientCert(t, dir, "job-b"))
+	later := time.Now().Add(time.Minute)
+	for _, name := range []string{"client.pem", "client-key.pem"} {
+		os.Chtimes(filepath.Join(dir, name), later, later)
+	}
+	client.HTTPClient.CloseIdleConnections()
+	if err := client.Log(LogEntry{Cmd: "b"}); err != nil {
+		t.Fatalf("Log after rotation: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 2 || seen[0] != "job-a" || seen[1] != "job-b" {
+		t.Fatalf("client certificates seen: %v", seen)
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.pem")
+	os.WriteFile(empty, nil, 0o600)
+
+	tests := map[string]TLSConfig{
+		"read CA bundle":      {CAFile: filepath.Join(dir, "missing.pem")},
+		"no certificates":     {CAFile: empty},
+		"both a cert and":     {CertFile: empty},
+		"client certificate:": {CertFile: empty, KeyFile: empty},
+	}
+	for want, cfg := range tests {
+		if _, err := cfg.Load(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%+v: error %v, want %q", cfg, err, want)
+		}
+	}
+}
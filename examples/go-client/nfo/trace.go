@@ -0,0 +1,76 @@
+package nfo
+
+import (
+	"context"
+	"fmt"
+)
+
+// TraceContext identifies the distributed-trace span an entry belongs to.
+// IDs are lowercase hex, 32 characters for TraceID and 16 for SpanID.
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+// Valid reports whether both IDs are present.
+func (t TraceContext) Valid() bool {
+	return len(t.TraceID) == 32 && len(t.SpanID) == 16
+}
+
+// Traceparent formats t as a W3C Trace Context traceparent header value.
+func (t TraceContext) Traceparent() string {
+	flags := 0
+	if t.Sampled {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", t.TraceID, t.SpanID, flags)
+}
+
+// TraceExtractor pulls the current span out of a context. The nfootel
+// module provides one backed by OpenTelemetry.
+type TraceExtractor func(ctx context.Context) (TraceContext, bool)
+
+type traceKey struct{}
+
+// ContextWithTrace returns a copy of ctx carrying tc, for code that
+// propagates trace IDs without an OpenTelemetry SDK.
+func ContextWithTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, tc)
+}
+
+// TraceFromContext returns the TraceContext stored by ContextWithTrace.
+// It is the client's default TraceExtractor.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceKey{}).(TraceContext)
+	return tc, ok && tc.Valid()
+}
+
+// WithTraceExtractor replaces TraceFromContext as the source of trace IDs.
+// Context-aware calls (LogContext, Call, CapturePanic, RunCommand, Query)
+// stamp trace_id/span_id on the entry and send a traceparent header.
+func WithTraceExtractor(fn TraceExtractor) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.traceExtractor = fn
+	}
+}
+
+// trace returns the span active in ctx, if any.
+func (c *NfoClient) trace(ctx context.Context) (TraceContext, bool) {
+	if c.traceExtractor == nil {
+		return TraceContext{}, false
+	}
+	tc, ok := c.traceExtractor(ctx)
+	return tc, ok && tc.Valid()
+}
+
+// stampTrace links entry to the span in ctx unless it already names one.
+func (c *NfoClient) stampTrace(ctx context.Context, entry LogEntry) LogEntry {
+	if entry.TraceID != "" {
+		return entry
+	}
+	if tc, ok := c.trace(ctx); ok {
+		entry.TraceID, entry.SpanID = tc.TraceID, tc.SpanID
+	}
+	return entry
+}
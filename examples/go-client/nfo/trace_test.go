@@ -0,0 +1,77 @@
+package nfo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var testTrace = TraceContext{
+	TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+	SpanID:  "00f067aa0ba902b7",
+	Sampled: true,
+}
+
+func TestTraceparent(t *testing.T) {
+	want := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	if got := testTrace.Traceparent(); got != want {
+		t.Fatalf("Traceparent = %q, want %q", got, want)
+	}
+}
+
+func TestLogContextStampsTrace(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	ctx := ContextWithTrace(context.Background(), testTrace)
+	if err := client.LogContext(ctx, LogEntry{Cmd: "build"}); err != nil {
+		t.Fatalf("LogContext: %v", err)
+	}
+	e := rec.Entries()[0]
+	if e.TraceID != testTrace.TraceID || e.SpanID != testTrace.SpanID {
+		t.Fatalf("trace not stamped: %+v", e)
+	}
+	if got := rec.Header().Get("traceparent"); got != testTrace.Traceparent() {
+		t.Fatalf("traceparent header = %q", got)
+	}
+}
+
+func TestLogWithoutTrace(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	client.Log(LogEntry{Cmd: "build"})
+	if e := rec.Entries()[0]; e.TraceID != "" || rec.Header().Get("traceparent") != "" {
+		t.Fatalf("unexpected trace data: %+v", e)
+	}
+}
+
+func TestWithTraceExtractor(t *testing.T) {
+	mem := &memLogger{}
+	client := NewClient("http://unused", WithTraceExtractor(func(context.Context) (TraceContext, bool) {
+		return testTrace, true
+	}))
+	async := newAsyncClient(client, AsyncConfig{})
+
+	async.LogContext(context.Background(), LogEntry{Cmd: "queued"})
+	if async.queue[0].TraceID != testTrace.TraceID {
+		t.Fatalf("async entry not stamped at enqueue time: %+v", async.queue[0])
+	}
+
+	Call(context.Background(), mem, "plain", nil, func() (int, error) { return 1, nil })
+	if mem.Entries()[0].TraceID != "" {
+		t.Fatal("plain loggers have no extractor")
+	}
+}
+
+func TestRunCommandLogsAfterTimeout(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client.RunCommand(ctx, "sleep", "5")
+	if len(rec.Entries()) != 1 {
+		t.Fatal("command killed by its context should still be logged")
+	}
+}
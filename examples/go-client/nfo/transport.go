@@ -0,0 +1,154 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Transport delivers prepared log entries to a collector. The client
+// applies defaults, metadata, retries and the circuit breaker before
+// calling Send; an error fails the whole slice.
+//
+// The default transport is HTTP to nfo-service. Transports that hold
+// connections also implement io.Closer.
+type Transport interface {
+	Send(ctx context.Context, entries []LogEntry) error
+}
+
+// WithTransport replaces HTTP with t for Log and LogBatch. Queries still
+// use HTTP. WithFlattenFields and WithCompression only affect HTTP.
+func WithTransport(t Transport) Option {
+	return func(cfg *clientConfig) {
+		cfg.client.transport = t
+	}
+}
+
+// deliver sends entries through the configured transport.
+func (c *NfoClient) deliver(ctx context.Context, entries []LogEntry) error {
+	return c.guard(ctx, func(ctx context.Context) (err error) {
+		start := time.Now()
+		defer func() { c.metrics.RequestCompleted(time.Since(start), err) }()
+		return c.transport.Send(ctx, entries)
+	})
+}
+
+// MaxDatagramSize is the largest entry UDPTransport can send.
+const MaxDatagramSize = 65507
+
+// ErrTooLarge is returned when an encoded entry does not fit in a datagram.
+var ErrTooLarge = errors.New("nfo: entry too large for datagram")
+
+// UDPTransport sends each entry as one JSON datagram. Delivery is not
+// acknowledged, so lost datagrams are not detected.
+type UDPTransport struct {
+	conn *conn
+}
+
+// NewUDPTransport returns a transport sending to addr ("host:port").
+func NewUDPTransport(addr string) (*UDPTransport, error) {
+	if _, err := net.ResolveUDPAddr("udp", addr); err != nil {
+		return nil, fmt.Errorf("udp: %w", err)
+	}
+	return &UDPTransport{conn: &conn{network: "udp", addr: addr}}, nil
+}
+
+// Send writes one datagram per entry.
+func (t *UDPTransport) Send(ctx context.Context, entries []LogEntry) error {
+	frames := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+		if len(data) > MaxDatagramSize {
+			return ErrTooLarge
+		}
+		frames = append(frames, data)
+	}
+	return t.conn.write(ctx, frames)
+}
+
+// Close releases the socket.
+func (t *UDPTransport) Close() error {
+	return t.conn.Close()
+}
+
+// UnixTransport streams newline-delimited JSON over a Unix domain socket.
+// It connects on first use and reconnects after a failed write.
+type UnixTransport struct {
+	conn *conn
+}
+
+// NewUnixTransport returns a transport writing to the socket at path.
+func NewUnixTransport(path string) *UnixTransport {
+	return &UnixTransport{conn: &conn{network: "unix", addr: path}}
+}
+
+// Send writes one JSON line per entry.
+func (t *UnixTransport) Send(ctx context.Context, entries []LogEntry) error {
+	frames := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+		frames = append(frames, append(data, '\n'))
+	}
+	return t.conn.write(ctx, frames)
+}
+
+// Close closes the connection.
+func (t *UnixTransport) Close() error {
+	return t.conn.Close()
+}
+
+// conn is a lazily dialled connection shared by the socket transports.
+type conn struct {
+	network string
+	addr    string
+
+	mu sync.Mutex
+	c  net.Conn
+}
+
+// write sends frames in order, dialling first if needed. After an error
+// the connection is dropped so the next write redials.
+func (w *conn) write(ctx context.Context, frames [][]byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.c == nil {
+		var d net.Dialer
+		c, err := d.DialContext(ctx, w.network, w.addr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", w.network, err)
+		}
+		w.c = c
+	}
+	deadline, _ := ctx.Deadline()
+	w.c.SetWriteDeadline(deadline)
+	for _, frame := range frames {
+		if _, err := w.c.Write(frame); err != nil {
+			w.c.Close()
+			w.c = nil
+			return fmt.Errorf("%s: %w", w.network, err)
+		}
+	}
+	return nil
+}
+
+func (w *conn) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.c == nil {
+		return nil
+	}
+	err := w.c.Close()
+	w.c = nil
+	return err
+}
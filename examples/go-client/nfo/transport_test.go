@@ -0,0 +1,129 @@
+package nfo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memTransport records entries and fails the first failN sends.
+type memTransport struct {
+	mu      sync.Mutex
+	sends   [][]LogEntry
+	failN   int
+	attempt int
+}
+
+func (m *memTransport) Send(_ context.Context, entries []LogEntry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.attempt++
+	if m.failN > 0 {
+		m.failN--
+		return errors.New("unreachable")
+	}
+	m.sends = append(m.sends, entries)
+	return nil
+}
+
+func TestWithTransport(t *testing.T) {
+	tr := &memTransport{failN: 1}
+	client := NewClient("http://unused", WithTransport(tr), WithRetry(2, time.Millisecond),
+		WithEnv("test"), WithBatchLimits(2, 0))
+
+	if err := client.Log(LogEntry{Cmd: "single"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if tr.attempt != 2 {
+		t.Fatalf("expected a retry, got %d attempts", tr.attempt)
+	}
+	if err := client.LogBatch([]LogEntry{{Cmd: "a"}, {Cmd: "b"}, {Cmd: "c"}}); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+
+	if len(tr.sends) != 3 || len(tr.sends[1]) != 2 || len(tr.sends[2]) != 1 {
+		t.Fatalf("unexpected sends: %v", tr.sends)
+	}
+	if got := tr.sends[0][0]; got.Env != "test" || got.Language != "go" || got.Meta == nil {
+		t.Fatalf("entry was not prepared: %+v", got)
+	}
+}
+
+func TestUDPTransport(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	tr, err := NewUDPTransport(pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+	if err := tr.Send(context.Background(), []LogEntry{{Cmd: "a"}, {Cmd: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, MaxDatagramSize)
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for _, want := range []string{"a", "b"} {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got LogEntry
+		if err := json.Unmarshal(buf[:n], &got); err != nil || got.Cmd != want {
+			t.Fatalf("datagram %q: %v", buf[:n], err)
+		}
+	}
+
+	big := LogEntry{Output: string(make([]byte, MaxDatagramSize))}
+	if err := tr.Send(context.Background(), []LogEntry{big}); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+}
+
+func TestUnixTransportReconnects(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nfo.sock")
+	tr := NewUnixTransport(path)
+	defer tr.Close()
+
+	if err := tr.Send(context.Background(), []LogEntry{{Cmd: "early"}}); err == nil {
+		t.Fatal("expected an error before the socket exists")
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 2)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		sc := bufio.NewScanner(c)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+
+	if err := tr.Send(context.Background(), []LogEntry{{Cmd: "a"}, {Cmd: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"a", "b"} {
+		var got LogEntry
+		if err := json.Unmarshal([]byte(<-lines), &got); err != nil || got.Cmd != want {
+			t.Fatalf("got %+v, %v; want cmd %q", got, err, want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package nfo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"unicode/utf8"
+)
+
+// TruncateStrategy selects which part of an oversized value is kept.
+type TruncateStrategy int
+
+const (
+	// TruncateTail keeps the end, where build and test output usually
+	// report what went wrong. It is the default.
+	TruncateTail TruncateStrategy = iota
+	// TruncateHead keeps the beginning.
+	TruncateHead
+	// TruncateMiddle keeps both ends and drops the middle.
+	TruncateMiddle
+)
+
+// TruncateConfig configures WithTruncation. Zero limits are unlimited.
+type TruncateConfig struct {
+	// MaxOutput and MaxError cap the bytes kept of Output and Error.
+	MaxOutput int
+	MaxError  int
+	// MaxField caps the bytes kept of each string value in Fields.
+	MaxField int
+	// MaxEntry caps the approximate encoded size of a whole entry. Output,
+	// then Error, are cut further until the entry fits.
+	MaxEntry int
+	// Strategy selects the part kept; the default is TruncateTail.
+	Strategy TruncateStrategy
+	// Offload uploads the full Output or Error to POST /attachments before
+	// cutting it and records the returned ID in LogEntry.Attachments. The
+	// upload runs on the logging goroutine and needs the HTTP API; if it
+	// fails the entry is still logged, truncated.
+	Offload bool
+}
+
+// WithTruncation cuts oversized values before an entry is queued or sent, so
+// huge command output does not exceed the server's body limit. Each cut
+// value carries a marker with the number of bytes dropped, and the total is
+// reported in LogEntry.TruncatedBytes. Truncation runs after redaction.
+func WithTruncation(cfg TruncateConfig) Option {
+	return func(c *clientConfig) {
+		c.client.truncate = &cfg
+	}
+}
+
+// truncateEntry applies the client's TruncateConfig to entry. The caller's
+// Fields map is not modified.
+func (c *NfoClient) truncateEntry(ctx context.Context, entry LogEntry) LogEntry {
+	cfg := c.truncate
+	if cfg.MaxOutput > 0 {
+		entry.Output = c.truncateText(ctx, &entry, "output", entry.Output, cfg.MaxOutput)
+	}
+	if cfg.MaxError > 0 {
+		entry.Error = c.truncateText(ctx, &entry, "error", entry.Error, cfg.MaxError)
+	}
+	if cfg.MaxField > 0 && len(entry.Fields) > 0 {
+		var fields map[string]any
+		for k, v := range entry.Fields {
+			s, ok := v.(string)
+			if !ok || len(s) <= cfg.MaxField {
+				continue
+			}
+			if fields == nil {
+				fields = make(map[string]any, len(entry.Fields))
+				for k, v := range entry.Fields {
+					fields[k] = v
+				}
+			}
+			kept, dropped := truncate(s, cfg.MaxField, cfg.Strategy)
+			fields[k] = kept
+			entry.TruncatedBytes += dropped
+		}
+		if fields != nil {
+			entry.Fields = fields
+		}
+	}
+	if cfg.MaxEntry > 0 {
+		entry = c.fitEntry(ctx, entry)
+	}
+	return entry
+}
+
+// truncateMarker bounds the encoded length of the marker truncate adds.
+const truncateMarker = len(`\n[... 9999999999 bytes truncated ...]\n`)
+
+// fitEntry cuts Output, then Error, until the encoded entry, including the
+// defaults and metadata added when it is sent, is about MaxEntry bytes.
+// JSON escaping is not accounted for.
+func (c *NfoClient) fitEntry(ctx context.Context, entry LogEntry) LogEntry {
+	cfg := c.truncate
+	bare := entry
+	bare.Output, bare.Error = "", ""
+	bare.TruncatedBytes += len(entry.Output) + len(entry.Error)
+	data, err := c.encode(c.wireCodec(), c.prepare(bare))
+	if err != nil {
+		return entry
+	}
+	budget := max(cfg.MaxEntry-len(data)-2*truncateMarker, 0)
+	if len(entry.Output)+len(entry.Error) <= budget {
+		return entry
+	}
+	maxOutput := max(budget-len(entry.Error), 0)
+	entry.Output = c.truncateText(ctx, &entry, "output", entry.Output, maxOutput)
+	if maxError := max(budget-len(entry.Output), 0); len(entry.Error) > maxError {
+		entry.Error = c.truncateText(ctx, &entry, "error", entry.Error, maxError)
+	}
+	return entry
+}
+
+// truncateText cuts s to limit bytes, offloading the full text first if
+// configured, and adds the dropped bytes to entry.TruncatedBytes.
+func (c *NfoClient) truncateText(ctx context.Context, entry *LogEntry, name, s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	if c.truncate.Offload && entry.Attachments[name] == "" {
+		if id, err := c.attach(ctx, name, s); err == nil {
+			attachments := make(map[string]string, len(entry.Attachments)+1)
+			for k, v := range entry.Attachments {
+				attachments[k] = v
+			}
+			attachments[name] = id
+			entry.Attachments = attachments
+		}
+	}
+	kept, dropped := truncate(s, limit, c.truncate.Strategy)
+	entry.TruncatedBytes += dropped
+	return kept
+}
+
+// attach uploads content to POST /attachments and returns its ID.
+func (c *NfoClient) attach(ctx context.Context, name, content string) (string, error) {
+	body, err := json.Marshal(map[string]string{"name": name, "content": content})
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	data, err := c.do(ctx, http.MethodPost, "/attachments", ContentTypeJSON, body)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil || resp.ID == "" {
+		return "", fmt.Errorf("nfo: invalid attachment response %q", data)
+	}
+	return resp.ID, nil
+}
+
+// truncate keeps at most limit bytes of s, cut at rune boundaries and
+// marked with the number of bytes dropped, which it also returns.
+func truncate(s string, limit int, strategy TruncateStrategy) (string, int) {
+	if len(s) <= limit {
+		return s, 0
+	}
+	switch strategy {
+	case TruncateHead:
+		head := s[:runeBefore(s, limit)]
+		dropped := len(s) - len(head)
+		return fmt.Sprintf("%s\n[... %d bytes truncated ...]", head, dropped), dropped
+	case TruncateMiddle:
+		head := s[:runeBefore(s, limit/2)]
+		tail := s[runeAfter(s, len(s)-(limit-len(head))):]
+		dropped := len(s) - len(head) - len(tail)
+		return fmt.Sprintf("%s\n[... %d bytes truncated ...]\n%s", head, dropped, tail), dropped
+	default:
+		tail := s[runeAfter(s, len(s)-limit):]
+		dropped := len(s) - len(tail)
+		return fmt.Sprintf("[... %d bytes truncated ...]\n%s", dropped, tail), dropped
+	}
+}
+
+// runeBefore and runeAfter move the cut at i back or forward to the start
+// of a rune, so cuts never split a multi-byte character.
+func runeBefore(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
+
+func runeAfter(s string, i int) int {
+	for i < len(s) && !utf8.RuneStart(s[i]) {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,105 @@
+package nfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateStrategies(t *testing.T) {
+	s := "0123456789abcdefghij"
+	tests := []struct {
+		strategy TruncateStrategy
+		want     string
+	}{
+		{TruncateTail, "[... 14 bytes truncated ...]\nefghij"},
+		{TruncateHead, "012345\n[... 14 bytes truncated ...]"},
+		{TruncateMiddle, "012\n[... 14 bytes truncated ...]\nhij"},
+	}
+	for _, tt := range tests {
+		got, dropped := truncate(s, 6, tt.strategy)
+		if got != tt.want || dropped != 14 {
+			t.Errorf("strategy %d: got %q (%d dropped), want %q", tt.strategy, got, dropped, tt.want)
+		}
+	}
+	if got, dropped := truncate("short", 6, TruncateTail); got != "short" || dropped != 0 {
+		t.Errorf("short value changed: %q", got)
+	}
+	for _, strategy := range []TruncateStrategy{TruncateTail, TruncateHead, TruncateMiddle} {
+		if got, _ := truncate(strings.Repeat("é", 10), 5, strategy); !utf8.ValidString(got) {
+			t.Errorf("strategy %d split a rune: %q", strategy, got)
+		}
+	}
+}
+
+func TestWithTruncation(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithTruncation(TruncateConfig{
+		MaxOutput: 10, MaxField: 4, Strategy: TruncateHead,
+	}))
+
+	fields := map[string]any{"note": "abcdefgh", "n": 12345678}
+	client.Log(LogEntry{Cmd: "x", Output: strings.Repeat("o", 100), Error: strings.Repeat("e", 100), Fields: fields})
+	got := rec.Entries()[0]
+	if !strings.HasPrefix(got.Output, "oooooooooo\n[... 90 bytes") || len(got.Error) != 100 {
+		t.Fatalf("output=%q error length=%d", got.Output, len(got.Error))
+	}
+	if got.Fields["note"] != "abcd\n[... 4 bytes truncated ...]" || got.TruncatedBytes != 94 {
+		t.Fatalf("fields=%v truncated=%d", got.Fields, got.TruncatedBytes)
+	}
+	if fields["note"] != "abcdefgh" {
+		t.Fatal("caller's fields were modified")
+	}
+}
+
+func TestTruncationMaxEntry(t *testing.T) {
+	rec, srv := newRecorder(t)
+	client := NewClient(srv.URL, WithTruncation(TruncateConfig{MaxEntry: 1000}))
+
+	client.Log(LogEntry{Cmd: "x", Output: strings.Repeat("o", 5000), Error: strings.Repeat("e", 5000)})
+	got := rec.Entries()[0]
+	if size := len(rec.Body()); size > 1000 {
+		t.Fatalf("encoded entry is %d bytes, want at most 1000", size)
+	}
+	if got.TruncatedBytes < 9000 || !strings.Contains(got.Error, "bytes truncated") {
+		t.Fatalf("truncated=%d error=%q", got.TruncatedBytes, got.Error)
+	}
+}
+
+func TestTruncationOffload(t *testing.T) {
+	var mu sync.Mutex
+	var attached []string
+	var entry LogEntry
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/attachments":
+			var a struct{ Name, Content string }
+			json.NewDecoder(r.Body).Decode(&a)
+			attached = append(attached, a.Content)
+			w.Write([]byte(`{"id": "att-1"}`))
+		case "/log":
+			json.NewDecoder(r.Body).Decode(&entry)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewClient(srv.URL, WithTruncation(TruncateConfig{MaxOutput: 10, Offload: true}))
+	full := strings.Repeat("x", 50)
+	if err := client.Log(LogEntry{Cmd: "x", Output: full}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attached) != 1 || attached[0] != full {
+		t.Fatalf("attachments = %q", attached)
+	}
+	if entry.Attachments["output"] != "att-1" || entry.TruncatedBytes != 40 {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/wronai/lg/examples/go-client/nfoamqp
+
+go 1.23
+
+require (
+	github.com/rabbitmq/amqp091-go v1.10.0
+	github.com/wronai/lg/examples/go-client v0.0.0
+)
+
+replace github.com/wronai/lg/examples/go-client => ../
@@ -0,0 +1,144 @@
+package nfoamqp
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// channel records publishings and confirms them like a broker, nacking
+// those whose routing key is in nack and holding back confirmations while
+// hold is set.
+type channel struct {
+	mu         sync.Mutex
+	published  []amqp.Publishing
+	keys       []string
+	confirming bool
+	confirms   chan amqp.Confirmation
+	nack       map[string]bool
+	hold       bool
+}
+
+func (c *channel) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.published = append(c.published, msg)
+	c.keys = append(c.keys, exchange+"/"+key)
+	if c.confirming && !c.hold {
+		c.confirms <- amqp.Confirmation{DeliveryTag: uint64(len(c.published)), Ack: !c.nack[key]}
+	}
+	return nil
+}
+
+func (c *channel) Confirm(noWait bool) error {
+	c.confirming = true
+	return nil
+}
+
+func (c *channel) NotifyPublish(confirm chan amqp.Confirmation) chan amqp.Confirmation {
+	c.confirms = confirm
+	return confirm
+}
+
+func TestTransport(t *testing.T) {
+	ch := &channel{}
+	tr, err := New(ch, &Options{Exchange: "logs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := nfo.NewClient("http://unused", nfo.WithTransport(tr), nfo.WithEnv("prod"))
+	ctx := nfo.WithCorrelationID(context.Background(), "job-1")
+
+	if err := client.LogContext(ctx, nfo.LogEntry{Cmd: "deploy", Level: nfo.LevelError}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if err := client.LogBatch([]nfo.LogEntry{{Cmd: "a"}, {Cmd: "b", Env: "ci"}}); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+	if len(ch.published) != 3 {
+		t.Fatalf("published %d messages, want 3", len(ch.published))
+	}
+	if got := ch.keys; got[0] != "logs/prod.error" || got[1] != "logs/prod.info" || got[2] != "logs/ci.info" {
+		t.Fatalf("routing keys = %v", got)
+	}
+	msg := ch.published[0]
+	if msg.ContentType != nfo.ContentTypeJSON || msg.DeliveryMode != amqp.Persistent || msg.Type != "deploy" ||
+		msg.CorrelationId != "job-1" || msg.Headers["level"] != "error" || msg.Timestamp.IsZero() {
+		t.Fatalf("message = %+v", msg)
+	}
+	if entries, err := nfo.JSONCodec.Unmarshal(msg.Body); err != nil || entries[0].Cmd != "deploy" {
+		t.Fatalf("body decodes to %+v, %v", entries, err)
+	}
+}
+
+func TestTransportNacked(t *testing.T) {
+	ch := &channel{nack: map[string]bool{"prod.error": true}}
+	tr, err := New(ch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tr.Send(context.Background(), []nfo.LogEntry{
+		{Cmd: "a", Env: "prod", Level: nfo.LevelInfo},
+		{Cmd: "b", Env: "prod", Level: nfo.LevelError},
+	})
+	if !errors.Is(err, ErrNacked) {
+		t.Fatalf("Send = %v, want ErrNacked", err)
+	}
+	// Later sends are unaffected.
+	if err := tr.Send(context.Background(), []nfo.LogEntry{{Cmd: "c", Env: "prod"}}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+}
+
+func TestTransportAbandonedConfirms(t *testing.T) {
+	ch := &channel{hold: true}
+	tr, err := New(ch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := tr.Send(ctx, []nfo.LogEntry{{Cmd: "a"}}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send without confirmation = %v", err)
+	}
+
+	// The late confirmation of the first Send must not satisfy the second.
+	ch.mu.Lock()
+	ch.hold = false
+	ch.confirms <- amqp.Confirmation{DeliveryTag: 1, Ack: true}
+	ch.mu.Unlock()
+	if err := tr.Send(context.Background(), []nfo.LogEntry{{Cmd: "b"}}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+}
+
+func TestTransportNoConfirm(t *testing.T) {
+	ch := &channel{}
+	tr, err := New(ch, &Options{NoConfirm: true, Transient: true, Codec: nfo.MsgpackCodec,
+		RoutingKey: func(e nfo.LogEntry) string { return "nfo." + e.Cmd }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Send(context.Background(), []nfo.LogEntry{{Cmd: "a"}}); err != nil {
+		t.Fatal(err)
+	}
+	if ch.confirming || ch.keys[0] != "/nfo.a" || ch.published[0].DeliveryMode != amqp.Transient ||
+		ch.published[0].ContentType != nfo.ContentTypeMsgpack {
+		t.Fatalf("published %+v to %v", ch.published, ch.keys)
+	}
+}
+
+func TestRoutingByEnvLevel(t *testing.T) {
+	if got := RoutingByEnvLevel(nfo.LogEntry{}); got != "none.none" {
+		t.Fatalf("RoutingByEnvLevel = %q", got)
+	}
+	if got := RoutingByEnvLevel(nfo.LogEntry{Env: "ci", Level: nfo.LevelWarn}); got != "ci.warn" {
+		t.Fatalf("RoutingByEnvLevel = %q", got)
+	}
+}
@@ -0,0 +1,244 @@
+// Package nfoecho logs the requests served by an Echo instance to nfo:
+// route, handler, status and latency, with panic recovery and fields
+// extracted from request and response bodies.
+//
+// It lives in its own module so the core client does not depend on Echo:
+//
+//	async := nfo.NewAsyncClient(nfo.NewClient(url), nfo.AsyncConfig{})
+//	e := echo.New()
+//	e.Use(nfoecho.Middleware(async, &nfoecho.Options{Recover: true, Skip: []string{"/health"}}))
+package nfoecho
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// Options configures Middleware.
+type Options struct {
+	// Env is stamped on every entry (default $NFO_ENV or "prod").
+	Env string
+	// Skip lists the routes that are not logged, as registered, such as
+	// "/health" or "/users/:id".
+	Skip []string
+	// Recover makes the middleware recover panics in later handlers and
+	// pass them to Echo's HTTPErrorHandler, which answers 500. Without it a
+	// panic is logged and then re-panicked, for middleware.Recover or the
+	// server to handle.
+	Recover bool
+	// Fields, if set, returns extra fields for a request once it has been
+	// handled, for example the user set in the context by authentication.
+	Fields func(c echo.Context) map[string]any
+	// RequestBody and ResponseBody, if set, receive the first MaxBodyBytes
+	// of each body and return fields to log, for example an order ID.
+	// Bodies are only buffered when they are set.
+	RequestBody  func(body []byte) map[string]any
+	ResponseBody func(body []byte) map[string]any
+	// MaxBodyBytes caps the body bytes passed to the extractors (default
+	// 64 KiB).
+	MaxBodyBytes int
+}
+
+// Middleware returns an Echo middleware that logs each request through
+// logger, which should be an nfo.AsyncClient so that requests never wait on
+// the network. A nil opts selects the defaults.
+//
+// An error returned by a handler is passed to Echo's HTTPErrorHandler
+// before the request is logged, so the entry has the status the client
+// got. Entries have Cmd "METHOD /route" and Args the request path.
+// Responses with status 500 or above are failures logged at LevelError,
+// 4xx at LevelWarn and the rest at LevelInfo. Fields hold "method",
+// "route", "path", "status", "handler" (the route name, by default the
+// handler function), "client_ip" and "response_bytes". The entry is logged
+// with the request context, so it carries the correlation ID and trace of
+// the request.
+func Middleware(logger nfo.Logger, opts *Options) echo.MiddlewareFunc {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Env == "" {
+		o.Env = os.Getenv("NFO_ENV")
+	}
+	if o.Env == "" {
+		o.Env = "prod"
+	}
+	if o.MaxBodyBytes <= 0 {
+		o.MaxBodyBytes = 64 << 10
+	}
+	names := &handlerNames{}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if slices.Contains(o.Skip, c.Path()) {
+				return next(c)
+			}
+			start := time.Now()
+			req := c.Request()
+			var reqBody []byte
+			if o.RequestBody != nil && req.Body != nil {
+				reqBody, req.Body = peek(req.Body, o.MaxBodyBytes)
+			}
+			var capture *captureWriter
+			if o.ResponseBody != nil {
+				capture = &captureWriter{ResponseWriter: c.Response().Writer, max: o.MaxBodyBytes}
+				c.Response().Writer = capture
+			}
+
+			var err error
+			defer func() {
+				r := recover()
+				if r != nil && o.Recover {
+					c.Error(fmt.Errorf("panic: %v", r))
+				}
+				entry := requestEntry(c, &o, time.Since(start), err, r)
+				entry.Fields["handler"] = names.lookup(c)
+				if o.RequestBody != nil {
+					merge(&entry, o.RequestBody(reqBody))
+				}
+				if capture != nil {
+					merge(&entry, o.ResponseBody(capture.body.Bytes()))
+				}
+				if o.Fields != nil {
+					merge(&entry, o.Fields(c))
+				}
+				logContext(logger, req.Context(), entry)
+				if r != nil && !o.Recover {
+					panic(r)
+				}
+			}()
+			if err = next(c); err != nil {
+				c.Error(err) // commit the error response so its status is logged
+			}
+			return nil
+		}
+	}
+}
+
+// requestEntry describes the request handled by c, which failed with err
+// or panicked with r if they are not nil.
+func requestEntry(c echo.Context, o *Options, elapsed time.Duration, err error, r any) nfo.LogEntry {
+	req := c.Request()
+	route := c.Path()
+	if route == "" {
+		route = req.URL.Path // no route matched
+	}
+	status := c.Response().Status
+	if r != nil && !o.Recover {
+		status = http.StatusInternalServerError
+	}
+	ms := float64(elapsed) / float64(time.Millisecond)
+	ok := status < http.StatusInternalServerError
+	entry := nfo.LogEntry{
+		Cmd:        req.Method + " " + route,
+		Args:       []string{req.URL.Path},
+		Language:   "go",
+		Env:        o.Env,
+		Success:    &ok,
+		DurationMs: &ms,
+		Level:      nfo.LevelInfo,
+		Fields: map[string]any{
+			"method":         req.Method,
+			"route":          route,
+			"path":           req.URL.Path,
+			"status":         status,
+			"client_ip":      c.RealIP(),
+			"response_bytes": c.Response().Size,
+		},
+	}
+	switch {
+	case r != nil:
+		entry.Level = nfo.LevelError
+		entry.Error = fmt.Sprintf("panic: %v", r)
+		entry.Fields["panic_type"] = fmt.Sprintf("%T", r)
+		entry.Fields["stack"] = string(debug.Stack())
+	case !ok:
+		entry.Level = nfo.LevelError
+		entry.Error = http.StatusText(status)
+		if err != nil {
+			entry.Error = err.Error()
+		}
+	case status >= http.StatusBadRequest:
+		entry.Level = nfo.LevelWarn
+	}
+	return entry
+}
+
+// handlerNames maps "METHOD /route" to the name Echo gave the route,
+// which by default is the package-qualified name of its handler. c.Handler
+// only returns Echo's wrapper around the handler and middleware.
+type handlerNames struct {
+	m sync.Map
+}
+
+func (n *handlerNames) lookup(c echo.Context) string {
+	key := c.Request().Method + " " + c.Path()
+	if name, ok := n.m.Load(key); ok {
+		return name.(string)
+	}
+	// Routes may be added at any time, so look again on a miss.
+	for _, r := range c.Echo().Routes() {
+		n.m.Store(r.Method+" "+r.Path, r.Name)
+	}
+	name, _ := n.m.LoadOrStore(key, "")
+	return name.(string)
+}
+
+// merge adds fields to entry without replacing the ones it has.
+func merge(entry *nfo.LogEntry, fields map[string]any) {
+	for k, v := range fields {
+		if _, ok := entry.Fields[k]; !ok {
+			entry.Fields[k] = v
+		}
+	}
+}
+
+// logContext logs entry with ctx when logger supports it (NfoClient,
+// AsyncClient).
+func logContext(logger nfo.Logger, ctx context.Context, entry nfo.LogEntry) {
+	if cl, ok := logger.(interface {
+		LogContext(context.Context, nfo.LogEntry) error
+	}); ok {
+		cl.LogContext(ctx, entry)
+		return
+	}
+	logger.Log(entry)
+}
+
+// peek reads up to n bytes of body and returns them with a reader that
+// still yields the whole body.
+func peek(body io.ReadCloser, n int) ([]byte, io.ReadCloser) {
+	head, _ := io.ReadAll(io.LimitReader(body, int64(n)))
+	return head, struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), body), body}
+}
+
+// captureWriter keeps the first max bytes of the response body.
+type captureWriter struct {
+	http.ResponseWriter
+	body bytes.Buffer
+	max  int
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	if room := w.max - w.body.Len(); room > 0 {
+		w.body.Write(p[:min(len(p), room)])
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *captureWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
@@ -0,0 +1,162 @@
+package nfoecho
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+type captureLogger struct {
+	entries []nfo.LogEntry
+	ctxs    []context.Context
+}
+
+func (c *captureLogger) Log(entry nfo.LogEntry) error {
+	c.entries = append(c.entries, entry)
+	return nil
+}
+
+func (c *captureLogger) LogContext(ctx context.Context, entry nfo.LogEntry) error {
+	c.ctxs = append(c.ctxs, ctx)
+	return c.Log(entry)
+}
+
+func serve(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func getUser(c echo.Context) error {
+	c.Set("user", "alice")
+	return c.JSON(http.StatusOK, map[string]string{"id": c.Param("id")})
+}
+
+func TestMiddlewareLogsRequests(t *testing.T) {
+	capture := &captureLogger{}
+	e := echo.New()
+	e.Use(Middleware(capture, &Options{
+		Env:    "ci",
+		Skip:   []string{"/health"},
+		Fields: func(c echo.Context) map[string]any { return map[string]any{"user": c.Get("user"), "status": "ignored"} },
+	}))
+	e.GET("/users/:id", getUser)
+	e.GET("/health", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.POST("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "db down")
+	})
+
+	serve(e, http.MethodGet, "/users/42", "")
+	serve(e, http.MethodGet, "/health", "")
+	if w := serve(e, http.MethodPost, "/fail", ""); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d", w.Code)
+	}
+	serve(e, http.MethodGet, "/missing", "")
+
+	if len(capture.entries) != 3 || len(capture.ctxs) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", capture.entries)
+	}
+	got := capture.entries[0]
+	if got.Cmd != "GET /users/:id" || got.Args[0] != "/users/42" || got.Env != "ci" || got.Level != nfo.LevelInfo || !*got.Success || got.DurationMs == nil {
+		t.Errorf("unexpected entry: %+v", got)
+	}
+	if got.Fields["status"] != 200 || got.Fields["user"] != "alice" || got.Fields["response_bytes"] != int64(len(`{"id":"42"}`)+1) ||
+		!strings.HasSuffix(got.Fields["handler"].(string), ".getUser") {
+		t.Errorf("unexpected fields: %v", got.Fields)
+	}
+
+	got = capture.entries[1]
+	if got.Cmd != "POST /fail" || got.Level != nfo.LevelError || *got.Success || !strings.Contains(got.Error, "db down") || got.Fields["status"] != 503 {
+		t.Errorf("unexpected failure entry: %+v", got)
+	}
+	got = capture.entries[2]
+	if got.Level != nfo.LevelWarn || got.Fields["status"] != 404 {
+		t.Errorf("unexpected unmatched entry: %+v", got)
+	}
+}
+
+func TestMiddlewarePanics(t *testing.T) {
+	capture := &captureLogger{}
+	e := echo.New()
+	e.Use(Middleware(capture, &Options{Recover: true}))
+	e.GET("/boom", func(c echo.Context) error { panic("kaboom") })
+
+	if w := serve(e, http.MethodGet, "/boom", ""); w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", w.Code)
+	}
+	got := capture.entries[0]
+	if got.Error != "panic: kaboom" || got.Level != nfo.LevelError || got.Fields["status"] != 500 || got.Fields["stack"] == nil {
+		t.Fatalf("unexpected entry: %+v", got)
+	}
+
+	// Without Recover the panic reaches the next recovery handler.
+	capture = &captureLogger{}
+	e = echo.New()
+	var recovered any
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			defer func() {
+				if recovered = recover(); recovered != nil {
+					c.NoContent(http.StatusBadGateway)
+				}
+			}()
+			return next(c)
+		}
+	}, Middleware(capture, nil))
+	e.GET("/boom", func(c echo.Context) error { panic("kaboom") })
+	if w := serve(e, http.MethodGet, "/boom", ""); w.Code != http.StatusBadGateway || recovered != "kaboom" {
+		t.Fatalf("status = %d, recovered %v", w.Code, recovered)
+	}
+	if len(capture.entries) != 1 || capture.entries[0].Fields["status"] != 500 {
+		t.Fatalf("entries = %+v", capture.entries)
+	}
+}
+
+func TestMiddlewareBodies(t *testing.T) {
+	capture := &captureLogger{}
+	orderID := func(body []byte) map[string]any {
+		var v struct {
+			OrderID string `json:"order_id"`
+		}
+		json.Unmarshal(body, &v)
+		return map[string]any{"order_id": v.OrderID}
+	}
+	e := echo.New()
+	e.Use(Middleware(capture, &Options{
+		RequestBody:  orderID,
+		ResponseBody: func(body []byte) map[string]any { return map[string]any{"response": string(body)} },
+		MaxBodyBytes: 8,
+	}))
+	e.POST("/orders", func(c echo.Context) error {
+		body, _ := io.ReadAll(c.Request().Body)
+		return c.String(http.StatusCreated, fmt.Sprintf("created %d bytes", len(body)))
+	})
+
+	body := `{"order_id":"A1"}`
+	if w := serve(e, http.MethodPost, "/orders", body); w.Body.String() != "created 17 bytes" {
+		t.Fatalf("handler did not see the whole body: %q", w.Body.String())
+	}
+	got := capture.entries[0]
+	// The extractors see only the first MaxBodyBytes of each body.
+	if got.Fields["order_id"] != "" || got.Fields["response"] != "created " {
+		t.Fatalf("fields = %v", got.Fields)
+	}
+
+	capture.entries = nil
+	e = echo.New()
+	e.Use(Middleware(capture, &Options{RequestBody: orderID}))
+	e.POST("/orders", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	serve(e, http.MethodPost, "/orders", body)
+	if got := capture.entries[0].Fields["order_id"]; got != "A1" {
+		t.Fatalf("order_id = %v", got)
+	}
+}
@@ -0,0 +1,218 @@
+// Package nfogin logs the requests served by a Gin engine to nfo: route,
+// handler, status and latency, with panic recovery and fields extracted
+// from request and response bodies.
+//
+// It lives in its own module so the core client does not depend on Gin:
+//
+//	async := nfo.NewAsyncClient(nfo.NewClient(url), nfo.AsyncConfig{})
+//	r := gin.New()
+//	r.Use(nfogin.Middleware(async, &nfogin.Options{Recover: true, Skip: []string{"/health"}}))
+package nfogin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// Options configures Middleware.
+type Options struct {
+	// Env is stamped on every entry (default $NFO_ENV or "prod").
+	Env string
+	// Skip lists the routes that are not logged, as registered, such as
+	// "/health" or "/users/:id".
+	Skip []string
+	// Recover makes the middleware recover panics in later handlers and
+	// answer 500. Without it a panic is logged and then re-panicked, for
+	// gin.Recovery or the server to handle.
+	Recover bool
+	// Fields, if set, returns extra fields for a request once it has been
+	// handled, for example the user set in the context by authentication.
+	Fields func(c *gin.Context) map[string]any
+	// RequestBody and ResponseBody, if set, receive the first MaxBodyBytes
+	// of each body and return fields to log, for example an order ID.
+	// Bodies are only buffered when they are set.
+	RequestBody  func(body []byte) map[string]any
+	ResponseBody func(body []byte) map[string]any
+	// MaxBodyBytes caps the body bytes passed to the extractors (default
+	// 64 KiB).
+	MaxBodyBytes int
+}
+
+// Middleware returns a Gin middleware that logs each request through
+// logger, which should be an nfo.AsyncClient so that requests never wait on
+// the network. A nil opts selects the defaults.
+//
+// Entries have Cmd "METHOD /route" and Args the request path. Responses
+// with status 500 or above are failures logged at LevelError, 4xx at
+// LevelWarn and the rest at LevelInfo. Fields hold "method", "route",
+// "path", "status", "handler", "client_ip" and "response_bytes". The entry
+// is logged with the request context, so it carries the correlation ID and
+// trace of the request.
+func Middleware(logger nfo.Logger, opts *Options) gin.HandlerFunc {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Env == "" {
+		o.Env = os.Getenv("NFO_ENV")
+	}
+	if o.Env == "" {
+		o.Env = "prod"
+	}
+	if o.MaxBodyBytes <= 0 {
+		o.MaxBodyBytes = 64 << 10
+	}
+	return func(c *gin.Context) {
+		if slices.Contains(o.Skip, c.FullPath()) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		var reqBody []byte
+		if o.RequestBody != nil && c.Request.Body != nil {
+			reqBody, c.Request.Body = peek(c.Request.Body, o.MaxBodyBytes)
+		}
+		var capture *captureWriter
+		if o.ResponseBody != nil {
+			capture = &captureWriter{ResponseWriter: c.Writer, max: o.MaxBodyBytes}
+			c.Writer = capture
+		}
+
+		defer func() {
+			r := recover()
+			if r != nil && o.Recover {
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+			entry := requestEntry(c, &o, time.Since(start), r)
+			if o.RequestBody != nil {
+				merge(&entry, o.RequestBody(reqBody))
+			}
+			if capture != nil {
+				merge(&entry, o.ResponseBody(capture.body.Bytes()))
+			}
+			if o.Fields != nil {
+				merge(&entry, o.Fields(c))
+			}
+			logContext(logger, c.Request.Context(), entry)
+			if r != nil && !o.Recover {
+				panic(r)
+			}
+		}()
+		c.Next()
+	}
+}
+
+// requestEntry describes the request handled by c, which panicked with r
+// if r is not nil.
+func requestEntry(c *gin.Context, o *Options, elapsed time.Duration, r any) nfo.LogEntry {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path // no route matched
+	}
+	status := c.Writer.Status()
+	if r != nil {
+		status = http.StatusInternalServerError
+	}
+	ms := float64(elapsed) / float64(time.Millisecond)
+	ok := status < http.StatusInternalServerError
+	entry := nfo.LogEntry{
+		Cmd:        c.Request.Method + " " + route,
+		Args:       []string{c.Request.URL.Path},
+		Language:   "go",
+		Env:        o.Env,
+		Success:    &ok,
+		DurationMs: &ms,
+		Level:      nfo.LevelInfo,
+		Fields: map[string]any{
+			"method":         c.Request.Method,
+			"route":          route,
+			"path":           c.Request.URL.Path,
+			"status":         status,
+			"handler":        c.HandlerName(),
+			"client_ip":      c.ClientIP(),
+			"response_bytes": max(c.Writer.Size(), 0),
+		},
+	}
+	switch {
+	case r != nil:
+		entry.Level = nfo.LevelError
+		entry.Error = fmt.Sprintf("panic: %v", r)
+		entry.Fields["panic_type"] = fmt.Sprintf("%T", r)
+		entry.Fields["stack"] = string(debug.Stack())
+	case !ok:
+		entry.Level = nfo.LevelError
+		entry.Error = http.StatusText(status)
+		if len(c.Errors) > 0 {
+			entry.Error = strings.Join(c.Errors.Errors(), "; ")
+		}
+	case status >= http.StatusBadRequest:
+		entry.Level = nfo.LevelWarn
+	}
+	return entry
+}
+
+// merge adds fields to entry without replacing the ones it has.
+func merge(entry *nfo.LogEntry, fields map[string]any) {
+	for k, v := range fields {
+		if _, ok := entry.Fields[k]; !ok {
+			entry.Fields[k] = v
+		}
+	}
+}
+
+// logContext logs entry with ctx when logger supports it (NfoClient,
+// AsyncClient).
+func logContext(logger nfo.Logger, ctx context.Context, entry nfo.LogEntry) {
+	if cl, ok := logger.(interface {
+		LogContext(context.Context, nfo.LogEntry) error
+	}); ok {
+		cl.LogContext(ctx, entry)
+		return
+	}
+	logger.Log(entry)
+}
+
+// peek reads up to n bytes of body and returns them with a reader that
+// still yields the whole body.
+func peek(body io.ReadCloser, n int) ([]byte, io.ReadCloser) {
+	head, _ := io.ReadAll(io.LimitReader(body, int64(n)))
+	return head, struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), body), body}
+}
+
+// captureWriter keeps the first max bytes of the response body.
+type captureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+	max  int
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.keep(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *captureWriter) WriteString(s string) (int, error) {
+	w.keep([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *captureWriter) keep(p []byte) {
+	if room := w.max - w.body.Len(); room > 0 {
+		w.body.Write(p[:min(len(p), room)])
+	}
+}
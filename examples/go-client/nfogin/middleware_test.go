@@ -0,0 +1,156 @@
+package nfogin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+type captureLogger struct {
+	entries []nfo.LogEntry
+	ctxs    []context.Context
+}
+
+func (c *captureLogger) Log(entry nfo.LogEntry) error {
+	c.entries = append(c.entries, entry)
+	return nil
+}
+
+func (c *captureLogger) LogContext(ctx context.Context, entry nfo.LogEntry) error {
+	c.ctxs = append(c.ctxs, ctx)
+	return c.Log(entry)
+}
+
+func init() { gin.SetMode(gin.TestMode) }
+
+func serve(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func getUser(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"id": c.Param("id")}) }
+
+func TestMiddlewareLogsRequests(t *testing.T) {
+	capture := &captureLogger{}
+	r := gin.New()
+	r.Use(Middleware(capture, &Options{
+		Env:  "ci",
+		Skip: []string{"/health"},
+		Fields: func(c *gin.Context) map[string]any {
+			return map[string]any{"user": c.GetString("user"), "status": "ignored"}
+		},
+	}))
+	r.GET("/users/:id", func(c *gin.Context) { c.Set("user", "alice"); getUser(c) })
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/fail", func(c *gin.Context) {
+		c.Error(errors.New("db down"))
+		c.Status(http.StatusServiceUnavailable)
+	})
+
+	serve(r, http.MethodGet, "/users/42", "")
+	serve(r, http.MethodGet, "/health", "")
+	serve(r, http.MethodPost, "/fail", "")
+	serve(r, http.MethodGet, "/missing", "")
+
+	if len(capture.entries) != 3 || len(capture.ctxs) != 3 {
+		t.Fatalf("expected 3 entries, got %+v", capture.entries)
+	}
+	e := capture.entries[0]
+	if e.Cmd != "GET /users/:id" || e.Args[0] != "/users/42" || e.Env != "ci" || e.Level != nfo.LevelInfo || !*e.Success || e.DurationMs == nil {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e.Fields["status"] != 200 || e.Fields["user"] != "alice" || e.Fields["response_bytes"] != len(`{"id":"42"}`) ||
+		!strings.HasSuffix(e.Fields["handler"].(string), "TestMiddlewareLogsRequests.func2") {
+		t.Errorf("unexpected fields: %v", e.Fields)
+	}
+
+	e = capture.entries[1]
+	if e.Cmd != "POST /fail" || e.Level != nfo.LevelError || *e.Success || !strings.Contains(e.Error, "db down") {
+		t.Errorf("unexpected failure entry: %+v", e)
+	}
+	e = capture.entries[2]
+	if e.Cmd != "GET /missing" || e.Level != nfo.LevelWarn || e.Fields["status"] != 404 {
+		t.Errorf("unexpected unmatched entry: %+v", e)
+	}
+}
+
+func TestMiddlewarePanics(t *testing.T) {
+	capture := &captureLogger{}
+	r := gin.New()
+	r.Use(Middleware(capture, &Options{Recover: true}))
+	r.GET("/boom", func(c *gin.Context) { panic("kaboom") })
+
+	if w := serve(r, http.MethodGet, "/boom", ""); w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d", w.Code)
+	}
+	e := capture.entries[0]
+	if e.Error != "panic: kaboom" || e.Level != nfo.LevelError || e.Fields["status"] != 500 || e.Fields["stack"] == nil {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+
+	// Without Recover the panic reaches the next recovery handler.
+	capture = &captureLogger{}
+	r = gin.New()
+	var recovered any
+	r.Use(gin.CustomRecovery(func(c *gin.Context, err any) {
+		recovered = err
+		c.AbortWithStatus(http.StatusBadGateway)
+	}), Middleware(capture, nil))
+	r.GET("/boom", func(c *gin.Context) { panic("kaboom") })
+	if w := serve(r, http.MethodGet, "/boom", ""); w.Code != http.StatusBadGateway || recovered != "kaboom" {
+		t.Fatalf("status = %d, recovered %v", w.Code, recovered)
+	}
+	if len(capture.entries) != 1 || capture.entries[0].Env == "" {
+		t.Fatalf("entries = %+v", capture.entries)
+	}
+}
+
+func TestMiddlewareBodies(t *testing.T) {
+	capture := &captureLogger{}
+	orderID := func(body []byte) map[string]any {
+		var v struct {
+			OrderID string `json:"order_id"`
+		}
+		json.Unmarshal(body, &v)
+		return map[string]any{"order_id": v.OrderID}
+	}
+	r := gin.New()
+	r.Use(Middleware(capture, &Options{
+		RequestBody:  orderID,
+		ResponseBody: func(body []byte) map[string]any { return map[string]any{"response": string(body)} },
+		MaxBodyBytes: 8,
+	}))
+	r.POST("/orders", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, "created %d bytes", len(body))
+	})
+
+	body := `{"order_id":"A1"}`
+	if w := serve(r, http.MethodPost, "/orders", body); w.Body.String() != "created 17 bytes" {
+		t.Fatalf("handler did not see the whole body: %q", w.Body.String())
+	}
+	e := capture.entries[0]
+	// The extractor sees only the first MaxBodyBytes of each body.
+	if e.Fields["order_id"] != "" || e.Fields["response"] != "created " {
+		t.Fatalf("fields = %v", e.Fields)
+	}
+
+	capture.entries = nil
+	r = gin.New()
+	r.Use(Middleware(capture, &Options{RequestBody: orderID}))
+	r.POST("/orders", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	serve(r, http.MethodPost, "/orders", body)
+	if got := capture.entries[0].Fields["order_id"]; got != "A1" {
+		t.Fatalf("order_id = %v", got)
+	}
+}
@@ -0,0 +1,5 @@
+// Package nfopb holds the Go stubs generated from
+// examples/grpc-service/nfo.proto.
+package nfopb
+
+//go:generate protoc -I../../../grpc-service --go_out=. --go_opt=paths=source_relative "--go_opt=Mnfo.proto=github.com/wronai/lg/examples/go-client/nfogrpc/nfopb;nfopb" --go-grpc_out=. --go-grpc_opt=paths=source_relative "--go-grpc_opt=Mnfo.proto=github.com/wronai/lg/examples/go-client/nfogrpc/nfopb;nfopb" nfo.proto
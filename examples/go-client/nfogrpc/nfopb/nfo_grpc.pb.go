@@ -0,0 +1,247 @@
+// nfo example — gRPC service definition for high-performance logging.
+//
+// Use this proto to generate clients in any language (Go, Rust, Java, C++, etc.)
+// that send log entries to a centralized nfo gRPC service.
+//
+// Generate:
+//   python -m grpc_tools.protoc -I. --python_out=. --grpc_python_out=. nfo.proto
+//   (cd ../go-client/nfogrpc && go generate ./...)   # Go stubs in nfopb/
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: nfo.proto
+
+package nfopb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	NfoLogger_LogCall_FullMethodName   = "/nfo.NfoLogger/LogCall"
+	NfoLogger_BatchLog_FullMethodName  = "/nfo.NfoLogger/BatchLog"
+	NfoLogger_StreamLog_FullMethodName = "/nfo.NfoLogger/StreamLog"
+	NfoLogger_QueryLogs_FullMethodName = "/nfo.NfoLogger/QueryLogs"
+)
+
+// NfoLoggerClient is the client API for NfoLogger service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NfoLoggerClient interface {
+	// Log a single function call
+	LogCall(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (*LogResponse, error)
+	// Log multiple entries in one round-trip
+	BatchLog(ctx context.Context, in *BatchLogRequest, opts ...grpc.CallOption) (*BatchLogResponse, error)
+	// Stream log entries (high-throughput)
+	StreamLog(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LogRequest, LogResponse], error)
+	// Query stored logs
+	QueryLogs(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+}
+
+type nfoLoggerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNfoLoggerClient(cc grpc.ClientConnInterface) NfoLoggerClient {
+	return &nfoLoggerClient{cc}
+}
+
+func (c *nfoLoggerClient) LogCall(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (*LogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogResponse)
+	err := c.cc.Invoke(ctx, NfoLogger_LogCall_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nfoLoggerClient) BatchLog(ctx context.Context, in *BatchLogRequest, opts ...grpc.CallOption) (*BatchLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchLogResponse)
+	err := c.cc.Invoke(ctx, NfoLogger_BatchLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nfoLoggerClient) StreamLog(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LogRequest, LogResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NfoLogger_ServiceDesc.Streams[0], NfoLogger_StreamLog_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LogRequest, LogResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NfoLogger_StreamLogClient = grpc.BidiStreamingClient[LogRequest, LogResponse]
+
+func (c *nfoLoggerClient) QueryLogs(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, NfoLogger_QueryLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NfoLoggerServer is the server API for NfoLogger service.
+// All implementations must embed UnimplementedNfoLoggerServer
+// for forward compatibility.
+type NfoLoggerServer interface {
+	// Log a single function call
+	LogCall(context.Context, *LogRequest) (*LogResponse, error)
+	// Log multiple entries in one round-trip
+	BatchLog(context.Context, *BatchLogRequest) (*BatchLogResponse, error)
+	// Stream log entries (high-throughput)
+	StreamLog(grpc.BidiStreamingServer[LogRequest, LogResponse]) error
+	// Query stored logs
+	QueryLogs(context.Context, *QueryRequest) (*QueryResponse, error)
+	mustEmbedUnimplementedNfoLoggerServer()
+}
+
+// UnimplementedNfoLoggerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNfoLoggerServer struct{}
+
+func (UnimplementedNfoLoggerServer) LogCall(context.Context, *LogRequest) (*LogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogCall not implemented")
+}
+func (UnimplementedNfoLoggerServer) BatchLog(context.Context, *BatchLogRequest) (*BatchLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchLog not implemented")
+}
+func (UnimplementedNfoLoggerServer) StreamLog(grpc.BidiStreamingServer[LogRequest, LogResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamLog not implemented")
+}
+func (UnimplementedNfoLoggerServer) QueryLogs(context.Context, *QueryRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryLogs not implemented")
+}
+func (UnimplementedNfoLoggerServer) mustEmbedUnimplementedNfoLoggerServer() {}
+func (UnimplementedNfoLoggerServer) testEmbeddedByValue()                   {}
+
+// UnsafeNfoLoggerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NfoLoggerServer will
+// result in compilation errors.
+type UnsafeNfoLoggerServer interface {
+	mustEmbedUnimplementedNfoLoggerServer()
+}
+
+func RegisterNfoLoggerServer(s grpc.ServiceRegistrar, srv NfoLoggerServer) {
+	// If the following call pancis, it indicates UnimplementedNfoLoggerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NfoLogger_ServiceDesc, srv)
+}
+
+func _NfoLogger_LogCall_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NfoLoggerServer).LogCall(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NfoLogger_LogCall_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NfoLoggerServer).LogCall(ctx, req.(*LogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NfoLogger_BatchLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NfoLoggerServer).BatchLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NfoLogger_BatchLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NfoLoggerServer).BatchLog(ctx, req.(*BatchLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NfoLogger_StreamLog_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NfoLoggerServer).StreamLog(&grpc.GenericServerStream[LogRequest, LogResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NfoLogger_StreamLogServer = grpc.BidiStreamingServer[LogRequest, LogResponse]
+
+func _NfoLogger_QueryLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NfoLoggerServer).QueryLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NfoLogger_QueryLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NfoLoggerServer).QueryLogs(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NfoLogger_ServiceDesc is the grpc.ServiceDesc for NfoLogger service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NfoLogger_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nfo.NfoLogger",
+	HandlerType: (*NfoLoggerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "LogCall",
+			Handler:    _NfoLogger_LogCall_Handler,
+		},
+		{
+			MethodName: "BatchLog",
+			Handler:    _NfoLogger_BatchLog_Handler,
+		},
+		{
+			MethodName: "QueryLogs",
+			Handler:    _NfoLogger_QueryLogs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamLog",
+			Handler:       _NfoLogger_StreamLog_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "nfo.proto",
+}
@@ -0,0 +1,187 @@
+// Package nfogrpc sends nfo log entries to the gRPC service in
+// examples/grpc-service instead of over JSON/HTTP.
+//
+// It lives in its own module so the core client does not depend on gRPC:
+//
+//	tr, err := nfogrpc.Dial("localhost:50051",
+//	    grpc.WithTransportCredentials(insecure.NewCredentials()))
+//	defer tr.Close()
+//	client := nfo.NewClient(url, nfo.WithTransport(tr))
+package nfogrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfogrpc/nfopb"
+)
+
+// ErrNotStored is returned when the service acknowledges fewer entries
+// than were sent.
+var ErrNotStored = errors.New("nfogrpc: entries not stored")
+
+// Transport implements nfo.Transport over the NfoLogger service. Single
+// entries use the LogCall RPC; batches are streamed over StreamLog.
+type Transport struct {
+	client nfopb.NfoLoggerClient
+	conn   *grpc.ClientConn
+}
+
+var _ nfo.Transport = (*Transport)(nil)
+
+// New returns a transport using an existing connection, which the caller
+// keeps ownership of.
+func New(cc grpc.ClientConnInterface) *Transport {
+	return &Transport{client: nfopb.NewNfoLoggerClient(cc)}
+}
+
+// Dial connects to target and returns a transport that owns the
+// connection; Close releases it.
+func Dial(target string, opts ...grpc.DialOption) (*Transport, error) {
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("grpc: %w", err)
+	}
+	t := New(conn)
+	t.conn = conn
+	return t, nil
+}
+
+// Close closes the connection if the transport was created by Dial.
+func (t *Transport) Close() error {
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.Close()
+}
+
+// Send delivers entries and checks that the service stored every one.
+func (t *Transport) Send(ctx context.Context, entries []nfo.LogEntry) error {
+	if len(entries) == 1 {
+		resp, err := t.client.LogCall(ctx, toProto(entries[0]))
+		if err != nil {
+			return err
+		}
+		if !resp.GetStored() {
+			return ErrNotStored
+		}
+		return nil
+	}
+	return t.stream(ctx, entries)
+}
+
+// stream sends entries over one StreamLog call and counts the
+// acknowledgements, reading them concurrently so a large batch cannot
+// stall on flow control.
+func (t *Transport) stream(ctx context.Context, entries []nfo.LogEntry) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := t.client.StreamLog(ctx)
+	if err != nil {
+		return err
+	}
+	type result struct {
+		stored int
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		for {
+			resp, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				r.err = err
+				break
+			}
+			if resp.GetStored() {
+				r.stored++
+			}
+		}
+		done <- r
+	}()
+
+	for _, entry := range entries {
+		if err := stream.Send(toProto(entry)); err != nil {
+			// The real error, if any, is reported by Recv.
+			break
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	r := <-done
+	if r.err != nil {
+		return r.err
+	}
+	if r.stored < len(entries) {
+		return fmt.Errorf("%w: %d of %d", ErrNotStored, len(entries)-r.stored, len(entries))
+	}
+	return nil
+}
+
+// toProto converts an entry to the wire message. Fields go into extra,
+// with non-string values JSON-encoded.
+func toProto(e nfo.LogEntry) *nfopb.LogRequest {
+	req := &nfopb.LogRequest{
+		Cmd:        e.Cmd,
+		Args:       e.Args,
+		Language:   e.Language,
+		Env:        e.Env,
+		Success:    e.Success,
+		DurationMs: e.DurationMs,
+		Output:     e.Output,
+		Error:      e.Error,
+		TraceId:    e.TraceID,
+		SpanId:     e.SpanID,
+
+		CorrelationId:  e.CorrelationID,
+		TruncatedBytes: int64(e.TruncatedBytes),
+		Attachments:    e.Attachments,
+		Fingerprint:    e.Fingerprint,
+		RepeatCount:    int64(e.RepeatCount),
+	}
+	if e.Level != 0 {
+		req.Level = e.Level.String()
+	}
+	if e.Timestamp != nil {
+		req.Timestamp = e.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	if len(e.Fields) > 0 {
+		req.Extra = make(map[string]string, len(e.Fields))
+		for k, v := range e.Fields {
+			if s, ok := v.(string); ok {
+				req.Extra[k] = s
+			} else if data, err := json.Marshal(v); err == nil {
+				req.Extra[k] = string(data)
+			} else {
+				req.Extra[k] = fmt.Sprint(v)
+			}
+		}
+	}
+	if m := e.Meta; m != nil {
+		req.Meta = &nfopb.Metadata{
+			Hostname:     m.Hostname,
+			Pid:          int64(m.PID),
+			GoVersion:    m.GoVersion,
+			Binary:       m.Binary,
+			Os:           m.OS,
+			Arch:         m.Arch,
+			ContainerId:  m.ContainerID,
+			PodName:      m.PodName,
+			PodNamespace: m.PodNamespace,
+			NodeName:     m.NodeName,
+		}
+	}
+	return req
+}
@@ -0,0 +1,118 @@
+package nfogrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfogrpc/nfopb"
+)
+
+// server stores requests and rejects entries whose cmd is "reject".
+type server struct {
+	nfopb.UnimplementedNfoLoggerServer
+
+	mu       sync.Mutex
+	requests []*nfopb.LogRequest
+	streams  int
+}
+
+func (s *server) store(req *nfopb.LogRequest) *nfopb.LogResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, req)
+	return &nfopb.LogResponse{Stored: req.GetCmd() != "reject"}
+}
+
+func (s *server) LogCall(_ context.Context, req *nfopb.LogRequest) (*nfopb.LogResponse, error) {
+	return s.store(req), nil
+}
+
+func (s *server) StreamLog(stream nfopb.NfoLogger_StreamLogServer) error {
+	s.mu.Lock()
+	s.streams++
+	s.mu.Unlock()
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(s.store(req)); err != nil {
+			return err
+		}
+	}
+}
+
+func newServer(t *testing.T) (*server, *Transport) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := &server{}
+	gs := grpc.NewServer()
+	nfopb.RegisterNfoLoggerServer(gs, srv)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	tr, err := Dial("passthrough:///bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { tr.Close() })
+	return srv, tr
+}
+
+func TestTransportWithClient(t *testing.T) {
+	srv, tr := newServer(t)
+	client := nfo.NewClient("http://unused", nfo.WithTransport(tr), nfo.WithEnv("test"))
+
+	ok := true
+	ctx := nfo.WithCorrelationID(context.Background(), "job-1")
+	if err := client.LogContext(ctx, nfo.LogEntry{Cmd: "single", Success: &ok, Fields: map[string]any{"user": "u1", "n": 3}}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if err := client.LogBatch([]nfo.LogEntry{{Cmd: "a"}, {Cmd: "b"}, {Cmd: "c"}}); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+
+	if len(srv.requests) != 4 || srv.streams != 1 {
+		t.Fatalf("requests=%d streams=%d", len(srv.requests), srv.streams)
+	}
+	first := srv.requests[0]
+	if first.GetEnv() != "test" || !first.GetSuccess() || first.GetLevel() != "info" || first.GetTimestamp() == "" || first.Meta.GetHostname() == "" ||
+		first.GetCorrelationId() != "job-1" {
+		t.Fatalf("unexpected request: %v", first)
+	}
+	if first.Extra["user"] != "u1" || first.Extra["n"] != "3" {
+		t.Fatalf("extra = %v", first.Extra)
+	}
+	if srv.requests[1].Success != nil {
+		t.Fatal("unset success must stay unset on the wire")
+	}
+}
+
+func TestTransportNotStored(t *testing.T) {
+	_, tr := newServer(t)
+	ctx := context.Background()
+
+	if err := tr.Send(ctx, []nfo.LogEntry{{Cmd: "reject"}}); !errors.Is(err, ErrNotStored) {
+		t.Fatalf("single: expected ErrNotStored, got %v", err)
+	}
+	err := tr.Send(ctx, []nfo.LogEntry{{Cmd: "a"}, {Cmd: "reject"}})
+	if !errors.Is(err, ErrNotStored) {
+		t.Fatalf("stream: expected ErrNotStored, got %v", err)
+	}
+}
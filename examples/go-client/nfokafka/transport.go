@@ -0,0 +1,184 @@
+// Package nfokafka publishes nfo log entries to a Kafka topic instead of
+// sending them to nfo-service, for platforms that collect logs from a
+// message bus.
+//
+// It lives in its own module so the core client does not depend on a Kafka
+// client:
+//
+//	tr, err := nfokafka.Dial([]string{"kafka-1:9092", "kafka-2:9092"}, "nfo-logs", nil)
+//	defer tr.Close()
+//	client := nfo.NewClient(url, nfo.WithTransport(tr))
+//
+// Each entry becomes one message, keyed so that the entries of one command
+// in one environment land on the same partition and keep their order.
+package nfokafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// KeyFunc returns the message key of an entry, which picks its partition.
+type KeyFunc func(nfo.LogEntry) []byte
+
+// Partition keys. Entries with the same key go to the same partition and
+// are consumed in the order they were logged.
+var (
+	// KeyByEnvCmd keys entries by environment and command, "prod/deploy".
+	KeyByEnvCmd KeyFunc = func(e nfo.LogEntry) []byte { return []byte(e.Env + "/" + e.Cmd) }
+	// KeyByCmd keys entries by command.
+	KeyByCmd KeyFunc = func(e nfo.LogEntry) []byte { return []byte(e.Cmd) }
+	// KeyByEnv keys entries by environment.
+	KeyByEnv KeyFunc = func(e nfo.LogEntry) []byte { return []byte(e.Env) }
+	// KeyByCorrelationID keys entries by correlation ID, keeping each job
+	// together.
+	KeyByCorrelationID KeyFunc = func(e nfo.LogEntry) []byte { return []byte(e.CorrelationID) }
+)
+
+// Options configures a Transport. Zero values select the defaults.
+type Options struct {
+	// Key picks the partition key of each entry (default KeyByEnvCmd).
+	Key KeyFunc
+	// Codec encodes message values (default nfo.JSONCodec). Its
+	// Content-Type is sent in the "content-type" header.
+	Codec nfo.Codec
+	// BatchSize and BatchBytes cap the messages and bytes Dial's writer
+	// sends per request to a partition (default 100 and 1 MiB), and
+	// BatchTimeout is how long it waits to fill a batch (default 10ms).
+	// Send hands the writer whole batches from the client, so a short wait
+	// suffices.
+	BatchSize    int
+	BatchBytes   int64
+	BatchTimeout time.Duration
+	// Compression compresses the batches of Dial's writer, e.g.
+	// kafka.Snappy (default none).
+	Compression kafka.Compression
+}
+
+// Writer is the part of *kafka.Writer a Transport uses.
+type Writer interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+}
+
+// Transport implements nfo.Transport by writing entries to Kafka. Send
+// returns once the brokers have acknowledged every message.
+type Transport struct {
+	w     Writer
+	owned *kafka.Writer
+	key   KeyFunc
+	codec nfo.Codec
+}
+
+var _ nfo.Transport = (*Transport)(nil)
+
+// New returns a transport writing through w, which the caller configures
+// (brokers, topic, balancer) and keeps ownership of. Use a balancer that
+// honours message keys, such as &kafka.Hash{}, to get per-key ordering.
+func New(w Writer, opts *Options) *Transport {
+	if opts == nil {
+		opts = &Options{}
+	}
+	t := &Transport{w: w, key: opts.Key, codec: opts.Codec}
+	if t.key == nil {
+		t.key = KeyByEnvCmd
+	}
+	if t.codec == nil {
+		t.codec = nfo.JSONCodec
+	}
+	return t
+}
+
+// Dial returns a transport that owns a writer to topic on the given
+// brokers; Close releases it. Messages are partitioned by hashing their
+// key and are acknowledged by all in-sync replicas.
+func Dial(brokers []string, topic string, opts *Options) (*Transport, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("nfokafka: no brokers")
+	}
+	if topic == "" {
+		return nil, errors.New("nfokafka: no topic")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		BatchSize:    opts.BatchSize,
+		BatchBytes:   opts.BatchBytes,
+		BatchTimeout: opts.BatchTimeout,
+		RequiredAcks: kafka.RequireAll,
+		Compression:  opts.Compression,
+		// The nfo client retries, and backs off, on its own.
+		MaxAttempts: 1,
+	}
+	if w.BatchSize <= 0 {
+		w.BatchSize = 100
+	}
+	if w.BatchBytes <= 0 {
+		w.BatchBytes = 1 << 20
+	}
+	if w.BatchTimeout <= 0 {
+		w.BatchTimeout = 10 * time.Millisecond
+	}
+	t := New(w, opts)
+	t.owned = w
+	return t, nil
+}
+
+// Close flushes and closes the writer if the transport was created by
+// Dial.
+func (t *Transport) Close() error {
+	if t.owned == nil {
+		return nil
+	}
+	return t.owned.Close()
+}
+
+// Send writes one message per entry and waits for the brokers to
+// acknowledge them all.
+func (t *Transport) Send(ctx context.Context, entries []nfo.LogEntry) error {
+	msgs := make([]kafka.Message, len(entries))
+	for i, entry := range entries {
+		msg, err := t.message(entry)
+		if err != nil {
+			return err
+		}
+		msgs[i] = msg
+	}
+	if err := t.w.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("kafka: %w", err)
+	}
+	return nil
+}
+
+// message encodes entry as a Kafka message. The headers let consumers
+// route entries without decoding them.
+func (t *Transport) message(e nfo.LogEntry) (kafka.Message, error) {
+	value, err := t.codec.Marshal(e)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("marshal: %w", err)
+	}
+	msg := kafka.Message{
+		Key:   t.key(e),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: "content-type", Value: []byte(t.codec.ContentType())},
+			{Key: "env", Value: []byte(e.Env)},
+		},
+	}
+	if e.Level != 0 {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: "level", Value: []byte(e.Level.String())})
+	}
+	if e.Timestamp != nil {
+		msg.Time = *e.Timestamp
+	}
+	return msg, nil
+}
@@ -0,0 +1,105 @@
+package nfokafka
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// writer records messages, failing while err is set.
+type writer struct {
+	mu   sync.Mutex
+	msgs []kafka.Message
+	err  error
+}
+
+func (w *writer) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestTransport(t *testing.T) {
+	w := &writer{}
+	client := nfo.NewClient("http://unused", nfo.WithTransport(New(w, nil)), nfo.WithEnv("prod"))
+
+	if err := client.LogBatch([]nfo.LogEntry{{Cmd: "deploy"}, {Cmd: "migrate", Env: "staging", Level: nfo.LevelWarn}}); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+	if len(w.msgs) != 2 {
+		t.Fatalf("wrote %d messages, want 2", len(w.msgs))
+	}
+	msg := w.msgs[1]
+	if string(msg.Key) != "staging/migrate" || header(msg, "level") != "warn" || header(msg, "env") != "staging" ||
+		header(msg, "content-type") != nfo.ContentTypeJSON || msg.Time.IsZero() {
+		t.Fatalf("message = %+v", msg)
+	}
+	entries, err := nfo.JSONCodec.Unmarshal(msg.Value)
+	if err != nil || len(entries) != 1 || entries[0].Cmd != "migrate" {
+		t.Fatalf("value decodes to %+v, %v", entries, err)
+	}
+	if string(w.msgs[0].Key) != "prod/deploy" {
+		t.Fatalf("key = %q", w.msgs[0].Key)
+	}
+}
+
+func TestTransportOptions(t *testing.T) {
+	w := &writer{}
+	tr := New(w, &Options{Key: KeyByCorrelationID, Codec: nfo.MsgpackCodec})
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if err := tr.Send(context.Background(), []nfo.LogEntry{{Cmd: "a", CorrelationID: "job-1", Timestamp: &ts}}); err != nil {
+		t.Fatal(err)
+	}
+	msg := w.msgs[0]
+	if string(msg.Key) != "job-1" || !msg.Time.Equal(ts) || header(msg, "content-type") != nfo.ContentTypeMsgpack {
+		t.Fatalf("message = %+v", msg)
+	}
+	if entries, err := nfo.MsgpackCodec.Unmarshal(msg.Value); err != nil || entries[0].Cmd != "a" {
+		t.Fatalf("value decodes to %+v, %v", entries, err)
+	}
+}
+
+func TestTransportError(t *testing.T) {
+	boom := errors.New("leader not available")
+	w := &writer{err: boom}
+	client := nfo.NewClient("http://unused", nfo.WithTransport(New(w, nil)))
+	if err := client.Log(nfo.LogEntry{Cmd: "a"}); !errors.Is(err, boom) {
+		t.Fatalf("Log = %v, want %v", err, boom)
+	}
+}
+
+func TestDial(t *testing.T) {
+	if _, err := Dial(nil, "logs", nil); err == nil {
+		t.Fatal("expected an error without brokers")
+	}
+	if _, err := Dial([]string{"localhost:9092"}, "", nil); err == nil {
+		t.Fatal("expected an error without a topic")
+	}
+	tr, err := Dial([]string{"localhost:9092"}, "logs", &Options{BatchSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Close()
+	if tr.owned.BatchSize != 10 || tr.owned.BatchTimeout != 10*time.Millisecond || tr.owned.Topic != "logs" {
+		t.Fatalf("writer = %+v", tr.owned)
+	}
+}
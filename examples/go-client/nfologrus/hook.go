@@ -0,0 +1,190 @@
+// Package nfologrus adapts github.com/sirupsen/logrus to nfo: a Hook added
+// to a logrus.Logger converts its entries to nfo.LogEntry values and ships
+// them through an nfo client, next to the logger's usual output.
+//
+// It lives in its own module so the core client does not depend on logrus:
+//
+//	async := nfo.NewAsyncClient(nfo.NewClient(url), nfo.AsyncConfig{})
+//	logrus.AddHook(nfologrus.NewHook(async, nil))
+package nfologrus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfoslog"
+)
+
+// Options configures a Hook.
+type Options struct {
+	// Levels are the levels that are shipped (default logrus.PanicLevel
+	// through logrus.InfoLevel).
+	Levels []logrus.Level
+	// Env is stamped on every entry (default $NFO_ENV or "prod").
+	Env string
+	// FieldMap maps logrus field keys to LogEntry JSON field names, as in
+	// nfoslog.Options. Fields without a mapping are stored in Fields. Nil
+	// selects nfoslog.DefaultFieldMap, which routes WithError's "error" key.
+	FieldMap map[string]string
+}
+
+// Hook is a logrus.Hook that forwards entries to an nfo.Logger.
+//
+// The message becomes Cmd unless a field maps to "cmd". The logrus level
+// sets Level, and entries at logrus.ErrorLevel or more severe are marked
+// Success=false. With ReportCaller set, the caller is stored in Fields as
+// "caller".
+type Hook struct {
+	logger nfo.Logger
+	opts   Options
+}
+
+var _ logrus.Hook = (*Hook)(nil)
+
+// NewHook returns a Hook that ships entries through logger, which should be
+// an nfo.AsyncClient so that logging calls never wait on the network.
+// A nil opts selects the defaults.
+func NewHook(logger nfo.Logger, opts *Options) *Hook {
+	h := &Hook{logger: logger}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Levels == nil {
+		for _, l := range logrus.AllLevels {
+			if l <= logrus.InfoLevel {
+				h.opts.Levels = append(h.opts.Levels, l)
+			}
+		}
+	}
+	if h.opts.Env == "" {
+		h.opts.Env = os.Getenv("NFO_ENV")
+	}
+	if h.opts.Env == "" {
+		h.opts.Env = "prod"
+	}
+	if h.opts.FieldMap == nil {
+		h.opts.FieldMap = nfoslog.DefaultFieldMap
+	}
+	return h
+}
+
+// Levels returns the levels the hook fires for.
+func (h *Hook) Levels() []logrus.Level {
+	return h.opts.Levels
+}
+
+// Fire converts e into a LogEntry and logs it. When e carries a context and
+// the logger supports LogContext (NfoClient, AsyncClient), the context is
+// passed along so the entry is linked to the active trace.
+func (h *Hook) Fire(e *logrus.Entry) error {
+	entry := nfo.LogEntry{
+		Cmd:      e.Message,
+		Language: "go",
+		Env:      h.opts.Env,
+		Level:    level(e.Level),
+	}
+	if !e.Time.IsZero() {
+		t := e.Time.UTC()
+		entry.Timestamp = &t
+	}
+	if e.Level <= logrus.ErrorLevel {
+		failed := false
+		entry.Success = &failed
+	}
+	for key, v := range e.Data {
+		h.apply(&entry, key, v)
+	}
+	if e.Caller != nil {
+		h.apply(&entry, "caller", fmt.Sprintf("%s:%d", e.Caller.File, e.Caller.Line))
+	}
+
+	if cl, ok := h.logger.(contextLogger); ok && e.Context != nil {
+		return cl.LogContext(e.Context, entry)
+	}
+	return h.logger.Log(entry)
+}
+
+// contextLogger is implemented by nfo loggers that accept a context.
+type contextLogger interface {
+	LogContext(ctx context.Context, entry nfo.LogEntry) error
+}
+
+// apply stores the field key=v on entry.
+func (h *Hook) apply(entry *nfo.LogEntry, key string, v any) {
+	switch h.opts.FieldMap[key] {
+	case "cmd":
+		entry.Cmd = fmt.Sprint(v)
+	case "args":
+		if args, ok := v.([]string); ok {
+			entry.Args = append(entry.Args, args...)
+		} else {
+			entry.Args = append(entry.Args, fmt.Sprint(v))
+		}
+	case "language":
+		entry.Language = fmt.Sprint(v)
+	case "env":
+		entry.Env = fmt.Sprint(v)
+	case "success":
+		if ok, isBool := v.(bool); isBool {
+			entry.Success = &ok
+		}
+	case "duration_ms":
+		if ms, ok := durationMs(v); ok {
+			entry.DurationMs = &ms
+		}
+	case "output":
+		entry.Output = fmt.Sprint(v)
+	case "error":
+		entry.Error = fmt.Sprint(v)
+	default:
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]any)
+		}
+		entry.Fields[key] = fieldValue(v)
+	}
+}
+
+// fieldValue converts v into something encoding/json renders sensibly.
+func fieldValue(v any) any {
+	switch v := v.(type) {
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case error:
+		return v.Error()
+	}
+	return v
+}
+
+// level maps a logrus level to the nfo level covering it.
+func level(l logrus.Level) nfo.Level {
+	switch {
+	case l <= logrus.ErrorLevel:
+		return nfo.LevelError
+	case l == logrus.WarnLevel:
+		return nfo.LevelWarn
+	case l == logrus.InfoLevel:
+		return nfo.LevelInfo
+	}
+	return nfo.LevelDebug
+}
+
+func durationMs(v any) (float64, bool) {
+	switch v := v.(type) {
+	case time.Duration:
+		return float64(v) / float64(time.Millisecond), true
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	}
+	return 0, false
+}
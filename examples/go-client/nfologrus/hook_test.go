@@ -0,0 +1,103 @@
+package nfologrus
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+type captureLogger struct {
+	entries []nfo.LogEntry
+	ctxs    []context.Context
+}
+
+func (c *captureLogger) Log(entry nfo.LogEntry) error {
+	c.entries = append(c.entries, entry)
+	return nil
+}
+
+func (c *captureLogger) LogContext(ctx context.Context, entry nfo.LogEntry) error {
+	c.ctxs = append(c.ctxs, ctx)
+	return c.Log(entry)
+}
+
+func newLogger(hook *Hook) *logrus.Logger {
+	logger := logrus.New()
+	logger.Out = io.Discard
+	logger.Level = logrus.TraceLevel
+	logger.AddHook(hook)
+	return logger
+}
+
+func TestHookMapsFields(t *testing.T) {
+	capture := &captureLogger{}
+	logger := newLogger(NewHook(capture, &Options{Env: "ci"}))
+
+	logger.WithFields(logrus.Fields{
+		"cmd":      "deploy",
+		"args":     []string{"prod"},
+		"duration": 1500 * time.Millisecond,
+		"region":   "eu",
+	}).WithError(errors.New("timeout")).Error("deploy failed")
+
+	if len(capture.entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(capture.entries))
+	}
+	e := capture.entries[0]
+	if e.Cmd != "deploy" || e.Env != "ci" || e.Language != "go" || e.Level != nfo.LevelError {
+		t.Errorf("unexpected identity fields: %+v", e)
+	}
+	if e.Success == nil || *e.Success {
+		t.Errorf("error entry should have success=false, got %v", e.Success)
+	}
+	if e.Timestamp == nil || time.Since(*e.Timestamp) > time.Minute || e.Timestamp.Location() != time.UTC {
+		t.Errorf("expected the entry time in UTC, got %v", e.Timestamp)
+	}
+	if e.DurationMs == nil || *e.DurationMs != 1500 || e.Error != "timeout" {
+		t.Errorf("unexpected outcome: duration=%v error=%q", e.DurationMs, e.Error)
+	}
+	if !slices.Equal(e.Args, []string{"prod"}) || e.Fields["region"] != "eu" {
+		t.Errorf("unexpected args %v or fields %v", e.Args, e.Fields)
+	}
+}
+
+func TestHookLevels(t *testing.T) {
+	capture := &captureLogger{}
+	logger := newLogger(NewHook(capture, nil))
+
+	logger.Debug("ignored")
+	logger.Info("i")
+	logger.Warn("w")
+
+	if len(capture.entries) != 2 || capture.entries[0].Level != nfo.LevelInfo || capture.entries[1].Level != nfo.LevelWarn {
+		t.Fatalf("unexpected entries: %+v", capture.entries)
+	}
+
+	capture.entries = nil
+	logger = newLogger(NewHook(capture, &Options{Levels: logrus.AllLevels}))
+	logger.Trace("t")
+	if len(capture.entries) != 1 || capture.entries[0].Level != nfo.LevelDebug {
+		t.Fatalf("trace entry not shipped as debug: %+v", capture.entries)
+	}
+}
+
+func TestHookPassesContext(t *testing.T) {
+	capture := &captureLogger{}
+	logger := newLogger(NewHook(capture, nil))
+
+	ctx := nfo.WithCorrelationID(context.Background(), "job-1")
+	logger.WithContext(ctx).Info("with context")
+	if len(capture.ctxs) != 1 {
+		t.Fatalf("LogContext not used")
+	}
+	if id, _ := nfo.CorrelationID(capture.ctxs[0]); id != "job-1" {
+		t.Fatalf("context not passed to LogContext")
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/wronai/lg/examples/go-client/nfootel
+
+go 1.23
+
+require (
+	github.com/wronai/lg/examples/go-client v0.0.0
+	go.opentelemetry.io/otel/trace v1.35.0
+)
+
+require go.opentelemetry.io/otel v1.35.0 // indirect
+
+replace github.com/wronai/lg/examples/go-client => ../
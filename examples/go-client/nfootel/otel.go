@@ -0,0 +1,35 @@
+// Package nfootel links nfo entries to OpenTelemetry traces.
+//
+// It lives in its own module so the core client stays free of the
+// OpenTelemetry dependency:
+//
+//	client := nfo.NewClient(url, nfootel.WithOTel())
+//	client.LogContext(ctx, entry) // trace_id/span_id + traceparent header
+package nfootel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// Extractor is an nfo.TraceExtractor that reads the OpenTelemetry span
+// context carried by ctx.
+func Extractor(ctx context.Context) (nfo.TraceContext, bool) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return nfo.TraceContext{}, false
+	}
+	return nfo.TraceContext{
+		TraceID: sc.TraceID().String(),
+		SpanID:  sc.SpanID().String(),
+		Sampled: sc.IsSampled(),
+	}, true
+}
+
+// WithOTel makes the client take trace IDs from OpenTelemetry spans.
+func WithOTel() nfo.Option {
+	return nfo.WithTraceExtractor(Extractor)
+}
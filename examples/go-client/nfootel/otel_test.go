@@ -0,0 +1,36 @@
+package nfootel
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestExtractor(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	tc, ok := Extractor(ctx)
+	if !ok {
+		t.Fatal("expected a trace context")
+	}
+	if tc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tc.SpanID != "00f067aa0ba902b7" || !tc.Sampled {
+		t.Fatalf("unexpected trace context: %+v", tc)
+	}
+	if tc.Traceparent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("unexpected traceparent: %s", tc.Traceparent())
+	}
+}
+
+func TestExtractorNoSpan(t *testing.T) {
+	if _, ok := Extractor(context.Background()); ok {
+		t.Fatal("expected no trace context without a span")
+	}
+}
@@ -0,0 +1,130 @@
+// Package nfoparquet adds Apache Parquet to the formats of nfo's
+// NfoClient.Export, for loading exported logs into DuckDB, Spark, pandas or
+// a data warehouse.
+//
+// It lives in its own module so the core client does not depend on a
+// Parquet library:
+//
+//	f, _ := os.Create("logs.parquet")
+//	defer f.Close()
+//	n, err := client.Export(ctx, params, f, nfoparquet.NewFormat(nil))
+package nfoparquet
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// Options configures a Parquet export.
+type Options struct {
+	// Compression is the codec of the column chunks (default Zstandard).
+	Compression compress.Codec
+	// RowsPerRowGroup caps the rows buffered per row group; larger groups
+	// compress better but use more memory (default 100000).
+	RowsPerRowGroup int64
+}
+
+// Row is the schema of an exported file: one row per entry, with the
+// columns of nfo.ExportColumns. Unset values are null, Args is a list and
+// Fields holds the entry's fields as a JSON document.
+type Row struct {
+	Timestamp     time.Time `parquet:"timestamp,optional"`
+	Level         string    `parquet:"level,optional,dict"`
+	Env           string    `parquet:"env,optional,dict"`
+	Cmd           string    `parquet:"cmd,dict"`
+	Args          []string  `parquet:"args,list"`
+	Language      string    `parquet:"language,optional,dict"`
+	Success       *bool     `parquet:"success,optional"`
+	DurationMs    *float64  `parquet:"duration_ms,optional"`
+	Output        string    `parquet:"output,optional"`
+	Error         string    `parquet:"error,optional"`
+	CorrelationID string    `parquet:"correlation_id,optional"`
+	TraceID       string    `parquet:"trace_id,optional"`
+	SpanID        string    `parquet:"span_id,optional"`
+	Fingerprint   string    `parquet:"fingerprint,optional"`
+	RepeatCount   int64     `parquet:"repeat_count,optional"`
+	Hostname      string    `parquet:"hostname,optional,dict"`
+	Fields        string    `parquet:"fields,optional,json"`
+}
+
+// NewRow converts entry to a Row.
+func NewRow(entry nfo.LogEntry) Row {
+	row := Row{
+		Env:           entry.Env,
+		Cmd:           entry.Cmd,
+		Args:          entry.Args,
+		Language:      entry.Language,
+		Success:       entry.Success,
+		DurationMs:    entry.DurationMs,
+		Output:        entry.Output,
+		Error:         entry.Error,
+		CorrelationID: entry.CorrelationID,
+		TraceID:       entry.TraceID,
+		SpanID:        entry.SpanID,
+		Fingerprint:   entry.Fingerprint,
+		RepeatCount:   int64(entry.RepeatCount),
+	}
+	if entry.Timestamp != nil {
+		row.Timestamp = entry.Timestamp.UTC()
+	}
+	if entry.Level != 0 {
+		row.Level = entry.Level.String()
+	}
+	if entry.Meta != nil {
+		row.Hostname = entry.Meta.Hostname
+	}
+	if len(entry.Fields) > 0 {
+		if data, err := json.Marshal(entry.Fields); err == nil {
+			row.Fields = string(data)
+		}
+	}
+	return row
+}
+
+// NewFormat returns the Parquet nfo.ExportFormat. A nil opts selects the
+// defaults.
+func NewFormat(opts *Options) nfo.ExportFormat {
+	f := format{Options{Compression: &parquet.Zstd, RowsPerRowGroup: 100_000}}
+	if opts != nil {
+		if opts.Compression != nil {
+			f.opts.Compression = opts.Compression
+		}
+		if opts.RowsPerRowGroup > 0 {
+			f.opts.RowsPerRowGroup = opts.RowsPerRowGroup
+		}
+	}
+	return f
+}
+
+type format struct {
+	opts Options
+}
+
+func (f format) NewEncoder(w io.Writer) (nfo.ExportEncoder, error) {
+	return &encoder{w: parquet.NewGenericWriter[Row](w,
+		parquet.Compression(f.opts.Compression),
+		parquet.MaxRowsPerRowGroup(f.opts.RowsPerRowGroup),
+	)}, nil
+}
+
+type encoder struct {
+	w   *parquet.GenericWriter[Row]
+	row [1]Row
+}
+
+func (e *encoder) Encode(entry nfo.LogEntry) error {
+	e.row[0] = NewRow(entry)
+	_, err := e.w.Write(e.row[:])
+	return err
+}
+
+// Close flushes the last row group and writes the file footer.
+func (e *encoder) Close() error {
+	return e.w.Close()
+}
@@ -0,0 +1,53 @@
+package nfoparquet
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfotest"
+)
+
+func TestExportParquet(t *testing.T) {
+	srv := nfotest.NewServer(t)
+	client := nfo.NewClient(srv.URL)
+
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	ok, ms := false, 12.5
+	if err := client.Log(nfo.LogEntry{
+		Timestamp: &ts, Env: "prod", Cmd: "deploy", Args: []string{"--tag", "v2"},
+		Success: &ok, DurationMs: &ms, Error: "exit 1", Fields: map[string]any{"team": "core"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for range 4 {
+		client.Log(nfo.LogEntry{Cmd: "tick"})
+	}
+
+	var buf bytes.Buffer
+	n, err := client.Export(context.Background(), nfo.QueryParams{Limit: 2}, &buf, NewFormat(&Options{RowsPerRowGroup: 3}))
+	if err != nil || n != 5 {
+		t.Fatalf("Export = %d, %v", n, err)
+	}
+
+	rows, err := parquet.Read[Row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("got %d rows", len(rows))
+	}
+	r := rows[0]
+	if r.Cmd != "deploy" || !r.Timestamp.Equal(ts) || r.Level != "error" || len(r.Args) != 2 ||
+		r.Success == nil || *r.Success || r.DurationMs == nil || *r.DurationMs != 12.5 ||
+		r.Error != "exit 1" || r.Language != "go" || r.Fields != `{"team":"core"}` {
+		t.Fatalf("unexpected row: %+v", r)
+	}
+	if r := rows[4]; r.Cmd != "tick" || r.DurationMs != nil || r.Fields != "" {
+		t.Fatalf("unexpected row: %+v", r)
+	}
+}
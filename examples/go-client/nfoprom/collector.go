@@ -0,0 +1,142 @@
+// Package nfoprom exposes nfo client health as Prometheus metrics.
+//
+// It lives in its own module so the core client does not depend on the
+// Prometheus client library:
+//
+//	metrics := nfoprom.NewCollector("myapp")
+//	prometheus.MustRegister(metrics)
+//	client := nfo.NewClient(url, nfo.WithMetrics(metrics))
+package nfoprom
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// Collector implements both nfo.Metrics and prometheus.Collector.
+type Collector struct {
+	sent    prometheus.Counter
+	dropped *prometheus.CounterVec
+	byPrio  *prometheus.CounterVec
+	batches prometheus.Counter
+	retries prometheus.Counter
+	queue   prometheus.Gauge
+	skew    prometheus.Gauge
+	latency *prometheus.HistogramVec
+}
+
+var (
+	_ nfo.Metrics         = (*Collector)(nil)
+	_ nfo.PriorityMetrics = (*Collector)(nil)
+	_ nfo.ClockMetrics    = (*Collector)(nil)
+)
+
+// NewCollector creates the nfo client metrics under namespace, e.g.
+// "myapp" yields myapp_nfo_entries_sent_total.
+func NewCollector(namespace string) *Collector {
+	const subsystem = "nfo"
+	return &Collector{
+		sent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "entries_sent_total",
+			Help: "Log entries accepted by nfo-service.",
+		}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "entries_dropped_total",
+			Help: "Log entries discarded by the client, by reason.",
+		}, []string{"reason"}),
+		byPrio: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "entries_dropped_by_priority_total",
+			Help: "Log entries discarded by the client, by priority and reason.",
+		}, []string{"priority", "reason"}),
+		batches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "batches_flushed_total",
+			Help: "Batch requests accepted by nfo-service.",
+		}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "request_retries_total",
+			Help: "Request attempts repeated after a failure.",
+		}),
+		queue: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "queue_depth",
+			Help: "Entries buffered by the async client.",
+		}),
+		skew: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "clock_skew_seconds",
+			Help: "How far the client clock is ahead of nfo-service's, as last measured.",
+		}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name:    "request_duration_seconds",
+			Help:    "Latency of HTTP attempts to nfo-service.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"result"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.sent.Describe(ch)
+	c.dropped.Describe(ch)
+	c.byPrio.Describe(ch)
+	c.batches.Describe(ch)
+	c.retries.Describe(ch)
+	c.queue.Describe(ch)
+	c.skew.Describe(ch)
+	c.latency.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.sent.Collect(ch)
+	c.dropped.Collect(ch)
+	c.byPrio.Collect(ch)
+	c.batches.Collect(ch)
+	c.retries.Collect(ch)
+	c.queue.Collect(ch)
+	c.skew.Collect(ch)
+	c.latency.Collect(ch)
+}
+
+// EntriesSent implements nfo.Metrics.
+func (c *Collector) EntriesSent(n int) { c.sent.Add(float64(n)) }
+
+// EntriesDropped implements nfo.Metrics.
+func (c *Collector) EntriesDropped(n int, reason nfo.DropReason) {
+	c.dropped.WithLabelValues(string(reason)).Add(float64(n))
+}
+
+// EntriesDroppedByPriority implements nfo.PriorityMetrics.
+func (c *Collector) EntriesDroppedByPriority(n int, reason nfo.DropReason, priority nfo.Priority) {
+	c.byPrio.WithLabelValues(priority.String(), string(reason)).Add(float64(n))
+}
+
+// BatchFlushed implements nfo.Metrics.
+func (c *Collector) BatchFlushed(int) { c.batches.Inc() }
+
+// RequestRetried implements nfo.Metrics.
+func (c *Collector) RequestRetried() { c.retries.Inc() }
+
+// RequestCompleted implements nfo.Metrics.
+func (c *Collector) RequestCompleted(latency time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	c.latency.WithLabelValues(result).Observe(latency.Seconds())
+}
+
+// QueueDepth implements nfo.Metrics.
+func (c *Collector) QueueDepth(n int) { c.queue.Set(float64(n)) }
+
+// ClockSkew implements nfo.ClockMetrics.
+func (c *Collector) ClockSkew(skew time.Duration) { c.skew.Set(skew.Seconds()) }
@@ -0,0 +1,56 @@
+package nfoprom
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+func TestCollector(t *testing.T) {
+	c := NewCollector("test")
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(c)
+
+	c.EntriesSent(3)
+	c.EntriesDropped(2, "queue_full")
+	c.EntriesDroppedByPriority(2, "queue_full", nfo.PriorityLow)
+	c.BatchFlushed(3)
+	c.RequestRetried()
+	c.QueueDepth(7)
+	c.ClockSkew(-1500 * time.Millisecond)
+	c.RequestCompleted(20*time.Millisecond, nil)
+	c.RequestCompleted(time.Second, errors.New("boom"))
+
+	want := `
+# HELP test_nfo_clock_skew_seconds How far the client clock is ahead of nfo-service's, as last measured.
+# TYPE test_nfo_clock_skew_seconds gauge
+test_nfo_clock_skew_seconds -1.5
+# HELP test_nfo_entries_dropped_by_priority_total Log entries discarded by the client, by priority and reason.
+# TYPE test_nfo_entries_dropped_by_priority_total counter
+test_nfo_entries_dropped_by_priority_total{priority="low",reason="queue_full"} 2
+# HELP test_nfo_entries_dropped_total Log entries discarded by the client, by reason.
+# TYPE test_nfo_entries_dropped_total counter
+test_nfo_entries_dropped_total{reason="queue_full"} 2
+# HELP test_nfo_entries_sent_total Log entries accepted by nfo-service.
+# TYPE test_nfo_entries_sent_total counter
+test_nfo_entries_sent_total 3
+# HELP test_nfo_queue_depth Entries buffered by the async client.
+# TYPE test_nfo_queue_depth gauge
+test_nfo_queue_depth 7
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(want),
+		"test_nfo_entries_sent_total", "test_nfo_entries_dropped_total", "test_nfo_entries_dropped_by_priority_total", "test_nfo_queue_depth",
+		"test_nfo_clock_skew_seconds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(c, "test_nfo_request_duration_seconds"); n != 2 {
+		t.Fatalf("expected ok and error latency series, got %d", n)
+	}
+}
@@ -0,0 +1,116 @@
+package nfoserver
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// ForwarderConfig configures a Forwarder. Zero values select the defaults.
+type ForwarderConfig struct {
+	// QueueSize bounds the entries buffered in memory (default 10000).
+	// When it is full, Forward waits for the next batch to go out.
+	QueueSize int
+	// FlushInterval is how often buffered entries are sent upstream
+	// (default 1s). A full batch is sent right away.
+	FlushInterval time.Duration
+	// SpillDir, if set, is a directory where entries upstream does not
+	// accept are kept on disk, to be replayed once it recovers. Without
+	// it they are dropped.
+	SpillDir string
+	// SpillMaxBytes caps the spill file (default nfo.DefaultSpillMaxBytes).
+	SpillMaxBytes int64
+	// ErrorHandler, if set, receives errors from background sends.
+	ErrorHandler func(error)
+}
+
+// Forwarder relays entries to an upstream nfo-service, making a Go program
+// a sidecar or edge agent: it buffers entries in memory, sends them in
+// batches through the upstream client, with its retries, circuit breaker
+// and compression, and spills them to disk while upstream is unreachable.
+//
+// Entries reach it from a Handler, see Config.Forwarder, from a channel,
+// see Run, or directly through Forward. The upstream client's defaults,
+// hooks and redaction apply to relayed entries too, filling only what the
+// entries leave empty.
+type Forwarder struct {
+	async *nfo.AsyncClient
+	spill *nfo.Spill
+}
+
+// NewForwarder returns a Forwarder that relays through upstream. Close it
+// on shutdown to send what is still buffered.
+func NewForwarder(upstream *nfo.NfoClient, cfg ForwarderConfig) (*Forwarder, error) {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10_000
+	}
+	f := &Forwarder{}
+	if cfg.SpillDir != "" {
+		spill, err := nfo.OpenSpill(cfg.SpillDir, cfg.SpillMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		f.spill = spill
+	}
+	f.async = nfo.NewAsyncClient(upstream, nfo.AsyncConfig{
+		QueueSize:     cfg.QueueSize,
+		FlushInterval: cfg.FlushInterval,
+		Overflow:      nfo.Block,
+		ErrorHandler:  cfg.ErrorHandler,
+		Spill:         f.spill,
+	})
+	return f, nil
+}
+
+// Forward queues entries for upstream, returning once they are buffered.
+func (f *Forwarder) Forward(entries []nfo.LogEntry) error {
+	var errs []error
+	for _, entry := range entries {
+		if err := f.async.Log(entry); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Run forwards the entries received on entries until it is closed, and
+// then returns nil, or until ctx is done.
+func (f *Forwarder) Run(ctx context.Context, entries <-chan nfo.LogEntry) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case entry, ok := <-entries:
+			if !ok {
+				return nil
+			}
+			if err := f.async.Log(entry); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Pending returns the number of entries buffered in memory, and Spilled the
+// number waiting on disk.
+func (f *Forwarder) Pending() int { return f.async.Len() }
+
+func (f *Forwarder) Spilled() int {
+	if f.spill == nil {
+		return 0
+	}
+	return f.spill.Len()
+}
+
+// Flush sends everything buffered now; see nfo.AsyncClient.FlushContext.
+func (f *Forwarder) Flush(ctx context.Context) (nfo.FlushReport, error) {
+	return f.async.FlushContext(ctx)
+}
+
+// Close stops the Forwarder after sending, or spilling, what is buffered;
+// see nfo.AsyncClient.CloseContext.
+func (f *Forwarder) Close(ctx context.Context) (nfo.FlushReport, error) {
+	return f.async.CloseContext(ctx)
+}
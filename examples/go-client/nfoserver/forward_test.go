@@ -0,0 +1,93 @@
+package nfoserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfotest"
+)
+
+func TestForwarderRelay(t *testing.T) {
+	upstream := nfotest.NewServer(t)
+	f, err := NewForwarder(nfo.NewClient(upstream.URL, nfo.WithoutMetadata()), ForwarderConfig{FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(NewHandler(Config{Forwarder: f}))
+	defer srv.Close()
+
+	edge := nfo.NewClient(srv.URL, nfo.WithEnv("edge"))
+	edge.Log(nfo.LogEntry{Cmd: "scan", Language: "python"})
+	edge.LogBatch([]nfo.LogEntry{{Cmd: "a"}, {Cmd: "b"}})
+	if f.Pending() != 3 || len(upstream.Entries()) != 0 {
+		t.Fatalf("pending %d, upstream %d", f.Pending(), len(upstream.Entries()))
+	}
+	var apiErr *nfo.APIError
+	if _, err := edge.Query(context.Background(), nfo.QueryParams{}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("relay without a store served a query: %v", err)
+	}
+
+	if report, err := f.Close(context.Background()); err != nil || report.Sent != 3 {
+		t.Fatalf("Close = %+v, %v", report, err)
+	}
+	got := upstream.Entries()
+	if len(got) != 3 || got[0].Cmd != "scan" || got[0].Language != "python" || got[0].Env != "edge" || got[0].Timestamp == nil {
+		t.Fatalf("upstream entries = %+v", got)
+	}
+}
+
+func TestForwarderSpillsWhileUpstreamDown(t *testing.T) {
+	upstream := nfotest.NewServer(t)
+	upstream.SetStatus("/logs/batch", http.StatusServiceUnavailable)
+	f, err := NewForwarder(nfo.NewClient(upstream.URL, nfo.WithRetry(1, 0)), ForwarderConfig{
+		FlushInterval: time.Hour,
+		SpillDir:      t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close(context.Background())
+
+	f.Forward([]nfo.LogEntry{{Cmd: "a"}, {Cmd: "b"}})
+	if report, _ := f.Flush(context.Background()); report.Spilled != 2 || f.Spilled() != 2 {
+		t.Fatalf("report %+v, spilled %d", report, f.Spilled())
+	}
+
+	upstream.SetStatus("/logs/batch", 0)
+	if report, err := f.Flush(context.Background()); err != nil || report.Sent != 2 || f.Spilled() != 0 {
+		t.Fatalf("replay: %+v, %v", report, err)
+	}
+	if len(upstream.Entries()) != 2 {
+		t.Fatalf("upstream entries = %+v", upstream.Entries())
+	}
+}
+
+func TestForwarderRun(t *testing.T) {
+	upstream := nfotest.NewServer(t)
+	f, err := NewForwarder(nfo.NewClient(upstream.URL), ForwarderConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch := make(chan nfo.LogEntry, 2)
+	ch <- nfo.LogEntry{Cmd: "x"}
+	ch <- nfo.LogEntry{Cmd: "y"}
+	close(ch)
+	if err := f.Run(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+	f.Close(context.Background())
+	if len(upstream.Entries()) != 2 {
+		t.Fatalf("upstream entries = %+v", upstream.Entries())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := f.Run(ctx, make(chan nfo.LogEntry)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package nfoserver
+
+import (
+	"context"
+	"sync"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// DefaultMemoryEntries is the capacity of a MemoryStore created with a
+// limit of 0.
+const DefaultMemoryEntries = 100_000
+
+// MemoryStore keeps the most recent entries in memory. It suits tests,
+// development and collectors that forward entries elsewhere.
+type MemoryStore struct {
+	limit int
+
+	mu      sync.RWMutex
+	entries []nfo.LogEntry
+	ids     map[string]bool // LogEntry.IDs in entries
+}
+
+var (
+	_ Store     = (*MemoryStore)(nil)
+	_ Confirmer = (*MemoryStore)(nil)
+)
+
+// NewMemoryStore returns a MemoryStore that keeps at most limit entries,
+// dropping the oldest ones when full (DefaultMemoryEntries if limit <= 0).
+func NewMemoryStore(limit int) *MemoryStore {
+	if limit <= 0 {
+		limit = DefaultMemoryEntries
+	}
+	return &MemoryStore{limit: limit, ids: make(map[string]bool)}
+}
+
+// Append implements Store. Entries with the ID of a stored entry are
+// skipped.
+func (s *MemoryStore) Append(ctx context.Context, entries []nfo.LogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range entries {
+		if e.ID != "" {
+			if s.ids[e.ID] {
+				continue
+			}
+			s.ids[e.ID] = true
+		}
+		s.entries = append(s.entries, e)
+	}
+	if len(s.entries) >= 2*s.limit {
+		// Compact only once the slice has doubled, so eviction costs
+		// amortised constant time per entry.
+		for _, e := range s.entries[:len(s.entries)-s.limit] {
+			delete(s.ids, e.ID)
+		}
+		s.entries = append(s.entries[:0:0], s.recent()...)
+	}
+	return nil
+}
+
+// Confirm implements Confirmer. Entries evicted by the limit may still be
+// confirmed until the store compacts.
+func (s *MemoryStore) Confirm(ctx context.Context, ids []string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var stored []string
+	for _, id := range ids {
+		if s.ids[id] {
+			stored = append(stored, id)
+		}
+	}
+	return stored, nil
+}
+
+// recent returns the entries within the limit.
+func (s *MemoryStore) recent() []nfo.LogEntry {
+	return s.entries[max(0, len(s.entries)-s.limit):]
+}
+
+// Query implements Store.
+func (s *MemoryStore) Query(ctx context.Context, params nfo.QueryParams) ([]nfo.LogEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var result []nfo.LogEntry
+	skip := params.Offset
+	for _, e := range s.recent() {
+		if !Match(params, e) {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		if params.Limit > 0 && len(result) == params.Limit {
+			break
+		}
+		result = append(result, e)
+	}
+	return result, nil
+}
+
+// Len returns the number of stored entries.
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.recent())
+}
@@ -0,0 +1,43 @@
+package nfoserver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+func TestMemoryStoreEvictsOldest(t *testing.T) {
+	s := NewMemoryStore(3)
+	ctx := context.Background()
+	for i := range 10 {
+		s.Append(ctx, []nfo.LogEntry{{Cmd: fmt.Sprint(i)}})
+	}
+	if s.Len() != 3 {
+		t.Fatalf("Len = %d", s.Len())
+	}
+	got, _ := s.Query(ctx, nfo.QueryParams{})
+	if len(got) != 3 || got[0].Cmd != "7" || got[2].Cmd != "9" {
+		t.Fatalf("entries = %+v", got)
+	}
+	if got, _ := s.Query(ctx, nfo.QueryParams{Offset: 1, Limit: 1}); len(got) != 1 || got[0].Cmd != "8" {
+		t.Fatalf("paged = %+v", got)
+	}
+}
+
+func TestMemoryStoreConfirm(t *testing.T) {
+	s := NewMemoryStore(2)
+	ctx := context.Background()
+	s.Append(ctx, []nfo.LogEntry{{Cmd: "a", ID: "1"}, {Cmd: "b", ID: "2"}, {Cmd: "a again", ID: "1"}})
+	if s.Len() != 2 {
+		t.Fatalf("Len = %d, want duplicates skipped", s.Len())
+	}
+	if got, _ := s.Confirm(ctx, []string{"2", "3", "1"}); fmt.Sprint(got) != "[2 1]" {
+		t.Fatalf("Confirm = %v", got)
+	}
+	s.Append(ctx, []nfo.LogEntry{{Cmd: "c", ID: "3"}, {Cmd: "d", ID: "4"}})
+	if got, _ := s.Confirm(ctx, []string{"1", "2", "3", "4"}); fmt.Sprint(got) != "[3 4]" {
+		t.Fatalf("Confirm after eviction = %v", got)
+	}
+}
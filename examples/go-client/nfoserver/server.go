@@ -0,0 +1,398 @@
+// Package nfoserver embeds an nfo ingest server in a Go program, so the
+// program itself can act as the collector: other nfo clients post entries
+// to it, and it answers queries from the entries it stored.
+//
+//	store := nfoserver.NewMemoryStore(0)
+//	http.ListenAndServe(":8080", nfoserver.NewHandler(nfoserver.Config{Store: store}))
+//
+// The handler speaks the nfo-service API: POST /log and /logs/batch in any
+// built-in codec, optionally gzipped, GET /logs, GET /health and GET
+// /capabilities, GET /time, plus POST /logs/ack when the Store is a
+// Confirmer. Entries are kept in a Store; NewMemoryStore is built in and
+// the nfosqlite module adds SQLite. A Forwarder relays them to an upstream
+// nfo-service.
+package nfoserver
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// Query limits: GET /logs returns DefaultLimit entries unless the request
+// sets limit, and never more than MaxLimit.
+const (
+	DefaultLimit = 50
+	MaxLimit     = 1000
+)
+
+// DefaultMaxBodyBytes caps the size of a decompressed request body.
+const DefaultMaxBodyBytes = 10 << 20
+
+// Store keeps the entries received by a Handler. Implementations must be
+// safe for concurrent use.
+type Store interface {
+	// Append stores entries in order, all or none.
+	Append(ctx context.Context, entries []nfo.LogEntry) error
+	// Query returns the stored entries matching params in the order they
+	// were appended, skipping params.Offset and returning at most
+	// params.Limit. The Handler always sets a positive Limit.
+	Query(ctx context.Context, params nfo.QueryParams) ([]nfo.LogEntry, error)
+}
+
+// Confirmer is implemented by stores that can look entries up by
+// LogEntry.ID. With such a store the Handler supports acknowledged
+// delivery (see nfo.WithAcknowledgements): it gives every entry an ID,
+// lists the IDs in its responses, stores each ID only once, and serves
+// POST /logs/ack.
+type Confirmer interface {
+	// Confirm returns those of ids whose entries are stored.
+	Confirm(ctx context.Context, ids []string) ([]string, error)
+}
+
+// Config configures a Handler.
+type Config struct {
+	// Store keeps the entries (default NewMemoryStore(0), or none when
+	// Forwarder is set).
+	Store Store
+	// Forwarder, if set, relays every accepted entry upstream. Without a
+	// Store the Handler is a pure relay and does not serve GET /logs.
+	Forwarder *Forwarder
+	// MaxBodyBytes caps request bodies after decompression; larger ones
+	// are rejected with 413 (default DefaultMaxBodyBytes).
+	MaxBodyBytes int64
+	// Version is reported by GET /health (default "nfoserver").
+	Version string
+}
+
+// Handler serves the nfo-service API from a Store.
+type Handler struct {
+	store   Store
+	forward *Forwarder
+	maxBody int64
+	version string
+	mux     *http.ServeMux
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// NewHandler returns a Handler configured by cfg.
+func NewHandler(cfg Config) *Handler {
+	h := &Handler{store: cfg.Store, forward: cfg.Forwarder, maxBody: cfg.MaxBodyBytes, version: cfg.Version}
+	if h.store == nil && h.forward == nil {
+		h.store = NewMemoryStore(0)
+	}
+	if h.maxBody <= 0 {
+		h.maxBody = DefaultMaxBodyBytes
+	}
+	if h.version == "" {
+		h.version = "nfoserver"
+	}
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("POST /log", h.log)
+	h.mux.HandleFunc("POST /logs/batch", h.batch)
+	if h.store != nil {
+		h.mux.HandleFunc("GET /logs", h.query)
+	}
+	if h.confirmer() != nil {
+		h.mux.HandleFunc("POST /logs/ack", h.ack)
+	}
+	h.mux.HandleFunc("GET /health", h.health)
+	h.mux.HandleFunc("GET /capabilities", h.capabilities)
+	h.mux.HandleFunc("GET /time", h.time)
+	return h
+}
+
+// Store returns the store entries are kept in, or nil.
+func (h *Handler) Store() Store { return h.store }
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) log(w http.ResponseWriter, r *http.Request) {
+	entries, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	if len(entries) != 1 {
+		http.Error(w, "expected one entry", http.StatusUnprocessableEntity)
+		return
+	}
+	ids, ok := h.append(w, r, entries)
+	if !ok {
+		return
+	}
+	resp := map[string]any{"cmd": entries[0].Cmd, "language": entries[0].Language, "stored": true}
+	if ids != nil {
+		resp["ids"] = ids
+	}
+	writeJSON(w, resp)
+}
+
+func (h *Handler) batch(w http.ResponseWriter, r *http.Request) {
+	entries, ok := h.decode(w, r)
+	if !ok {
+		return
+	}
+	ids, ok := h.append(w, r, entries)
+	if !ok {
+		return
+	}
+	resp := map[string]any{"stored": len(entries)}
+	if ids != nil {
+		resp["ids"] = ids
+	}
+	writeJSON(w, resp)
+}
+
+// append validates and stamps entries, forwards them and stores them. With
+// a Confirmer store it returns the IDs of the entries, and skips those
+// already stored.
+func (h *Handler) append(w http.ResponseWriter, r *http.Request, entries []nfo.LogEntry) ([]string, bool) {
+	now := time.Now().UTC()
+	for i := range entries {
+		e := &entries[i]
+		if e.Cmd == "" {
+			http.Error(w, fmt.Sprintf("entry %d: cmd is required", i), http.StatusUnprocessableEntity)
+			return nil, false
+		}
+		if e.Timestamp == nil {
+			e.Timestamp = &now
+		}
+		if e.Level == 0 {
+			e.Level = nfo.LevelInfo
+			if (e.Success != nil && !*e.Success) || e.Error != "" {
+				e.Level = nfo.LevelError
+			}
+		}
+	}
+	ids, entries, err := h.identify(r.Context(), entries)
+	if err != nil {
+		http.Error(w, "store: "+err.Error(), http.StatusServiceUnavailable)
+		return nil, false
+	}
+	if h.forward != nil && len(entries) > 0 {
+		if err := h.forward.Forward(entries); err != nil {
+			http.Error(w, "forward: "+err.Error(), http.StatusServiceUnavailable)
+			return nil, false
+		}
+	}
+	if h.store != nil && len(entries) > 0 {
+		if err := h.store.Append(r.Context(), entries); err != nil {
+			http.Error(w, "store: "+err.Error(), http.StatusServiceUnavailable)
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// identify gives entries without an ID one and returns the IDs of all
+// entries along with the entries not stored yet, if the store is a
+// Confirmer. A re-sent entry is thus acknowledged again but kept once.
+func (h *Handler) identify(ctx context.Context, entries []nfo.LogEntry) ([]string, []nfo.LogEntry, error) {
+	c := h.confirmer()
+	if c == nil {
+		return nil, entries, nil
+	}
+	ids := make([]string, len(entries))
+	for i := range entries {
+		if entries[i].ID == "" {
+			entries[i].ID = nfo.NewULID()
+		}
+		ids[i] = entries[i].ID
+	}
+	stored, err := c.Confirm(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[string]bool, len(stored))
+	for _, id := range stored {
+		seen[id] = true
+	}
+	var fresh []nfo.LogEntry
+	for _, e := range entries {
+		if !seen[e.ID] {
+			seen[e.ID] = true // once per request, too
+			fresh = append(fresh, e)
+		}
+	}
+	return ids, fresh, nil
+}
+
+// confirmer returns the store as a Confirmer, or nil.
+func (h *Handler) confirmer() Confirmer {
+	c, _ := h.store.(Confirmer)
+	return c
+}
+
+func (h *Handler) ack(w http.ResponseWriter, r *http.Request) {
+	var req nfo.Acks
+	if err := json.NewDecoder(io.LimitReader(r.Body, h.maxBody)).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	stored, err := h.confirmer().Confirm(r.Context(), req.IDs)
+	if err != nil {
+		http.Error(w, "store: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if stored == nil {
+		stored = []string{}
+	}
+	writeJSON(w, nfo.Acks{IDs: stored})
+}
+
+func (h *Handler) query(w http.ResponseWriter, r *http.Request) {
+	params, err := parseQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entries, err := h.store.Query(r.Context(), params)
+	if err != nil {
+		http.Error(w, "store: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []nfo.LogEntry{}
+	}
+	writeJSON(w, entries)
+}
+
+func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, map[string]string{"status": "ok", "version": h.version})
+}
+
+func (h *Handler) time(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, nfo.ServerTime{Time: time.Now().UTC()})
+}
+
+func (h *Handler) capabilities(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, nfo.Capabilities{
+		APIVersion:       nfo.APIVersion,
+		Codecs:           []string{nfo.ContentTypeJSON, nfo.ContentTypeNDJSON, nfo.ContentTypeMsgpack},
+		Compression:      []string{"gzip"},
+		Batch:            true,
+		Query:            h.store != nil,
+		Acknowledgements: h.confirmer() != nil,
+	})
+}
+
+// decode reads a request body, gunzipping it if needed, with the codec
+// named by its Content-Type.
+func (h *Handler) decode(w http.ResponseWriter, r *http.Request) ([]nfo.LogEntry, bool) {
+	codec, ok := nfo.CodecFor(r.Header.Get("Content-Type"))
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+	var body io.Reader = r.Body
+	switch r.Header.Get("Content-Encoding") {
+	case "":
+	case "gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+		defer zr.Close()
+		body = zr
+	default:
+		http.Error(w, "unsupported Content-Encoding", http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+	data, err := io.ReadAll(io.LimitReader(body, h.maxBody+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if int64(len(data)) > h.maxBody {
+		http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	entries, err := codec.Unmarshal(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return nil, false
+	}
+	return entries, true
+}
+
+// parseQuery decodes the filters of GET /logs.
+func parseQuery(q url.Values) (nfo.QueryParams, error) {
+	params := nfo.QueryParams{
+		Cmd:           q.Get("cmd"),
+		Env:           q.Get("env"),
+		CorrelationID: q.Get("correlation_id"),
+		Limit:         DefaultLimit,
+	}
+	var errs []error
+	if s := q.Get("success"); s != "" {
+		b, err := strconv.ParseBool(s)
+		errs = append(errs, err)
+		params.Success = &b
+	}
+	if s := q.Get("level"); s != "" {
+		var err error
+		params.Level, err = nfo.ParseLevel(s)
+		errs = append(errs, err)
+	}
+	for key, t := range map[string]*time.Time{"since": &params.Since, "until": &params.Until} {
+		if s := q.Get(key); s != "" {
+			var err error
+			*t, err = time.Parse(time.RFC3339Nano, s)
+			errs = append(errs, err)
+		}
+	}
+	for key, n := range map[string]*int{"limit": &params.Limit, "offset": &params.Offset} {
+		if s := q.Get(key); s != "" {
+			v, err := strconv.Atoi(s)
+			if err == nil && v < 0 {
+				err = fmt.Errorf("%s must not be negative", key)
+			}
+			errs = append(errs, err)
+			*n = v
+		}
+	}
+	if params.Limit == 0 {
+		params.Limit = DefaultLimit
+	}
+	params.Limit = min(params.Limit, MaxLimit)
+	return params, errors.Join(errs...)
+}
+
+// Match reports whether entry passes the filters of params, ignoring Limit
+// and Offset. An entry without Success counts as successful. Stores without
+// a query language of their own can use it.
+func Match(params nfo.QueryParams, entry nfo.LogEntry) bool {
+	switch {
+	case params.Cmd != "" && entry.Cmd != params.Cmd,
+		params.Env != "" && entry.Env != params.Env,
+		params.Success != nil && (entry.Success == nil || *entry.Success) != *params.Success,
+		params.Level != 0 && entry.Level != params.Level,
+		params.CorrelationID != "" && entry.CorrelationID != params.CorrelationID:
+		return false
+	}
+	if entry.Timestamp != nil {
+		if !params.Since.IsZero() && entry.Timestamp.Before(params.Since) ||
+			!params.Until.IsZero() && entry.Timestamp.After(params.Until) {
+			return false
+		}
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
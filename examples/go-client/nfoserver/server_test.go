@@ -0,0 +1,171 @@
+package nfoserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+func newServer(t *testing.T, cfg Config) (*Handler, *httptest.Server) {
+	t.Helper()
+	h := NewHandler(cfg)
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return h, srv
+}
+
+func TestHandlerIngestAndQuery(t *testing.T) {
+	_, srv := newServer(t, Config{})
+	ctx := context.Background()
+
+	for _, codec := range []nfo.Codec{nfo.JSONCodec, nfo.NDJSONCodec, nfo.MsgpackCodec} {
+		client := nfo.NewClient(srv.URL, nfo.WithCodec(codec), nfo.WithCompression(1), nfo.WithNegotiation(0), nfo.WithEnv("prod"))
+		ok := false
+		if err := client.LogContext(nfo.WithCorrelationID(ctx, "job-1"), nfo.LogEntry{Cmd: "deploy", Success: &ok, Error: "boom"}); err != nil {
+			t.Fatalf("%s: Log: %v", codec.ContentType(), err)
+		}
+		if err := client.LogBatch([]nfo.LogEntry{{Cmd: "build"}, {Cmd: "test", Env: "ci"}}); err != nil {
+			t.Fatalf("%s: LogBatch: %v", codec.ContentType(), err)
+		}
+	}
+
+	client := nfo.NewClient(srv.URL)
+	if h, err := client.Ping(ctx); err != nil || h.Version != "nfoserver" {
+		t.Fatalf("Ping = %+v, %v", h, err)
+	}
+	failed := false
+	got, err := client.Query(ctx, nfo.QueryParams{Env: "prod", Success: &failed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0].Cmd != "deploy" || got[0].Level != nfo.LevelError || got[0].CorrelationID != "job-1" || got[0].Timestamp == nil {
+		t.Fatalf("failed prod entries = %+v", got)
+	}
+
+	var cmds []string
+	for e, err := range client.QueryAll(ctx, nfo.QueryParams{Limit: 2, Since: time.Now().Add(-time.Minute)}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		cmds = append(cmds, e.Cmd)
+	}
+	if strings.Join(cmds, ",") != "deploy,build,test,deploy,build,test,deploy,build,test" {
+		t.Fatalf("QueryAll = %v", cmds)
+	}
+
+	if got, _ := client.Query(ctx, nfo.QueryParams{Env: "ci", Level: nfo.LevelInfo, Limit: 1, Offset: 2}); len(got) != 1 || got[0].Cmd != "test" {
+		t.Fatalf("paged query = %+v", got)
+	}
+	if got, _ := client.Query(ctx, nfo.QueryParams{Until: time.Now().Add(-time.Hour)}); len(got) != 0 {
+		t.Fatalf("until filter = %+v", got)
+	}
+}
+
+func TestHandlerRejects(t *testing.T) {
+	h, srv := newServer(t, Config{MaxBodyBytes: 64})
+	for _, tc := range []struct {
+		method, path, contentType, body string
+		status                          int
+	}{
+		{"POST", "/log", "application/json", `{"args":[]}`, http.StatusUnprocessableEntity},
+		{"POST", "/log", "application/json", `[{"cmd":"a"},{"cmd":"b"}]`, http.StatusUnprocessableEntity},
+		{"POST", "/log", "application/json", `{"cmd":`, http.StatusUnprocessableEntity},
+		{"POST", "/log", "text/plain", `cmd=a`, http.StatusUnsupportedMediaType},
+		{"POST", "/logs/batch", "application/json", `[{"cmd":"` + strings.Repeat("x", 100) + `"}]`, http.StatusRequestEntityTooLarge},
+		{"GET", "/logs?level=loud", "", "", http.StatusBadRequest},
+		{"GET", "/logs?limit=-1", "", "", http.StatusBadRequest},
+		{"GET", "/logs?since=yesterday", "", "", http.StatusBadRequest},
+		{"DELETE", "/logs", "", "", http.StatusMethodNotAllowed},
+	} {
+		req, _ := http.NewRequest(tc.method, srv.URL+tc.path, strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s %s %s: status %d, want %d", tc.method, tc.path, tc.body, resp.StatusCode, tc.status)
+		}
+	}
+	if n := h.Store().(*MemoryStore).Len(); n != 0 {
+		t.Fatalf("rejected requests stored %d entries", n)
+	}
+}
+
+type failingStore struct{ MemoryStore }
+
+func (*failingStore) Append(context.Context, []nfo.LogEntry) error { return errors.New("disk full") }
+
+func TestHandlerStoreError(t *testing.T) {
+	_, srv := newServer(t, Config{Store: &failingStore{}})
+	err := nfo.NewClient(srv.URL, nfo.WithRetry(1, 0)).Log(nfo.LogEntry{Cmd: "a"})
+	var apiErr *nfo.APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, nfo.ErrServiceUnavailable) || !strings.Contains(apiErr.Body, "disk full") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestParseQueryLimits(t *testing.T) {
+	for raw, want := range map[string]int{"": DefaultLimit, "limit=0": DefaultLimit, "limit=7": 7, "limit=5000": MaxLimit} {
+		req := httptest.NewRequest("GET", "/logs?"+raw, nil)
+		params, err := parseQuery(req.URL.Query())
+		if err != nil || params.Limit != want {
+			t.Errorf("%q: limit %d, %v; want %d", raw, params.Limit, err, want)
+		}
+	}
+}
+
+func TestHandlerAcknowledgements(t *testing.T) {
+	h, srv := newServer(t, Config{})
+	ctx := context.Background()
+	client := nfo.NewClient(srv.URL, nfo.WithAcknowledgements(), nfo.WithNegotiation(0))
+
+	if err := client.Log(nfo.LogEntry{Cmd: "transfer", ID: "t-1"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	// A retry after a lost response re-sends the entry.
+	if err := client.LogBatch([]nfo.LogEntry{{Cmd: "transfer", ID: "t-1"}, {Cmd: "audit"}}); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+	if n := h.Store().(*MemoryStore).Len(); n != 2 {
+		t.Fatalf("stored %d entries, want 2", n)
+	}
+	got, err := client.Confirm(ctx, []string{"t-1", "t-2"})
+	if err != nil || strings.Join(got, ",") != "t-1" {
+		t.Fatalf("Confirm = %v, %v", got, err)
+	}
+	caps, err := client.Capabilities(ctx)
+	if err != nil || !caps.Acknowledgements {
+		t.Fatalf("Capabilities = %+v, %v", caps, err)
+	}
+
+	// A pure relay cannot confirm entries.
+	f, err := NewForwarder(nfo.NewClient(srv.URL), ForwarderConfig{FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close(ctx)
+	_, relay := newServer(t, Config{Forwarder: f})
+	client = nfo.NewClient(relay.URL, nfo.WithAcknowledgements(), nfo.WithNegotiation(0))
+	if err := client.Log(nfo.LogEntry{Cmd: "transfer"}); !errors.Is(err, nfo.ErrUnsupported) {
+		t.Fatalf("Log through a relay = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestHandlerTime(t *testing.T) {
+	_, srv := newServer(t, Config{})
+	client := nfo.NewClient(srv.URL, nfo.WithClock(func() time.Time { return time.Now().Add(-time.Minute) }))
+	skew, err := client.SyncClock(context.Background())
+	if err != nil || (skew+time.Minute).Abs() > time.Second {
+		t.Fatalf("SyncClock = %v, %v, want about -1m", skew, err)
+	}
+}
@@ -0,0 +1,244 @@
+// Package nfoslog adapts log/slog to nfo: records logged through a slog.Logger
+// backed by Handler are converted to nfo.LogEntry values and shipped through
+// an nfo client.
+//
+//	client := nfo.NewAsyncClient(nfo.NewNfoClient(url), nfo.AsyncConfig{})
+//	slog.SetDefault(slog.New(nfoslog.NewHandler(client, nil)))
+package nfoslog
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// DefaultFieldMap routes well-known attribute keys to LogEntry fields.
+var DefaultFieldMap = map[string]string{
+	"cmd":         "cmd",
+	"args":        "args",
+	"language":    "language",
+	"env":         "env",
+	"success":     "success",
+	"duration_ms": "duration_ms",
+	"duration":    "duration_ms",
+	"output":      "output",
+	"error":       "error",
+	"err":         "error",
+}
+
+// Options configures a Handler.
+type Options struct {
+	// Level is the minimum level that is shipped (default slog.LevelInfo).
+	Level slog.Leveler
+	// Env is stamped on every entry (default $NFO_ENV or "prod").
+	Env string
+	// FieldMap maps attribute keys, including group prefixes such as
+	// "job.cmd", to LogEntry JSON field names: "cmd", "args", "language",
+	// "env", "success", "duration_ms", "output" and "error". Attributes
+	// without a mapping are stored in Fields. Nil selects DefaultFieldMap.
+	FieldMap map[string]string
+}
+
+// Handler is a slog.Handler that forwards records to an nfo.Logger.
+//
+// The record message becomes Cmd unless an attribute maps to "cmd".
+// The slog level sets Level, and records at slog.LevelError or above are
+// marked Success=false.
+type Handler struct {
+	logger nfo.Logger
+	opts   Options
+	attrs  []groupedAttr
+	prefix string
+}
+
+// groupedAttr is an attribute added by WithAttrs together with the group
+// prefix that was open at the time.
+type groupedAttr struct {
+	prefix string
+	attr   slog.Attr
+}
+
+// NewHandler returns a Handler that ships records through logger.
+// A nil opts selects the defaults.
+func NewHandler(logger nfo.Logger, opts *Options) *Handler {
+	h := &Handler{logger: logger}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Level == nil {
+		h.opts.Level = slog.LevelInfo
+	}
+	if h.opts.Env == "" {
+		h.opts.Env = os.Getenv("NFO_ENV")
+	}
+	if h.opts.Env == "" {
+		h.opts.Env = "prod"
+	}
+	if h.opts.FieldMap == nil {
+		h.opts.FieldMap = DefaultFieldMap
+	}
+	return h
+}
+
+// Enabled reports whether level meets the configured minimum.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.opts.Level.Level()
+}
+
+// Handle converts r into a LogEntry and logs it. When the logger supports
+// LogContext (NfoClient, AsyncClient), ctx is passed along so the entry is
+// linked to the active trace.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	entry := nfo.LogEntry{
+		Cmd:      r.Message,
+		Language: "go",
+		Env:      h.opts.Env,
+		Level:    level(r.Level),
+	}
+	if !r.Time.IsZero() {
+		t := r.Time.UTC()
+		entry.Timestamp = &t
+	}
+	if r.Level >= slog.LevelError {
+		failed := false
+		entry.Success = &failed
+	}
+
+	for _, ga := range h.attrs {
+		h.apply(&entry, ga.prefix, ga.attr)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		h.apply(&entry, h.prefix, a)
+		return true
+	})
+	if cl, ok := h.logger.(contextLogger); ok {
+		return cl.LogContext(ctx, entry)
+	}
+	return h.logger.Log(entry)
+}
+
+// contextLogger is implemented by nfo loggers that accept a context.
+type contextLogger interface {
+	LogContext(ctx context.Context, entry nfo.LogEntry) error
+}
+
+// WithAttrs returns a Handler that adds attrs to every record.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.attrs = make([]groupedAttr, 0, len(h.attrs)+len(attrs))
+	h2.attrs = append(h2.attrs, h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, groupedAttr{prefix: h.prefix, attr: a})
+	}
+	return &h2
+}
+
+// WithGroup returns a Handler that qualifies later attribute keys with name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// apply resolves a and stores it on entry, flattening groups.
+func (h *Handler) apply(entry *nfo.LogEntry, prefix string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			h.apply(entry, prefix, ga)
+		}
+		return
+	}
+
+	key := prefix + a.Key
+	v := a.Value
+	switch h.opts.FieldMap[key] {
+	case "cmd":
+		entry.Cmd = v.String()
+	case "args":
+		if args, ok := v.Any().([]string); ok {
+			entry.Args = append(entry.Args, args...)
+		} else {
+			entry.Args = append(entry.Args, v.String())
+		}
+	case "language":
+		entry.Language = v.String()
+	case "env":
+		entry.Env = v.String()
+	case "success":
+		if v.Kind() == slog.KindBool {
+			ok := v.Bool()
+			entry.Success = &ok
+		}
+	case "duration_ms":
+		if ms, ok := durationMs(v); ok {
+			entry.DurationMs = &ms
+		}
+	case "output":
+		entry.Output = v.String()
+	case "error":
+		entry.Error = v.String()
+	default:
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]any)
+		}
+		entry.Fields[key] = fieldValue(v)
+	}
+}
+
+// fieldValue converts v into something encoding/json renders sensibly.
+func fieldValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return v.Any()
+}
+
+// level maps a slog level to the nfo level covering it.
+func level(l slog.Level) nfo.Level {
+	switch {
+	case l < slog.LevelInfo:
+		return nfo.LevelDebug
+	case l < slog.LevelWarn:
+		return nfo.LevelInfo
+	case l < slog.LevelError:
+		return nfo.LevelWarn
+	}
+	return nfo.LevelError
+}
+
+func durationMs(v slog.Value) (float64, bool) {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return float64(v.Duration()) / float64(time.Millisecond), true
+	case slog.KindFloat64:
+		return v.Float64(), true
+	case slog.KindInt64:
+		return float64(v.Int64()), true
+	case slog.KindUint64:
+		return float64(v.Uint64()), true
+	}
+	return 0, false
+}
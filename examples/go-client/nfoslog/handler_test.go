@@ -0,0 +1,109 @@
+package nfoslog
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+type captureLogger struct {
+	entries []nfo.LogEntry
+}
+
+func (c *captureLogger) Log(entry nfo.LogEntry) error {
+	c.entries = append(c.entries, entry)
+	return nil
+}
+
+func TestHandlerMapsFields(t *testing.T) {
+	capture := &captureLogger{}
+	logger := slog.New(NewHandler(capture, &Options{Env: "ci"}))
+
+	logger.Error("deploy failed",
+		"cmd", "deploy",
+		"args", []string{"prod"},
+		"duration", 1500*time.Millisecond,
+		"err", errors.New("timeout"),
+		"region", "eu",
+	)
+
+	if len(capture.entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(capture.entries))
+	}
+	e := capture.entries[0]
+	if e.Cmd != "deploy" || e.Env != "ci" || e.Language != "go" {
+		t.Errorf("unexpected identity fields: %+v", e)
+	}
+	if e.Success == nil || *e.Success {
+		t.Errorf("error record should have success=false, got %v", e.Success)
+	}
+	if e.Level != nfo.LevelError {
+		t.Errorf("unexpected level: %v", e.Level)
+	}
+	if e.Timestamp == nil || time.Since(*e.Timestamp) > time.Minute || e.Timestamp.Location() != time.UTC {
+		t.Errorf("expected the record time in UTC, got %v", e.Timestamp)
+	}
+	if e.DurationMs == nil || *e.DurationMs != 1500 {
+		t.Errorf("unexpected duration: %v", e.DurationMs)
+	}
+	if e.Error != "timeout" {
+		t.Errorf("unexpected error: %q", e.Error)
+	}
+	if !slices.Equal(e.Args, []string{"prod"}) {
+		t.Errorf("unexpected args: %v", e.Args)
+	}
+	if e.Fields["region"] != "eu" {
+		t.Errorf("unexpected fields: %v", e.Fields)
+	}
+}
+
+func TestHandlerLevelFilter(t *testing.T) {
+	capture := &captureLogger{}
+	logger := slog.New(NewHandler(capture, &Options{Level: slog.LevelWarn}))
+
+	logger.Info("ignored")
+	logger.Warn("kept")
+
+	if len(capture.entries) != 1 || capture.entries[0].Cmd != "kept" {
+		t.Fatalf("unexpected entries: %+v", capture.entries)
+	}
+}
+
+func TestHandlerGroupsAndCustomFieldMap(t *testing.T) {
+	capture := &captureLogger{}
+	logger := slog.New(NewHandler(capture, &Options{
+		FieldMap: map[string]string{"job.name": "cmd"},
+	}))
+
+	logger.WithGroup("job").With("name", "backup").Info("started", "id", 7)
+
+	e := capture.entries[0]
+	if e.Cmd != "backup" {
+		t.Errorf("expected cmd from job.name, got %q", e.Cmd)
+	}
+	if e.Fields["job.id"] != int64(7) {
+		t.Errorf("unexpected fields: %v", e.Fields)
+	}
+}
+
+func TestHandlerLevels(t *testing.T) {
+	capture := &captureLogger{}
+	logger := slog.New(NewHandler(capture, &Options{Level: slog.LevelDebug}))
+
+	logger.Debug("d")
+	logger.Info("i")
+	logger.Warn("w")
+	logger.Log(context.Background(), slog.LevelWarn+2, "w+2")
+
+	want := []nfo.Level{nfo.LevelDebug, nfo.LevelInfo, nfo.LevelWarn, nfo.LevelWarn}
+	for i, level := range want {
+		if got := capture.entries[i].Level; got != level {
+			t.Errorf("%s: level = %v, want %v", capture.entries[i].Cmd, got, level)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/wronai/lg/examples/go-client/nfosqlite
+
+go 1.23
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/wronai/lg/examples/go-client v0.0.0
+)
+
+replace github.com/wronai/lg/examples/go-client => ../
@@ -0,0 +1,235 @@
+// Package nfosqlite is an nfoserver.Store that keeps entries in a SQLite
+// database, so an embedded collector keeps them across restarts:
+//
+//	store, err := nfosqlite.Open("nfo.db")
+//	if err != nil { ... }
+//	defer store.Close()
+//	http.ListenAndServe(":8080", nfoserver.NewHandler(nfoserver.Config{Store: store}))
+//
+// It lives in its own module so the core client does not depend on cgo or a
+// SQLite driver.
+package nfosqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfoserver"
+)
+
+// schema stores each entry as its JSON encoding, with the columns queries
+// filter on broken out and indexed. ts is the timestamp in Unix
+// nanoseconds, so it sorts and compares as a number. entry_id is
+// LogEntry.ID, unique so that a re-sent entry is stored once.
+const schema = `
+CREATE TABLE IF NOT EXISTS entries (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	ts             INTEGER,
+	cmd            TEXT NOT NULL,
+	env            TEXT NOT NULL,
+	level          INTEGER NOT NULL,
+	success        INTEGER,
+	correlation_id TEXT NOT NULL,
+	entry          TEXT NOT NULL,
+	entry_id       TEXT
+);
+CREATE INDEX IF NOT EXISTS entries_ts ON entries (ts);
+CREATE INDEX IF NOT EXISTS entries_cmd ON entries (cmd);
+CREATE INDEX IF NOT EXISTS entries_correlation_id ON entries (correlation_id);
+`
+
+// migrations bring databases created by earlier versions up to schema.
+// Each runs only if its probe query fails.
+var migrations = []struct{ probe, stmt string }{
+	{"SELECT entry_id FROM entries LIMIT 0", "ALTER TABLE entries ADD COLUMN entry_id TEXT"},
+}
+
+// indexes are created after migrations, since they may need new columns.
+const indexes = `
+CREATE UNIQUE INDEX IF NOT EXISTS entries_entry_id ON entries (entry_id) WHERE entry_id IS NOT NULL;
+`
+
+// maxParams bounds the IDs per Confirm query, below SQLite's limit on
+// bound parameters.
+const maxParams = 500
+
+// Store keeps entries in a SQLite database.
+type Store struct {
+	db *sql.DB
+}
+
+var (
+	_ nfoserver.Store     = (*Store)(nil)
+	_ nfoserver.Confirmer = (*Store)(nil)
+)
+
+// Open opens or creates the database at path and its schema. The database
+// is switched to write-ahead logging so queries do not block ingestion.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("nfosqlite: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("nfosqlite: create schema: %w", err)
+	}
+	for _, m := range migrations {
+		if _, err := db.Exec(m.probe); err == nil {
+			continue
+		}
+		if _, err := db.Exec(m.stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("nfosqlite: migrate schema: %w", err)
+		}
+	}
+	if _, err := db.Exec(indexes); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("nfosqlite: create schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Append implements nfoserver.Store, inserting entries in one transaction.
+// Entries with the ID of a stored entry are skipped.
+func (s *Store) Append(ctx context.Context, entries []nfo.LogEntry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	stmt, err := tx.PrepareContext(ctx, `INSERT OR IGNORE INTO entries (ts, cmd, env, level, success, correlation_id, entry, entry_id) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		var ts, success, id any
+		if e.ID != "" {
+			id = e.ID
+		}
+		if e.Timestamp != nil {
+			ts = e.Timestamp.UnixNano()
+		}
+		if e.Success != nil {
+			success = *e.Success
+		}
+		if _, err := stmt.ExecContext(ctx, ts, e.Cmd, e.Env, int(e.Level), success, e.CorrelationID, string(data), id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Query implements nfoserver.Store.
+func (s *Store) Query(ctx context.Context, params nfo.QueryParams) ([]nfo.LogEntry, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		where = append(where, cond)
+		args = append(args, arg)
+	}
+	if params.Cmd != "" {
+		add("cmd = ?", params.Cmd)
+	}
+	if params.Env != "" {
+		add("env = ?", params.Env)
+	}
+	if params.Success != nil {
+		add("coalesce(success, 1) = ?", *params.Success)
+	}
+	if params.Level != 0 {
+		add("level = ?", int(params.Level))
+	}
+	if params.CorrelationID != "" {
+		add("correlation_id = ?", params.CorrelationID)
+	}
+	if !params.Since.IsZero() {
+		add("(ts IS NULL OR ts >= ?)", params.Since.UnixNano())
+	}
+	if !params.Until.IsZero() {
+		add("(ts IS NULL OR ts <= ?)", params.Until.UnixNano())
+	}
+	query := "SELECT entry FROM entries"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	limit := params.Limit
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	query += " ORDER BY id LIMIT ? OFFSET ?"
+	args = append(args, limit, params.Offset)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []nfo.LogEntry
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var e nfo.LogEntry
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			return nil, fmt.Errorf("entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Confirm implements nfoserver.Confirmer.
+func (s *Store) Confirm(ctx context.Context, ids []string) ([]string, error) {
+	found := make(map[string]bool, len(ids))
+	for chunk := range slices.Chunk(ids, maxParams) {
+		args := make([]any, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+		}
+		query := "SELECT entry_id FROM entries WHERE entry_id IN (?" + strings.Repeat(", ?", len(chunk)-1) + ")"
+		rows, err := s.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			found[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	var stored []string
+	for _, id := range ids {
+		if found[id] {
+			stored = append(stored, id)
+		}
+	}
+	return stored, nil
+}
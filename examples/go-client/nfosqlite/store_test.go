@@ -0,0 +1,94 @@
+package nfosqlite
+
+import (
+	"context"
+	"database/sql"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfoserver"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nfo.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(nfoserver.NewHandler(nfoserver.Config{Store: store}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	client := nfo.NewClient(srv.URL, nfo.WithEnv("prod"), nfo.WithFields(map[string]any{"team": "core"}))
+	ok := false
+	client.LogContext(nfo.WithCorrelationID(ctx, "job-1"), nfo.LogEntry{Cmd: "deploy", Success: &ok, Error: "boom"})
+	client.LogBatch([]nfo.LogEntry{{Cmd: "build"}, {Cmd: "test", Env: "ci"}, {Cmd: "build", Level: nfo.LevelWarn}})
+
+	failed := false
+	got, err := client.Query(ctx, nfo.QueryParams{Success: &failed})
+	if err != nil || len(got) != 1 || got[0].Cmd != "deploy" || got[0].CorrelationID != "job-1" ||
+		got[0].Level != nfo.LevelError || got[0].Fields["team"] != "core" {
+		t.Fatalf("failed entries = %+v, %v", got, err)
+	}
+	if got, _ := client.Query(ctx, nfo.QueryParams{Cmd: "build", Level: nfo.LevelWarn}); len(got) != 1 {
+		t.Fatalf("level filter = %+v", got)
+	}
+	if got, _ := client.Query(ctx, nfo.QueryParams{Env: "prod", Limit: 2, Offset: 1}); len(got) != 2 || got[0].Cmd != "build" {
+		t.Fatalf("paged = %+v", got)
+	}
+	if got, _ := client.Query(ctx, nfo.QueryParams{Since: time.Now().Add(time.Hour)}); len(got) != 0 {
+		t.Fatalf("since filter = %+v", got)
+	}
+	srv.Close()
+	store.Close()
+
+	// Entries survive a restart.
+	store, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	got, err = store.Query(ctx, nfo.QueryParams{})
+	if err != nil || len(got) != 4 || got[3].Level != nfo.LevelWarn || got[0].Timestamp == nil {
+		t.Fatalf("after reopen: %+v, %v", got, err)
+	}
+}
+
+func TestStoreConfirm(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nfo.db")
+	// A database created before entry IDs were stored.
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE entries (id INTEGER PRIMARY KEY AUTOINCREMENT, ts INTEGER, cmd TEXT NOT NULL,
+		env TEXT NOT NULL, level INTEGER NOT NULL, success INTEGER, correlation_id TEXT NOT NULL, entry TEXT NOT NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	entries := []nfo.LogEntry{{Cmd: "a", ID: "1"}, {Cmd: "b"}, {Cmd: "c"}, {Cmd: "a again", ID: "1"}}
+	if err := store.Append(ctx, entries); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := store.Query(ctx, nfo.QueryParams{}); len(got) != 3 {
+		t.Fatalf("stored %d entries, want 3", len(got))
+	}
+	ids := make([]string, 1200)
+	for i := range ids {
+		ids[i] = strconv.Itoa(len(ids) - i)
+	}
+	if got, err := store.Confirm(ctx, ids); err != nil || len(got) != 1 || got[0] != "1" {
+		t.Fatalf("Confirm = %v, %v", got, err)
+	}
+}
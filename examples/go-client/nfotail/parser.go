@@ -0,0 +1,290 @@
+package nfotail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// ErrSkip is returned by a Parser for lines that should be ignored without
+// being reported, such as blank lines.
+var ErrSkip = errors.New("nfotail: skip line")
+
+// Parser converts one line of a log file, without its line ending, into an
+// entry.
+type Parser interface {
+	Parse(line string) (nfo.LogEntry, error)
+}
+
+// ParserFunc adapts a function to Parser.
+type ParserFunc func(line string) (nfo.LogEntry, error)
+
+func (f ParserFunc) Parse(line string) (nfo.LogEntry, error) { return f(line) }
+
+// Built-in parsers.
+//
+// Plain uses the whole line as Cmd. JSON reads one JSON object per line and
+// Logfmt key=value pairs, such as written by logrus or Heroku; both map keys
+// to LogEntry fields as described at Regex. Blank lines are skipped.
+var (
+	Plain  Parser = ParserFunc(parsePlain)
+	JSON   Parser = ParserFunc(parseJSON)
+	Logfmt Parser = ParserFunc(parseLogfmt)
+)
+
+// Regex returns a Parser that matches each line against pattern and maps
+// its named groups to LogEntry fields. Lines that do not match fail.
+//
+// Keys map to fields as follows, and all other keys are stored in Fields:
+//
+//	cmd, msg, message          Cmd (the first found; without one, the line)
+//	level, lvl, severity       Level (trace, debug, info, notice, warn, error, fatal, ...)
+//	time, ts, timestamp        Timestamp (RFC 3339 or Unix seconds)
+//	error, err                 Error
+//	duration_ms, duration      DurationMs (milliseconds, or a Go duration like "1.5s")
+//	success                    Success
+//	args, language, env, output, correlation_id, trace_id, span_id
+//
+// Entries at LevelError without a success value are marked failed.
+func Regex(pattern string) (Parser, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("nfotail: %w", err)
+	}
+	names := re.SubexpNames()
+	if !slicesContainNamed(names) {
+		return nil, fmt.Errorf("nfotail: pattern %q has no named groups", pattern)
+	}
+	return ParserFunc(func(line string) (nfo.LogEntry, error) {
+		if strings.TrimSpace(line) == "" {
+			return nfo.LogEntry{}, ErrSkip
+		}
+		m := re.FindStringSubmatch(line)
+		if m == nil {
+			return nfo.LogEntry{}, errors.New("line does not match pattern")
+		}
+		values := make(map[string]any, len(names))
+		for i, name := range names {
+			if name != "" && m[i] != "" {
+				values[name] = m[i]
+			}
+		}
+		return fromMap(values, line)
+	}), nil
+}
+
+func slicesContainNamed(names []string) bool {
+	for _, name := range names {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePlain(line string) (nfo.LogEntry, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nfo.LogEntry{}, ErrSkip
+	}
+	return nfo.LogEntry{Cmd: line}, nil
+}
+
+func parseJSON(line string) (nfo.LogEntry, error) {
+	if strings.TrimSpace(line) == "" {
+		return nfo.LogEntry{}, ErrSkip
+	}
+	var values map[string]any
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&values); err != nil {
+		return nfo.LogEntry{}, fmt.Errorf("json: %w", err)
+	}
+	return fromMap(values, line)
+}
+
+func parseLogfmt(line string) (nfo.LogEntry, error) {
+	if strings.TrimSpace(line) == "" {
+		return nfo.LogEntry{}, ErrSkip
+	}
+	values := make(map[string]any)
+	rest := line
+	for {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+		end := strings.IndexFunc(rest, func(r rune) bool { return r == '=' || unicode.IsSpace(r) })
+		if end == 0 {
+			return nfo.LogEntry{}, fmt.Errorf("logfmt: unexpected %q", rest[:1])
+		}
+		if end < 0 {
+			end = len(rest)
+		}
+		key := rest[:end]
+		rest = rest[end:]
+		if !strings.HasPrefix(rest, "=") {
+			values[key] = true // a bare key is a flag
+			continue
+		}
+		rest = rest[1:]
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nfo.LogEntry{}, fmt.Errorf("logfmt: value of %s: %w", key, err)
+			}
+			values[key], _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+			continue
+		}
+		end = strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		values[key] = rest[:end]
+		rest = rest[end:]
+	}
+	return fromMap(values, line)
+}
+
+// fromMap builds an entry from parsed key/value pairs; see Regex for the
+// mapping. line is the Cmd of entries without a message.
+func fromMap(values map[string]any, line string) (nfo.LogEntry, error) {
+	var entry nfo.LogEntry
+	take := func(keys ...string) (any, bool) {
+		for _, key := range keys {
+			if v, ok := values[key]; ok {
+				delete(values, key)
+				return v, true
+			}
+		}
+		return nil, false
+	}
+	str := func(keys ...string) string {
+		v, _ := take(keys...)
+		switch v := v.(type) {
+		case nil:
+			return ""
+		case string:
+			return v
+		}
+		return fmt.Sprint(v)
+	}
+
+	entry.Cmd = str("cmd")
+	if msg := str("msg", "message"); entry.Cmd == "" {
+		entry.Cmd = msg
+	} else if msg != "" {
+		entry.Output = msg
+	}
+	if entry.Cmd == "" {
+		entry.Cmd = strings.TrimSpace(line)
+	}
+	if out := str("output"); out != "" {
+		entry.Output = out
+	}
+	entry.Error = str("error", "err")
+	entry.Language = str("language")
+	entry.Env = str("env")
+	entry.CorrelationID = str("correlation_id")
+	entry.TraceID = str("trace_id")
+	entry.SpanID = str("span_id")
+
+	if v, ok := take("args"); ok {
+		switch v := v.(type) {
+		case []any:
+			for _, a := range v {
+				entry.Args = append(entry.Args, fmt.Sprint(a))
+			}
+		default:
+			entry.Args = strings.Fields(fmt.Sprint(v))
+		}
+	}
+	if s := str("level", "lvl", "severity"); s != "" {
+		level, err := parseLevel(s)
+		if err != nil {
+			return nfo.LogEntry{}, err
+		}
+		entry.Level = level
+	}
+	if s := str("success"); s != "" {
+		ok, err := strconv.ParseBool(s)
+		if err != nil {
+			return nfo.LogEntry{}, fmt.Errorf("success: %w", err)
+		}
+		entry.Success = &ok
+	} else if entry.Level == nfo.LevelError {
+		failed := false
+		entry.Success = &failed
+	}
+	if s := str("duration_ms"); s != "" {
+		ms, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nfo.LogEntry{}, fmt.Errorf("duration_ms: %w", err)
+		}
+		entry.DurationMs = &ms
+	} else if s := str("duration"); s != "" {
+		ms, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			d, derr := time.ParseDuration(s)
+			if derr != nil {
+				return nfo.LogEntry{}, fmt.Errorf("duration: %w", derr)
+			}
+			ms = float64(d) / float64(time.Millisecond)
+		}
+		entry.DurationMs = &ms
+	}
+	if s := str("time", "ts", "timestamp", "@timestamp"); s != "" {
+		ts, err := parseTime(s)
+		if err != nil {
+			return nfo.LogEntry{}, err
+		}
+		entry.Timestamp = &ts
+	}
+
+	if len(values) > 0 {
+		entry.Fields = make(map[string]any, len(values))
+		for k, v := range values {
+			if n, ok := v.(json.Number); ok {
+				if f, err := n.Float64(); err == nil {
+					v = f
+				}
+			}
+			entry.Fields[k] = v
+		}
+	}
+	return entry, nil
+}
+
+// parseLevel accepts the level names of nfo.ParseLevel and common aliases.
+func parseLevel(s string) (nfo.Level, error) {
+	switch strings.ToLower(s) {
+	case "trace", "dbg":
+		return nfo.LevelDebug, nil
+	case "notice", "inf":
+		return nfo.LevelInfo, nil
+	case "wrn":
+		return nfo.LevelWarn, nil
+	case "err", "fatal", "critical", "crit", "panic", "alert", "emerg", "emergency":
+		return nfo.LevelError, nil
+	}
+	return nfo.ParseLevel(s)
+}
+
+// parseTime accepts RFC 3339 and Unix seconds, optionally fractional.
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.UTC(), nil
+	}
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Unix(0, int64(secs*float64(time.Second))).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("time %q is neither RFC 3339 nor Unix seconds", s)
+}
@@ -0,0 +1,102 @@
+package nfotail
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+func TestJSON(t *testing.T) {
+	entry, err := JSON.Parse(`{"time":"2024-05-01T10:00:00Z","level":"WARNING","msg":"disk low","err":"ENOSPC","duration":"1.5s","free_mb":12,"args":["a","b"],"env":"prod"}`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if entry.Cmd != "disk low" || entry.Level != nfo.LevelWarn || entry.Error != "ENOSPC" || entry.Env != "prod" {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if *entry.DurationMs != 1500 || !entry.Timestamp.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("duration=%v timestamp=%v", *entry.DurationMs, entry.Timestamp)
+	}
+	if len(entry.Args) != 2 || entry.Fields["free_mb"] != 12.0 || len(entry.Fields) != 1 {
+		t.Fatalf("args=%v fields=%v", entry.Args, entry.Fields)
+	}
+
+	entry, err = JSON.Parse(`{"cmd":"backup","msg":"done","level":"fatal","ts":1714557600.5}`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if entry.Cmd != "backup" || entry.Output != "done" || entry.Success == nil || *entry.Success {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if entry.Timestamp.UnixMilli() != 1714557600500 {
+		t.Fatalf("timestamp = %v", entry.Timestamp)
+	}
+
+	for _, line := range []string{"not json", `{"level":"loud"}`, `{"time":"yesterday"}`} {
+		if _, err := JSON.Parse(line); err == nil {
+			t.Errorf("Parse(%s): expected an error", line)
+		}
+	}
+	if _, err := JSON.Parse("  "); !errors.Is(err, ErrSkip) {
+		t.Fatalf("blank line: %v", err)
+	}
+}
+
+func TestLogfmt(t *testing.T) {
+	entry, err := Logfmt.Parse(`time=2024-05-01T10:00:00Z level=info msg="user logged in" user=alice duration_ms=12.5 success=true cached`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if entry.Cmd != "user logged in" || entry.Level != nfo.LevelInfo || *entry.DurationMs != 12.5 || !*entry.Success {
+		t.Fatalf("entry = %+v", entry)
+	}
+	if entry.Fields["user"] != "alice" || entry.Fields["cached"] != true {
+		t.Fatalf("fields = %v", entry.Fields)
+	}
+
+	for _, line := range []string{`msg="unterminated`, `=value`, `success=maybe`} {
+		if _, err := Logfmt.Parse(line); err == nil {
+			t.Errorf("Parse(%s): expected an error", line)
+		}
+	}
+}
+
+func TestRegex(t *testing.T) {
+	p, err := Regex(`^(?P<time>\S+) \[(?P<level>\w+)\] (?P<msg>.*?)(?: user=(?P<user>\w+))?$`)
+	if err != nil {
+		t.Fatalf("Regex: %v", err)
+	}
+	entry, err := p.Parse("2024-05-01T10:00:00Z [ERROR] payment failed user=bob")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if entry.Cmd != "payment failed" || entry.Level != nfo.LevelError || entry.Fields["user"] != "bob" {
+		t.Fatalf("entry = %+v", entry)
+	}
+	entry, err = p.Parse("2024-05-01T10:00:01Z [info] started")
+	if err != nil || entry.Fields != nil {
+		t.Fatalf("unmatched optional group: %+v, %v", entry, err)
+	}
+	if _, err := p.Parse("    at com.example.Main"); err == nil {
+		t.Fatal("expected a line that does not match to fail")
+	}
+
+	if _, err := Regex(`(`); err == nil {
+		t.Fatal("expected an invalid pattern to fail")
+	}
+	if _, err := Regex(`^\w+$`); err == nil {
+		t.Fatal("expected a pattern without named groups to fail")
+	}
+}
+
+func TestPlain(t *testing.T) {
+	entry, err := Plain.Parse("  GET /health 200  ")
+	if err != nil || entry.Cmd != "GET /health 200" {
+		t.Fatalf("Parse = %+v, %v", entry, err)
+	}
+	if _, err := Plain.Parse(""); !errors.Is(err, ErrSkip) {
+		t.Fatalf("blank line: %v", err)
+	}
+}
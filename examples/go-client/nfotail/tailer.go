@@ -0,0 +1,298 @@
+// Package nfotail feeds nfo from log files, so programs that only write
+// files can be shipped without changes: a Tailer follows files like
+// tail -F, parses each new line into an entry and logs it.
+//
+//	client := nfo.NewAsyncClient(nfo.NewClient(url), nfo.AsyncConfig{})
+//	defer client.Close()
+//	t, err := nfotail.New(client, nfotail.Config{
+//		Paths:  []string{"/var/log/app/*.log"},
+//		Parser: nfotail.JSON,
+//	})
+//	...
+//	t.Run(ctx)
+//
+// Files are polled, so it works on any filesystem, and rotation is
+// detected whether files are renamed and recreated or copied and truncated.
+package nfotail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// Config configures a Tailer. Zero values select the defaults.
+type Config struct {
+	// Paths are the files to follow, as paths or filepath.Match glob
+	// patterns. Patterns are expanded again on every poll, so files
+	// created later are picked up.
+	Paths []string
+	// Parser converts lines into entries (default Plain).
+	Parser Parser
+	// Fields are added to every entry, under the keys the parsed line
+	// leaves free. The path of the file is added as "file".
+	Fields map[string]any
+	// FromStart reads the files present when the Tailer starts from the
+	// beginning. By default only lines appended later are read; files
+	// that appear later are always read from the beginning.
+	FromStart bool
+	// PollInterval is how often files are checked for new lines (default
+	// 250ms).
+	PollInterval time.Duration
+	// MaxLineBytes caps the length of a line (default 1 MiB); longer lines
+	// are split.
+	MaxLineBytes int
+	// OnError, if set, receives lines that fail to parse, entries the
+	// logger rejects and files that cannot be read. Those lines are
+	// skipped and the Tailer carries on.
+	OnError func(error)
+}
+
+// Tailer follows log files and logs their lines as entries.
+type Tailer struct {
+	logger nfo.Logger
+	cfg    Config
+	files  map[string]*file
+	// moved holds, during a poll, files whose path now names another
+	// file, in case they were renamed to a path that is also followed.
+	moved []*file
+}
+
+// file is an open file being followed.
+type file struct {
+	path    string
+	f       *os.File
+	info    os.FileInfo
+	offset  int64
+	partial []byte // a line not yet ended by a newline
+}
+
+// New returns a Tailer that logs the lines of the files in cfg.Paths
+// through logger, typically an nfo.AsyncClient.
+func New(logger nfo.Logger, cfg Config) (*Tailer, error) {
+	if len(cfg.Paths) == 0 {
+		return nil, errors.New("nfotail: no paths")
+	}
+	for _, pattern := range cfg.Paths {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("nfotail: path %q: %w", pattern, err)
+		}
+	}
+	if cfg.Parser == nil {
+		cfg.Parser = Plain
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 250 * time.Millisecond
+	}
+	if cfg.MaxLineBytes <= 0 {
+		cfg.MaxLineBytes = 1 << 20
+	}
+	return &Tailer{logger: logger, cfg: cfg, files: make(map[string]*file)}, nil
+}
+
+// Run follows the files until ctx is done, and then closes them and
+// returns ctx.Err(). Lines are logged as they are read; with
+// Config.FromStart that starts with the files' existing contents.
+func (t *Tailer) Run(ctx context.Context) error {
+	t.poll(!t.cfg.FromStart)
+	ticker := time.NewTicker(t.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			for path, f := range t.files {
+				f.f.Close()
+				delete(t.files, path)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+			t.poll(false)
+		}
+	}
+}
+
+// poll reads what was appended to every followed file since the last poll.
+// Newly found files are read from their end if skipExisting is set.
+func (t *Tailer) poll(skipExisting bool) {
+	seen := make(map[string]bool, len(t.files))
+	for _, pattern := range t.cfg.Paths {
+		paths, _ := filepath.Glob(pattern) // patterns were checked by New
+		for _, path := range paths {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			t.follow(path, skipExisting)
+		}
+	}
+	for path, f := range t.files {
+		if !seen[path] {
+			t.close(f)
+			delete(t.files, path)
+		}
+	}
+	for _, f := range t.moved {
+		t.close(f)
+	}
+	t.moved = t.moved[:0]
+}
+
+// follow reads new lines of path, opening it or reopening it after
+// rotation as needed.
+func (t *Tailer) follow(path string, skipExisting bool) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return // between a rename and the new file's creation, or a directory
+	}
+	f := t.files[path]
+	if f != nil && !os.SameFile(f.info, info) {
+		// Renamed and recreated: drain the old file and start the new one.
+		// The old one is finished under its new name or after the poll.
+		t.read(f)
+		t.moved = append(t.moved, f)
+		delete(t.files, path)
+		f = nil
+	}
+	if f == nil {
+		f = t.adopt(path, info)
+	}
+	if f == nil {
+		fh, err := os.Open(path)
+		if err != nil {
+			t.report(fmt.Errorf("nfotail: %w", err))
+			return
+		}
+		f = &file{path: path, f: fh, info: info}
+		if skipExisting {
+			f.offset = info.Size()
+		}
+		t.files[path] = f
+	} else if info.Size() < f.offset {
+		// Copied and truncated: start again from the top.
+		t.flushPartial(f)
+		f.offset = 0
+	}
+	f.info = info
+	t.read(f)
+}
+
+// adopt returns the followed file that info describes if it was renamed to
+// path, so that it is read on from where it was rather than from the start.
+func (t *Tailer) adopt(path string, info os.FileInfo) *file {
+	for i, f := range t.moved {
+		if os.SameFile(f.info, info) {
+			t.moved = append(t.moved[:i], t.moved[i+1:]...)
+			f.path = path
+			t.files[path] = f
+			return f
+		}
+	}
+	for old, f := range t.files {
+		if os.SameFile(f.info, info) {
+			delete(t.files, old)
+			f.path = path
+			t.files[path] = f
+			return f
+		}
+	}
+	return nil
+}
+
+// close finishes f: it logs what was written to it since the last read,
+// including an unterminated last line, and closes it.
+func (t *Tailer) close(f *file) {
+	t.read(f)
+	t.flushPartial(f)
+	f.f.Close()
+}
+
+// read logs the complete lines appended to f since its offset.
+func (t *Tailer) read(f *file) {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := f.f.ReadAt(buf, f.offset)
+		f.offset += int64(n)
+		data := buf[:n]
+		for len(data) > 0 {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				f.partial = append(f.partial, data...)
+				if n := len(f.partial) - len(f.partial)%t.cfg.MaxLineBytes; n > 0 {
+					t.lines(f.path, f.partial[:n])
+					f.partial = append(f.partial[:0], f.partial[n:]...)
+				}
+				break
+			}
+			line := data[:i]
+			if len(f.partial) > 0 {
+				line = append(f.partial, line...)
+				f.partial = f.partial[:0]
+			}
+			t.lines(f.path, line)
+			data = data[i+1:]
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.report(fmt.Errorf("nfotail: %w", err))
+			}
+			return
+		}
+	}
+}
+
+// flushPartial logs the unterminated last line of f, if any.
+func (t *Tailer) flushPartial(f *file) {
+	if len(f.partial) > 0 {
+		t.lines(f.path, f.partial)
+		f.partial = f.partial[:0]
+	}
+}
+
+// lines logs line, split into pieces of at most MaxLineBytes.
+func (t *Tailer) lines(path string, line []byte) {
+	for len(line) > t.cfg.MaxLineBytes {
+		t.line(path, line[:t.cfg.MaxLineBytes])
+		line = line[t.cfg.MaxLineBytes:]
+	}
+	t.line(path, line)
+}
+
+// line parses one line and logs it.
+func (t *Tailer) line(path string, line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	entry, err := t.cfg.Parser.Parse(string(line))
+	if errors.Is(err, ErrSkip) {
+		return
+	}
+	if err != nil {
+		t.report(fmt.Errorf("nfotail: %s: %w: %q", path, err, line))
+		return
+	}
+	if entry.Fields == nil {
+		entry.Fields = make(map[string]any, len(t.cfg.Fields)+1)
+	}
+	for k, v := range t.cfg.Fields {
+		if _, ok := entry.Fields[k]; !ok {
+			entry.Fields[k] = v
+		}
+	}
+	if _, ok := entry.Fields["file"]; !ok {
+		entry.Fields["file"] = path
+	}
+	if err := t.logger.Log(entry); err != nil {
+		t.report(fmt.Errorf("nfotail: %s: %w", path, err))
+	}
+}
+
+func (t *Tailer) report(err error) {
+	if t.cfg.OnError != nil {
+		t.cfg.OnError(err)
+	}
+}
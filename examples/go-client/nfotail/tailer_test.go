@@ -0,0 +1,168 @@
+package nfotail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfotest"
+)
+
+// startTailer runs a Tailer over cfg until the test ends.
+func startTailer(t *testing.T, cfg Config) (*nfotest.Recorder, func() []error) {
+	t.Helper()
+	rec := nfotest.NewRecorder()
+	var mu sync.Mutex
+	var errs []error
+	cfg.PollInterval = 5 * time.Millisecond
+	cfg.OnError = func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	}
+	tailer, err := New(rec, cfg)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- tailer.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	time.Sleep(20 * time.Millisecond) // let the first poll find the files
+	return rec, func() []error {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(errs)
+	}
+}
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func waitCmds(t *testing.T, rec *nfotest.Recorder, want ...string) []nfo.LogEntry {
+	t.Helper()
+	entries, _ := rec.WaitForCount(len(want), 2*time.Second)
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Cmd)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("cmds = %q, want %q", got, want)
+	}
+	return entries
+}
+
+func TestTailerFollowsAppends(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "old line\n")
+
+	rec, errs := startTailer(t, Config{
+		Paths:  []string{filepath.Join(dir, "*.log")},
+		Parser: Logfmt,
+		Fields: map[string]any{"service": "billing", "user": "ignored"},
+	})
+	appendFile(t, path, "msg=first user=alice\nmsg=sec")
+	appendFile(t, path, "ond\r\nmsg=\"broken\n")
+	entries := waitCmds(t, rec, "first", "second")
+
+	if entries[0].Fields["file"] != path || entries[0].Fields["service"] != "billing" || entries[0].Fields["user"] != "alice" {
+		t.Fatalf("fields = %v", entries[0].Fields)
+	}
+	if e := errs(); len(e) != 1 {
+		t.Fatalf("errors = %v", e)
+	}
+
+	// Files created later are read from the start.
+	appendFile(t, filepath.Join(dir, "worker.log"), "msg=third\n")
+	waitCmds(t, rec, "first", "second", "third")
+}
+
+func TestTailerFromStart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "one\ntwo\n")
+	rec, _ := startTailer(t, Config{Paths: []string{path}, FromStart: true})
+	waitCmds(t, rec, "one", "two")
+}
+
+func TestTailerRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "")
+	rec, _ := startTailer(t, Config{Paths: []string{path}})
+
+	// Renamed and recreated, with a last line written to the old file.
+	appendFile(t, path, "a\n")
+	waitCmds(t, rec, "a")
+	appendFile(t, path, "b")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path+".1", "\n")
+	appendFile(t, path, "c\n")
+	waitCmds(t, rec, "a", "b", "c")
+
+	// Copied and truncated.
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	appendFile(t, path, "d\n")
+	waitCmds(t, rec, "a", "b", "c", "d")
+}
+
+func TestTailerRenamedWithinPattern(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	appendFile(t, path, "")
+	rec, _ := startTailer(t, Config{Paths: []string{filepath.Join(dir, "app.log*")}})
+
+	appendFile(t, path, "a\n")
+	waitCmds(t, rec, "a")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path+".1", "b\n")
+	appendFile(t, path, "c\n")
+	waitCmds(t, rec, "a", "b", "c")
+	appendFile(t, path+".1", "d\n")
+	waitCmds(t, rec, "a", "b", "c", "d")
+	time.Sleep(30 * time.Millisecond)
+	if rec.Len() != 4 {
+		t.Fatalf("the renamed file was read again: %d entries", rec.Len())
+	}
+}
+
+func TestTailerLongLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "")
+	rec, _ := startTailer(t, Config{Paths: []string{path}, MaxLineBytes: 4})
+	appendFile(t, path, "abcdefghij\n")
+	waitCmds(t, rec, "abcd", "efgh", "ij")
+}
+
+func TestNewErrors(t *testing.T) {
+	if _, err := New(nfotest.NewRecorder(), Config{}); err == nil {
+		t.Fatal("expected an error without paths")
+	}
+	if _, err := New(nfotest.NewRecorder(), Config{Paths: []string{"[a"}}); err == nil {
+		t.Fatal("expected an error for a bad pattern")
+	}
+}
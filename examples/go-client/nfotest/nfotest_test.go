@@ -0,0 +1,200 @@
+package nfotest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+func TestServer(t *testing.T) {
+	srv := NewServer(t)
+	client := nfo.NewClient(srv.URL, nfo.WithCompression(1), nfo.WithRetry(2, time.Millisecond))
+
+	srv.FailNext("/log", http.StatusServiceUnavailable)
+	if err := client.Log(nfo.LogEntry{Cmd: "a"}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if err := client.LogBatch([]nfo.LogEntry{{Cmd: "b"}, {Cmd: "c"}}); err != nil {
+		t.Fatalf("LogBatch: %v", err)
+	}
+	if srv.Requests("/log") != 2 || srv.Len() != 3 {
+		t.Fatalf("requests=%d entries=%d", srv.Requests("/log"), srv.Len())
+	}
+
+	got, err := client.Query(context.Background(), nfo.QueryParams{Cmd: "b"})
+	if err != nil || len(got) != 1 || got[0].Cmd != "b" {
+		t.Fatalf("Query = %+v, %v", got, err)
+	}
+	got, err = client.Query(context.Background(), nfo.QueryParams{Since: time.Now().Add(time.Hour)})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("Query(Since: future) = %+v, %v", got, err)
+	}
+
+	srv.SetStatus("/logs/batch", http.StatusBadRequest)
+	if err := client.LogBatch([]nfo.LogEntry{{Cmd: "d"}}); err == nil {
+		t.Fatal("expected injected failure")
+	}
+	srv.SetStatus("/logs/batch", http.StatusOK)
+	if err := client.LogBatch([]nfo.LogEntry{{Cmd: "d"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestServerCodecs(t *testing.T) {
+	srv := NewServer(t)
+	for _, codec := range []nfo.Codec{nfo.NDJSONCodec, nfo.MsgpackCodec} {
+		client := nfo.NewClient(srv.URL, nfo.WithCodec(codec))
+		if err := client.Log(nfo.LogEntry{Cmd: "one"}); err != nil {
+			t.Fatalf("%s: Log: %v", codec.ContentType(), err)
+		}
+		if err := client.LogBatch([]nfo.LogEntry{{Cmd: "two"}, {Cmd: "three"}}); err != nil {
+			t.Fatalf("%s: LogBatch: %v", codec.ContentType(), err)
+		}
+	}
+	if srv.Len() != 6 || srv.Entries()[5].Cmd != "three" {
+		t.Fatalf("unexpected entries: %+v", srv.Entries())
+	}
+}
+
+func TestServerCapabilities(t *testing.T) {
+	srv := NewServer(t)
+	client := nfo.NewClient(srv.URL, nfo.WithNegotiation(0))
+
+	caps, err := client.Capabilities(context.Background())
+	if err != nil || caps.APIVersion != nfo.APIVersion || !caps.Batch {
+		t.Fatalf("Capabilities = %+v, %v", caps, err)
+	}
+
+	srv.SetCapabilities(nfo.Capabilities{APIVersion: "1", Fields: []string{"cmd", "env"}})
+	if err := client.LogBatch([]nfo.LogEntry{{Cmd: "a"}, {Cmd: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if srv.Requests("/log") != 2 || srv.Requests("/logs/batch") != 0 {
+		t.Fatalf("expected single-entry posts, got %d /log and %d /logs/batch", srv.Requests("/log"), srv.Requests("/logs/batch"))
+	}
+	if e := srv.Entries()[0]; e.Env != "prod" || e.Language != "" || e.Meta != nil {
+		t.Fatalf("unsupported fields not stripped: %+v", e)
+	}
+	if _, err := client.Query(context.Background(), nfo.QueryParams{}); !errors.Is(err, nfo.ErrUnsupported) {
+		t.Fatalf("Query: %v, want ErrUnsupported", err)
+	}
+}
+
+func TestServerAcknowledgements(t *testing.T) {
+	srv := NewServer(t)
+	client := nfo.NewClient(srv.URL, nfo.WithAcknowledgements(), nfo.WithNegotiation(0))
+	if err := client.LogBatch([]nfo.LogEntry{{Cmd: "a", ID: "a-1"}, {Cmd: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := client.Confirm(context.Background(), []string{"a-1", "missing"})
+	if err != nil || !slices.Equal(got, []string{"a-1"}) {
+		t.Fatalf("Confirm = %v, %v", got, err)
+	}
+	if id := srv.Entries()[1].ID; id == "" {
+		t.Fatal("client did not assign an ID")
+	}
+}
+
+func TestServerTime(t *testing.T) {
+	srv := NewServer(t)
+	client := nfo.NewClient(srv.URL, nfo.WithClock(func() time.Time { return time.Now().Add(time.Minute) }))
+	skew, err := client.SyncClock(context.Background())
+	if err != nil || (skew-time.Minute).Abs() > time.Second {
+		t.Fatalf("SyncClock = %v, %v, want about 1m", skew, err)
+	}
+	if n := len(srv.Entries()); n != 0 {
+		t.Fatalf("GET /time recorded %d entries", n)
+	}
+}
+
+func TestServerAttachments(t *testing.T) {
+	srv := NewServer(t)
+	client := nfo.NewClient(srv.URL, nfo.WithTruncation(nfo.TruncateConfig{MaxOutput: 4, Offload: true}))
+	if err := client.Log(nfo.LogEntry{Cmd: "a", Output: "full output"}); err != nil {
+		t.Fatal(err)
+	}
+	id := srv.Entries()[0].Attachments["output"]
+	if content, ok := srv.Attachment(id); !ok || content != "full output" {
+		t.Fatalf("attachment %q = %q, %v", id, content, ok)
+	}
+}
+
+func TestServerStats(t *testing.T) {
+	srv := NewServer(t)
+	client := nfo.NewClient(srv.URL, nfo.WithEnv("prod"))
+	failed := false
+	for i, ms := range []float64{10, 20, 30, 40} {
+		e := nfo.LogEntry{Cmd: "deploy", DurationMs: &ms}
+		if i == 3 {
+			e.Success = &failed
+		}
+		client.Log(e)
+	}
+	client.Log(nfo.LogEntry{Cmd: "migrate", Level: nfo.LevelError})
+	client.Log(nfo.LogEntry{Cmd: "lint", Env: "ci"})
+
+	groups, err := client.Stats(context.Background(), nfo.StatsParams{Env: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []nfo.GroupStats{
+		{Key: "deploy", Count: 4, Failures: 1, SuccessRate: 0.75, DurationP50: 20, DurationP90: 40, DurationP99: 40},
+		{Key: "migrate", Count: 1, Failures: 1, SuccessRate: 0},
+	}
+	if !slices.Equal(groups, want) {
+		t.Fatalf("groups = %+v", groups)
+	}
+
+	groups, _ = client.Stats(context.Background(), nfo.StatsParams{GroupBy: "env"})
+	if len(groups) != 2 || groups[0].Key != "prod" || groups[1].Key != "ci" {
+		t.Fatalf("grouped by env: %+v", groups)
+	}
+}
+
+func TestWaitForCount(t *testing.T) {
+	srv := NewServer(t)
+	async := nfo.NewAsyncClient(nfo.NewClient(srv.URL), nfo.AsyncConfig{FlushInterval: 10 * time.Millisecond})
+	defer async.Close()
+
+	async.Log(nfo.LogEntry{Cmd: "a"})
+	async.Log(nfo.LogEntry{Cmd: "b"})
+	got, ok := srv.WaitForCount(2, 2*time.Second)
+	if !ok || got[1].Cmd != "b" {
+		t.Fatalf("WaitForCount = %+v, %v", got, ok)
+	}
+	if _, ok := srv.WaitForCount(3, 20*time.Millisecond); ok {
+		t.Fatal("expected a timeout")
+	}
+}
+
+func TestRecordingClient(t *testing.T) {
+	client, rec := NewRecordingClient(nfo.WithEnv("test"), nfo.WithRedaction(nfo.RedactConfig{}))
+
+	client.Log(nfo.LogEntry{Cmd: "login", Args: []string{"--password=hunter2"}})
+	_, err := nfo.Call(context.Background(), client, "fail", nil, func() (int, error) {
+		return 0, errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("Call must return fn's error")
+	}
+
+	got := rec.Entries()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(got))
+	}
+	if got[0].Env != "test" || got[0].Args[0] != "--password=[REDACTED]" {
+		t.Fatalf("pipeline not applied: %+v", got[0])
+	}
+	if got[1].Error != "boom" || got[1].Level != nfo.LevelError {
+		t.Fatalf("unexpected entry: %+v", got[1])
+	}
+	rec.Reset()
+	if rec.Len() != 0 {
+		t.Fatal("Reset must clear entries")
+	}
+}
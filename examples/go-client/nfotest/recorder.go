@@ -0,0 +1,113 @@
+// Package nfotest provides test doubles for code that logs through nfo:
+// an in-process fake nfo-service (Server) and an in-memory client that
+// needs no network at all (NewRecordingClient).
+//
+//	func TestCheckout(t *testing.T) {
+//	    client, rec := nfotest.NewRecordingClient()
+//	    checkout(client)
+//	    if got := rec.Entries(); len(got) != 1 || got[0].Cmd != "checkout" {
+//	        t.Fatalf("unexpected entries: %+v", got)
+//	    }
+//	}
+package nfotest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// Recorder collects entries in memory. It is safe for concurrent use and
+// implements both nfo.Logger and nfo.Transport.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []nfo.LogEntry
+	changed chan struct{}
+}
+
+var (
+	_ nfo.Logger    = (*Recorder)(nil)
+	_ nfo.Transport = (*Recorder)(nil)
+)
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{changed: make(chan struct{})}
+}
+
+// NewRecordingClient returns a client whose entries are captured by the
+// returned Recorder instead of being sent. The client's full pipeline
+// (defaults, metadata, hooks, levels, redaction) still applies, so tests
+// see exactly what nfo-service would receive.
+func NewRecordingClient(opts ...nfo.Option) (*nfo.NfoClient, *Recorder) {
+	rec := NewRecorder()
+	opts = append(opts, nfo.WithTransport(rec))
+	return nfo.NewClient("http://nfotest.invalid", opts...), rec
+}
+
+// Log records entry.
+func (r *Recorder) Log(entry nfo.LogEntry) error {
+	r.add([]nfo.LogEntry{entry})
+	return nil
+}
+
+// Send records entries.
+func (r *Recorder) Send(_ context.Context, entries []nfo.LogEntry) error {
+	r.add(entries)
+	return nil
+}
+
+func (r *Recorder) add(entries []nfo.LogEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entries...)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Entries returns a copy of everything recorded so far, in arrival order.
+func (r *Recorder) Entries() []nfo.LogEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]nfo.LogEntry(nil), r.entries...)
+}
+
+// Len returns the number of recorded entries.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Reset forgets all recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// WaitForCount waits until at least n entries were recorded, e.g. from an
+// AsyncClient's background flusher, and returns them. It returns false if
+// the timeout expires first.
+func (r *Recorder) WaitForCount(n int, timeout time.Duration) ([]nfo.LogEntry, bool) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		r.mu.Lock()
+		if len(r.entries) >= n {
+			entries := append([]nfo.LogEntry(nil), r.entries...)
+			r.mu.Unlock()
+			return entries, true
+		}
+		changed := r.changed
+		r.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return r.Entries(), false
+		}
+	}
+}
@@ -0,0 +1,336 @@
+package nfotest
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+// Server is a fake nfo-service. It accepts POST /log and POST /logs/batch
+// in any built-in codec (plain or gzip-compressed), acknowledging entries
+// that carry an ID, stores POST /attachments, serves recorded entries on GET
+// /logs, summarises them on GET /stats, confirms them on POST /logs/ack and
+// answers GET /health, GET /capabilities and GET /time. Point a client at
+// Server.URL.
+type Server struct {
+	*httptest.Server
+	*Recorder
+
+	mu          sync.Mutex
+	fail        map[string][]int
+	status      map[string]int
+	requests    map[string]int
+	attachments map[string]string
+	caps        nfo.Capabilities
+}
+
+// NewServer starts a Server that is closed when the test ends.
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+	s := &Server{
+		Recorder: NewRecorder(),
+		fail:     make(map[string][]int),
+		status:   make(map[string]int),
+		requests: make(map[string]int),
+
+		attachments: make(map[string]string),
+		caps: nfo.Capabilities{
+			APIVersion:       nfo.APIVersion,
+			Codecs:           []string{nfo.ContentTypeJSON, nfo.ContentTypeNDJSON, nfo.ContentTypeMsgpack},
+			Compression:      []string{"gzip"},
+			Batch:            true,
+			Query:            true,
+			Acknowledgements: true,
+		},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// FailNext makes the next len(statuses) requests to path (e.g. "/log")
+// fail with those statuses before recording anything.
+func (s *Server) FailNext(path string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail[path] = append(s.fail[path], statuses...)
+}
+
+// SetStatus makes every request to path fail with status until it is set
+// back to http.StatusOK.
+func (s *Server) SetStatus(path string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status == http.StatusOK {
+		delete(s.status, path)
+		return
+	}
+	s.status[path] = status
+}
+
+// SetCapabilities changes the answer of GET /capabilities, to test how a
+// client adapts to an older or more limited service. It does not change
+// what the server accepts; combine it with SetStatus for that, or use
+// SetStatus("/capabilities", http.StatusNotFound) to emulate a service
+// that predates the endpoint.
+func (s *Server) SetCapabilities(caps nfo.Capabilities) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.caps = caps
+}
+
+// Requests returns how many requests reached path, including failed ones.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// Attachment returns the content uploaded to POST /attachments under id.
+func (s *Server) Attachment(id string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.attachments[id]
+	return content, ok
+}
+
+// injected returns the failure status for a request to path, or 0.
+func (s *Server) injected(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests[path]++
+	if q := s.fail[path]; len(q) > 0 {
+		s.fail[path] = q[1:]
+		return q[0]
+	}
+	return s.status[path]
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if status := s.injected(r.URL.Path); status != 0 {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/log":
+		entries, ok := decodeEntries(w, r)
+		if !ok {
+			return
+		}
+		if len(entries) != 1 {
+			http.Error(w, "expected one entry", http.StatusUnprocessableEntity)
+			return
+		}
+		s.add(entries)
+		writeJSON(w, map[string]any{"cmd": entries[0].Cmd, "stored": true, "ids": ids(entries)})
+	case r.Method == http.MethodPost && r.URL.Path == "/logs/batch":
+		entries, ok := decodeEntries(w, r)
+		if !ok {
+			return
+		}
+		s.add(entries)
+		writeJSON(w, map[string]any{"stored": len(entries), "ids": ids(entries)})
+	case r.Method == http.MethodPost && r.URL.Path == "/logs/ack":
+		var req nfo.Acks
+		if !decode(w, r, &req) {
+			return
+		}
+		recorded := make(map[string]bool)
+		for _, e := range s.Entries() {
+			recorded[e.ID] = true
+		}
+		acks := nfo.Acks{IDs: []string{}}
+		for _, id := range req.IDs {
+			if recorded[id] {
+				acks.IDs = append(acks.IDs, id)
+			}
+		}
+		writeJSON(w, acks)
+	case r.Method == http.MethodPost && r.URL.Path == "/attachments":
+		var a struct{ Content string }
+		if !decode(w, r, &a) {
+			return
+		}
+		s.mu.Lock()
+		id := "att-" + strconv.Itoa(len(s.attachments)+1)
+		s.attachments[id] = a.Content
+		s.mu.Unlock()
+		writeJSON(w, map[string]string{"id": id})
+	case r.Method == http.MethodGet && r.URL.Path == "/logs":
+		writeJSON(w, s.query(r))
+	case r.Method == http.MethodGet && r.URL.Path == "/stats":
+		writeJSON(w, s.stats(r))
+	case r.Method == http.MethodGet && r.URL.Path == "/health":
+		writeJSON(w, map[string]string{"status": "ok", "version": "nfotest"})
+	case r.Method == http.MethodGet && r.URL.Path == "/time":
+		writeJSON(w, nfo.ServerTime{Time: time.Now().UTC()})
+	case r.Method == http.MethodGet && r.URL.Path == "/capabilities":
+		s.mu.Lock()
+		caps := s.caps
+		s.mu.Unlock()
+		writeJSON(w, caps)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// ids returns the IDs of entries that have one, to acknowledge them.
+func ids(entries []nfo.LogEntry) []string {
+	ids := []string{}
+	for _, e := range entries {
+		if e.ID != "" {
+			ids = append(ids, e.ID)
+		}
+	}
+	return ids
+}
+
+// query filters recorded entries by the cmd, env, level, correlation_id,
+// since and until parameters and pages them with limit and offset.
+func (s *Server) query(r *http.Request) []nfo.LogEntry {
+	q := r.URL.Query()
+	since, _ := time.Parse(time.RFC3339Nano, q.Get("since"))
+	until, _ := time.Parse(time.RFC3339Nano, q.Get("until"))
+	result := []nfo.LogEntry{}
+	for _, e := range s.Entries() {
+		if (q.Has("cmd") && e.Cmd != q.Get("cmd")) ||
+			(q.Has("env") && e.Env != q.Get("env")) ||
+			(q.Has("level") && e.Level.String() != q.Get("level")) ||
+			(q.Has("correlation_id") && e.CorrelationID != q.Get("correlation_id")) ||
+			(e.Timestamp != nil && !since.IsZero() && e.Timestamp.Before(since)) ||
+			(e.Timestamp != nil && !until.IsZero() && e.Timestamp.After(until)) {
+			continue
+		}
+		result = append(result, e)
+	}
+	if offset, _ := strconv.Atoi(q.Get("offset")); offset > 0 {
+		result = result[min(offset, len(result)):]
+	}
+	if limit, _ := strconv.Atoi(q.Get("limit")); limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	return result
+}
+
+// stats summarises the entries query selects, grouped by the group_by
+// parameter (default cmd).
+func (s *Server) stats(r *http.Request) []nfo.GroupStats {
+	key := func(e nfo.LogEntry) string { return e.Cmd }
+	switch r.URL.Query().Get("group_by") {
+	case "env":
+		key = func(e nfo.LogEntry) string { return e.Env }
+	case "language":
+		key = func(e nfo.LogEntry) string { return e.Language }
+	case "level":
+		key = func(e nfo.LogEntry) string { return e.Level.String() }
+	}
+
+	groups := map[string]*nfo.GroupStats{}
+	durations := map[string][]float64{}
+	for _, e := range s.query(r) {
+		k := key(e)
+		g := groups[k]
+		if g == nil {
+			g = &nfo.GroupStats{Key: k}
+			groups[k] = g
+		}
+		g.Count++
+		if (e.Success != nil && !*e.Success) || e.Level == nfo.LevelError {
+			g.Failures++
+		}
+		if e.DurationMs != nil {
+			durations[k] = append(durations[k], *e.DurationMs)
+		}
+	}
+
+	result := make([]nfo.GroupStats, 0, len(groups))
+	for k, g := range groups {
+		g.SuccessRate = float64(g.Count-g.Failures) / float64(g.Count)
+		d := durations[k]
+		slices.Sort(d)
+		g.DurationP50, g.DurationP90, g.DurationP99 = percentile(d, 50), percentile(d, 90), percentile(d, 99)
+		result = append(result, *g)
+	}
+	slices.SortFunc(result, func(a, b nfo.GroupStats) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return result
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted.
+func percentile(sorted []float64, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+// decodeEntries decodes a request body with the codec named by its
+// Content-Type.
+func decodeEntries(w http.ResponseWriter, r *http.Request) ([]nfo.LogEntry, bool) {
+	codec, ok := nfo.CodecFor(r.Header.Get("Content-Type"))
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusUnsupportedMediaType), http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return nil, false
+	}
+	entries, err := codec.Unmarshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return nil, false
+	}
+	return entries, true
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	body, ok := readBody(w, r)
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return false
+	}
+	return true
+}
+
+// readBody reads a request body, decompressing it if gzipped.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+		body = zr
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return data, true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,225 @@
+// Package nfozap adapts go.uber.org/zap to nfo: entries written to a
+// zap.Logger backed by Core are converted to nfo.LogEntry values and shipped
+// through an nfo client.
+//
+// It lives in its own module so the core client does not depend on zap:
+//
+//	async := nfo.NewAsyncClient(nfo.NewClient(url), nfo.AsyncConfig{})
+//	logger := zap.New(nfozap.NewCore(async, nil))
+//
+// To keep the existing console or file output, tee the cores:
+//
+//	logger = logger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+//		return zapcore.NewTee(c, nfozap.NewCore(async, nil))
+//	}))
+package nfozap
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+	"github.com/wronai/lg/examples/go-client/nfoslog"
+)
+
+// Options configures a Core.
+type Options struct {
+	// Level is the minimum level that is shipped (default zapcore.InfoLevel).
+	Level zapcore.LevelEnabler
+	// Env is stamped on every entry (default $NFO_ENV or "prod").
+	Env string
+	// FieldMap maps field keys to LogEntry JSON field names, as in
+	// nfoslog.Options. Fields without a mapping are stored in Fields. Nil
+	// selects nfoslog.DefaultFieldMap, which routes zap.Error's "error" key.
+	FieldMap map[string]string
+}
+
+// Core is a zapcore.Core that forwards entries to an nfo.Logger.
+//
+// The message becomes Cmd unless a field maps to "cmd". The zap level sets
+// Level, and entries at zapcore.ErrorLevel or above are marked
+// Success=false. The logger name and stack trace, when present, are stored
+// in Fields as "logger" and "stack".
+type Core struct {
+	logger nfo.Logger
+	opts   Options
+	fields []zapcore.Field
+}
+
+var _ zapcore.Core = (*Core)(nil)
+
+// NewCore returns a Core that ships entries through logger, which should be
+// an nfo.AsyncClient so that logging calls never wait on the network.
+// A nil opts selects the defaults.
+func NewCore(logger nfo.Logger, opts *Options) *Core {
+	c := &Core{logger: logger}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.Level == nil {
+		c.opts.Level = zapcore.InfoLevel
+	}
+	if c.opts.Env == "" {
+		c.opts.Env = os.Getenv("NFO_ENV")
+	}
+	if c.opts.Env == "" {
+		c.opts.Env = "prod"
+	}
+	if c.opts.FieldMap == nil {
+		c.opts.FieldMap = nfoslog.DefaultFieldMap
+	}
+	return c
+}
+
+// Enabled reports whether level meets the configured minimum.
+func (c *Core) Enabled(level zapcore.Level) bool {
+	return c.opts.Level.Enabled(level)
+}
+
+// With returns a Core that adds fields to every entry.
+func (c *Core) With(fields []zapcore.Field) zapcore.Core {
+	if len(fields) == 0 {
+		return c
+	}
+	c2 := *c
+	c2.fields = append(slices.Clip(c.fields), fields...)
+	return &c2
+}
+
+// Check adds c to ce if ent's level is enabled.
+func (c *Core) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write converts ent and fields into a LogEntry and logs it.
+func (c *Core) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	entry := nfo.LogEntry{
+		Cmd:      ent.Message,
+		Language: "go",
+		Env:      c.opts.Env,
+		Level:    level(ent.Level),
+	}
+	if !ent.Time.IsZero() {
+		t := ent.Time.UTC()
+		entry.Timestamp = &t
+	}
+	if ent.Level >= zapcore.ErrorLevel {
+		failed := false
+		entry.Success = &failed
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	if ent.LoggerName != "" {
+		enc.Fields["logger"] = ent.LoggerName
+	}
+	if ent.Stack != "" {
+		enc.Fields["stack"] = ent.Stack
+	}
+	for key, v := range enc.Fields {
+		c.apply(&entry, key, v)
+	}
+	return c.logger.Log(entry)
+}
+
+// Sync flushes the logger if it buffers entries, as nfo.AsyncClient does.
+func (c *Core) Sync() error {
+	if f, ok := c.logger.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// apply stores the encoded field key=v on entry.
+func (c *Core) apply(entry *nfo.LogEntry, key string, v any) {
+	switch c.opts.FieldMap[key] {
+	case "cmd":
+		entry.Cmd = fmt.Sprint(v)
+	case "args":
+		switch args := v.(type) {
+		case []string:
+			entry.Args = append(entry.Args, args...)
+		case []any:
+			for _, a := range args {
+				entry.Args = append(entry.Args, fmt.Sprint(a))
+			}
+		default:
+			entry.Args = append(entry.Args, fmt.Sprint(v))
+		}
+	case "language":
+		entry.Language = fmt.Sprint(v)
+	case "env":
+		entry.Env = fmt.Sprint(v)
+	case "success":
+		if ok, isBool := v.(bool); isBool {
+			entry.Success = &ok
+		}
+	case "duration_ms":
+		if ms, ok := durationMs(v); ok {
+			entry.DurationMs = &ms
+		}
+	case "output":
+		entry.Output = fmt.Sprint(v)
+	case "error":
+		entry.Error = fmt.Sprint(v)
+	default:
+		if entry.Fields == nil {
+			entry.Fields = make(map[string]any)
+		}
+		entry.Fields[key] = fieldValue(v)
+	}
+}
+
+// fieldValue converts v into something encoding/json renders sensibly.
+func fieldValue(v any) any {
+	switch v := v.(type) {
+	case time.Duration:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case error:
+		return v.Error()
+	}
+	return v
+}
+
+// level maps a zap level to the nfo level covering it.
+func level(l zapcore.Level) nfo.Level {
+	switch {
+	case l < zapcore.InfoLevel:
+		return nfo.LevelDebug
+	case l < zapcore.WarnLevel:
+		return nfo.LevelInfo
+	case l < zapcore.ErrorLevel:
+		return nfo.LevelWarn
+	}
+	return nfo.LevelError
+}
+
+func durationMs(v any) (float64, bool) {
+	switch v := v.(type) {
+	case time.Duration:
+		return float64(v) / float64(time.Millisecond), true
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,99 @@
+package nfozap
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/wronai/lg/examples/go-client/nfo"
+)
+
+type captureLogger struct {
+	entries []nfo.LogEntry
+	flushes int
+}
+
+func (c *captureLogger) Log(entry nfo.LogEntry) error {
+	c.entries = append(c.entries, entry)
+	return nil
+}
+
+func (c *captureLogger) Flush() error {
+	c.flushes++
+	return nil
+}
+
+func TestCoreMapsFields(t *testing.T) {
+	capture := &captureLogger{}
+	logger := zap.New(NewCore(capture, &Options{Env: "ci"})).Named("deployer")
+
+	logger.With(zap.String("region", "eu")).Error("deploy failed",
+		zap.String("cmd", "deploy"),
+		zap.Strings("args", []string{"prod"}),
+		zap.Duration("duration", 1500*time.Millisecond),
+		zap.Error(errors.New("timeout")),
+		zap.Int("attempt", 2),
+	)
+
+	if len(capture.entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(capture.entries))
+	}
+	e := capture.entries[0]
+	if e.Cmd != "deploy" || e.Env != "ci" || e.Language != "go" || e.Level != nfo.LevelError {
+		t.Errorf("unexpected identity fields: %+v", e)
+	}
+	if e.Success == nil || *e.Success {
+		t.Errorf("error entry should have success=false, got %v", e.Success)
+	}
+	if e.Timestamp == nil || time.Since(*e.Timestamp) > time.Minute || e.Timestamp.Location() != time.UTC {
+		t.Errorf("expected the entry time in UTC, got %v", e.Timestamp)
+	}
+	if e.DurationMs == nil || *e.DurationMs != 1500 || e.Error != "timeout" {
+		t.Errorf("unexpected outcome: duration=%v error=%q", e.DurationMs, e.Error)
+	}
+	if !slices.Equal(e.Args, []string{"prod"}) {
+		t.Errorf("unexpected args: %v", e.Args)
+	}
+	if e.Fields["region"] != "eu" || e.Fields["attempt"] != int64(2) || e.Fields["logger"] != "deployer" {
+		t.Errorf("unexpected fields: %v", e.Fields)
+	}
+}
+
+func TestCoreLevels(t *testing.T) {
+	capture := &captureLogger{}
+	logger := zap.New(NewCore(capture, nil))
+
+	logger.Debug("ignored")
+	logger.Info("i")
+	logger.Warn("w")
+	logger.DPanic("dp")
+
+	want := map[string]nfo.Level{"i": nfo.LevelInfo, "w": nfo.LevelWarn, "dp": nfo.LevelError}
+	if len(capture.entries) != len(want) {
+		t.Fatalf("unexpected entries: %+v", capture.entries)
+	}
+	for _, e := range capture.entries {
+		if e.Level != want[e.Cmd] {
+			t.Errorf("%s: level = %v, want %v", e.Cmd, e.Level, want[e.Cmd])
+		}
+	}
+
+	debug := zap.New(NewCore(capture, &Options{Level: zapcore.DebugLevel}))
+	debug.Debug("d")
+	if e := capture.entries[len(capture.entries)-1]; e.Cmd != "d" || e.Level != nfo.LevelDebug {
+		t.Errorf("debug entry not shipped: %+v", e)
+	}
+}
+
+func TestCoreSyncFlushes(t *testing.T) {
+	capture := &captureLogger{}
+	logger := zap.New(zapcore.NewTee(zapcore.NewNopCore(), NewCore(capture, nil)))
+	logger.Info("x")
+	if err := logger.Sync(); err != nil || capture.flushes != 1 {
+		t.Fatalf("Sync: %v, flushes=%d", err, capture.flushes)
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/wronai/lg/examples/go-client/nfozap
+
+go 1.23
+
+require (
+	github.com/wronai/lg/examples/go-client v0.0.0
+	go.uber.org/zap v1.27.0
+)
+
+require go.uber.org/multierr v1.10.0 // indirect
+
+replace github.com/wronai/lg/examples/go-client => ../
@@ -24,7 +24,7 @@ _sym_db = _symbol_database.Default()
 
 
 
-DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\tnfo.proto\x12\x03nfo\"\xe4\x01\n\nLogRequest\x12\x0b\n\x03\x63md\x18\x01 \x01(\t\x12\x0c\n\x04\x61rgs\x18\x02 \x03(\t\x12\x10\n\x08language\x18\x03 \x01(\t\x12\x0b\n\x03\x65nv\x18\x04 \x01(\t\x12\x0f\n\x07success\x18\x05 \x01(\x08\x12\x13\n\x0b\x64uration_ms\x18\x06 \x01(\x01\x12\x0e\n\x06output\x18\x07 \x01(\t\x12\r\n\x05\x65rror\x18\x08 \x01(\t\x12)\n\x05\x65xtra\x18\t \x03(\x0b\x32\x1a.nfo.LogRequest.ExtraEntry\x1a,\n\nExtraEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\"<\n\x0bLogResponse\x12\x0e\n\x06stored\x18\x01 \x01(\x08\x12\n\n\x02id\x18\x02 \x01(\t\x12\x11\n\ttimestamp\x18\x03 \x01(\t\"3\n\x0f\x42\x61tchLogRequest\x12 \n\x07\x65ntries\x18\x01 \x03(\x0b\x32\x0f.nfo.LogRequest\"E\n\x10\x42\x61tchLogResponse\x12\x0e\n\x06stored\x18\x01 \x01(\x05\x12!\n\x07results\x18\x02 \x03(\x0b\x32\x10.nfo.LogResponse\"Z\n\x0cQueryRequest\x12\x10\n\x08language\x18\x01 \x01(\t\x12\r\n\x05level\x18\x02 \x01(\t\x12\x0b\n\x03\x65nv\x18\x03 \x01(\t\x12\r\n\x05limit\x18\x04 \x01(\x05\x12\r\n\x05since\x18\x05 \x01(\t\">\n\rQueryResponse\x12\x1e\n\x07\x65ntries\x18\x01 \x03(\x0b\x32\r.nfo.LogEntry\x12\r\n\x05total\x18\x02 \x01(\x05\"\x8e\x02\n\x08LogEntry\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\ttimestamp\x18\x02 \x01(\t\x12\r\n\x05level\x18\x03 \x01(\t\x12\x0b\n\x03\x63md\x18\x04 \x01(\t\x12\x0c\n\x04\x61rgs\x18\x05 \x03(\t\x12\x10\n\x08language\x18\x06 \x01(\t\x12\x0b\n\x03\x65nv\x18\x07 \x01(\t\x12\x0f\n\x07success\x18\x08 \x01(\x08\x12\x13\n\x0b\x64uration_ms\x18\t \x01(\x01\x12\x0e\n\x06output\x18\n \x01(\t\x12\r\n\x05\x65rror\x18\x0b \x01(\t\x12\'\n\x05\x65xtra\x18\x0c \x03(\x0b\x32\x18.nfo.LogEntry.ExtraEntry\x1a,\n\nExtraEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\x32\xda\x01\n\tNfoLogger\x12,\n\x07LogCall\x12\x0f.nfo.LogRequest\x1a\x10.nfo.LogResponse\x12\x37\n\x08\x42\x61tchLog\x12\x14.nfo.BatchLogRequest\x1a\x15.nfo.BatchLogResponse\x12\x32\n\tStreamLog\x12\x0f.nfo.LogRequest\x1a\x10.nfo.LogResponse(\x01\x30\x01\x12\x32\n\tQueryLogs\x12\x11.nfo.QueryRequest\x1a\x12.nfo.QueryResponseB\x1dZ\x1bgithub.com/wronai/nfo/protob\x06proto3')
+DESCRIPTOR = _descriptor_pool.Default().AddSerializedFile(b'\n\tnfo.proto\x12\x03nfo\"\xb3\x04\n\nLogRequest\x12\x0b\n\x03\x63md\x18\x01 \x01(\t\x12\x0c\n\x04\x61rgs\x18\x02 \x03(\t\x12\x10\n\x08language\x18\x03 \x01(\t\x12\x0b\n\x03\x65nv\x18\x04 \x01(\t\x12\x14\n\x07success\x18\x05 \x01(\x08H\x00\x88\x01\x01\x12\x18\n\x0b\x64uration_ms\x18\x06 \x01(\x01H\x01\x88\x01\x01\x12\x0e\n\x06output\x18\x07 \x01(\t\x12\r\n\x05\x65rror\x18\x08 \x01(\t\x12)\n\x05\x65xtra\x18\t \x03(\x0b\x32\x1a.nfo.LogRequest.ExtraEntry\x12\x10\n\x08trace_id\x18\n \x01(\t\x12\x0f\n\x07span_id\x18\x0b \x01(\t\x12\x1b\n\x04meta\x18\x0c \x01(\x0b\x32\r.nfo.Metadata\x12\r\n\x05level\x18\r \x01(\t\x12\x11\n\ttimestamp\x18\x0e \x01(\t\x12\x16\n\x0e\x63orrelation_id\x18\x0f \x01(\t\x12\x17\n\x0ftruncated_bytes\x18\x10 \x01(\x03\x12\x35\n\x0b\x61ttachments\x18\x11 \x03(\x0b\x32 .nfo.LogRequest.AttachmentsEntry\x12\x13\n\x0b\x66ingerprint\x18\x12 \x01(\t\x12\x14\n\x0crepeat_count\x18\x13 \x01(\x03\x1a,\n\nExtraEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\x1a\x32\n\x10\x41ttachmentsEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\x42\n\n\x08_successB\x0e\n\x0c_duration_ms\"\xb9\x01\n\x08Metadata\x12\x10\n\x08hostname\x18\x01 \x01(\t\x12\x0b\n\x03pid\x18\x02 \x01(\x03\x12\x12\n\ngo_version\x18\x03 \x01(\t\x12\x0e\n\x06\x62inary\x18\x04 \x01(\t\x12\n\n\x02os\x18\x05 \x01(\t\x12\x0c\n\x04\x61rch\x18\x06 \x01(\t\x12\x14\n\x0c\x63ontainer_id\x18\x07 \x01(\t\x12\x10\n\x08pod_name\x18\x08 \x01(\t\x12\x15\n\rpod_namespace\x18\t \x01(\t\x12\x11\n\tnode_name\x18\n \x01(\t\"<\n\x0bLogResponse\x12\x0e\n\x06stored\x18\x01 \x01(\x08\x12\n\n\x02id\x18\x02 \x01(\t\x12\x11\n\ttimestamp\x18\x03 \x01(\t\"3\n\x0f\x42\x61tchLogRequest\x12 \n\x07\x65ntries\x18\x01 \x03(\x0b\x32\x0f.nfo.LogRequest\"E\n\x10\x42\x61tchLogResponse\x12\x0e\n\x06stored\x18\x01 \x01(\x05\x12!\n\x07results\x18\x02 \x03(\x0b\x32\x10.nfo.LogResponse\"Z\n\x0cQueryRequest\x12\x10\n\x08language\x18\x01 \x01(\t\x12\r\n\x05level\x18\x02 \x01(\t\x12\x0b\n\x03\x65nv\x18\x03 \x01(\t\x12\r\n\x05limit\x18\x04 \x01(\x05\x12\r\n\x05since\x18\x05 \x01(\t\">\n\rQueryResponse\x12\x1e\n\x07\x65ntries\x18\x01 \x03(\x0b\x32\r.nfo.LogEntry\x12\r\n\x05total\x18\x02 \x01(\x05\"\x8e\x02\n\x08LogEntry\x12\n\n\x02id\x18\x01 \x01(\t\x12\x11\n\ttimestamp\x18\x02 \x01(\t\x12\r\n\x05level\x18\x03 \x01(\t\x12\x0b\n\x03\x63md\x18\x04 \x01(\t\x12\x0c\n\x04\x61rgs\x18\x05 \x03(\t\x12\x10\n\x08language\x18\x06 \x01(\t\x12\x0b\n\x03\x65nv\x18\x07 \x01(\t\x12\x0f\n\x07success\x18\x08 \x01(\x08\x12\x13\n\x0b\x64uration_ms\x18\t \x01(\x01\x12\x0e\n\x06output\x18\n \x01(\t\x12\r\n\x05\x65rror\x18\x0b \x01(\t\x12\'\n\x05\x65xtra\x18\x0c \x03(\x0b\x32\x18.nfo.LogEntry.ExtraEntry\x1a,\n\nExtraEntry\x12\x0b\n\x03key\x18\x01 \x01(\t\x12\r\n\x05value\x18\x02 \x01(\t:\x02\x38\x01\x32\xda\x01\n\tNfoLogger\x12,\n\x07LogCall\x12\x0f.nfo.LogRequest\x1a\x10.nfo.LogResponse\x12\x37\n\x08\x42\x61tchLog\x12\x14.nfo.BatchLogRequest\x1a\x15.nfo.BatchLogResponse\x12\x32\n\tStreamLog\x12\x0f.nfo.LogRequest\x1a\x10.nfo.LogResponse(\x01\x30\x01\x12\x32\n\tQueryLogs\x12\x11.nfo.QueryRequest\x1a\x12.nfo.QueryResponseB\x1dZ\x1bgithub.com/wronai/nfo/protob\x06proto3')
 
 _globals = globals()
 _builder.BuildMessageAndEnumDescriptors(DESCRIPTOR, _globals)
@@ -34,26 +34,32 @@ if not _descriptor._USE_C_DESCRIPTORS:
   _globals['DESCRIPTOR']._serialized_options = b'Z\033github.com/wronai/nfo/proto'
   _globals['_LOGREQUEST_EXTRAENTRY']._loaded_options = None
   _globals['_LOGREQUEST_EXTRAENTRY']._serialized_options = b'8\001'
+  _globals['_LOGREQUEST_ATTACHMENTSENTRY']._loaded_options = None
+  _globals['_LOGREQUEST_ATTACHMENTSENTRY']._serialized_options = b'8\001'
   _globals['_LOGENTRY_EXTRAENTRY']._loaded_options = None
   _globals['_LOGENTRY_EXTRAENTRY']._serialized_options = b'8\001'
   _globals['_LOGREQUEST']._serialized_start=19
-  _globals['_LOGREQUEST']._serialized_end=247
-  _globals['_LOGREQUEST_EXTRAENTRY']._serialized_start=203
-  _globals['_LOGREQUEST_EXTRAENTRY']._serialized_end=247
-  _globals['_LOGRESPONSE']._serialized_start=249
-  _globals['_LOGRESPONSE']._serialized_end=309
-  _globals['_BATCHLOGREQUEST']._serialized_start=311
-  _globals['_BATCHLOGREQUEST']._serialized_end=362
-  _globals['_BATCHLOGRESPONSE']._serialized_start=364
-  _globals['_BATCHLOGRESPONSE']._serialized_end=433
-  _globals['_QUERYREQUEST']._serialized_start=435
-  _globals['_QUERYREQUEST']._serialized_end=525
-  _globals['_QUERYRESPONSE']._serialized_start=527
-  _globals['_QUERYRESPONSE']._serialized_end=589
-  _globals['_LOGENTRY']._serialized_start=592
-  _globals['_LOGENTRY']._serialized_end=862
-  _globals['_LOGENTRY_EXTRAENTRY']._serialized_start=203
-  _globals['_LOGENTRY_EXTRAENTRY']._serialized_end=247
-  _globals['_NFOLOGGER']._serialized_start=865
-  _globals['_NFOLOGGER']._serialized_end=1083
+  _globals['_LOGREQUEST']._serialized_end=582
+  _globals['_LOGREQUEST_EXTRAENTRY']._serialized_start=458
+  _globals['_LOGREQUEST_EXTRAENTRY']._serialized_end=502
+  _globals['_LOGREQUEST_ATTACHMENTSENTRY']._serialized_start=504
+  _globals['_LOGREQUEST_ATTACHMENTSENTRY']._serialized_end=554
+  _globals['_METADATA']._serialized_start=585
+  _globals['_METADATA']._serialized_end=770
+  _globals['_LOGRESPONSE']._serialized_start=772
+  _globals['_LOGRESPONSE']._serialized_end=832
+  _globals['_BATCHLOGREQUEST']._serialized_start=834
+  _globals['_BATCHLOGREQUEST']._serialized_end=885
+  _globals['_BATCHLOGRESPONSE']._serialized_start=887
+  _globals['_BATCHLOGRESPONSE']._serialized_end=956
+  _globals['_QUERYREQUEST']._serialized_start=958
+  _globals['_QUERYREQUEST']._serialized_end=1048
+  _globals['_QUERYRESPONSE']._serialized_start=1050
+  _globals['_QUERYRESPONSE']._serialized_end=1112
+  _globals['_LOGENTRY']._serialized_start=1115
+  _globals['_LOGENTRY']._serialized_end=1385
+  _globals['_LOGENTRY_EXTRAENTRY']._serialized_start=458
+  _globals['_LOGENTRY_EXTRAENTRY']._serialized_end=502
+  _globals['_NFOLOGGER']._serialized_start=1388
+  _globals['_NFOLOGGER']._serialized_end=1606
 # @@protoc_insertion_point(module_scope)
@@ -23,10 +23,12 @@ Test with client:
 from __future__ import annotations
 
 import os
+import re
 import sqlite3
 import sys
 import time
 from concurrent import futures
+from datetime import datetime
 from pathlib import Path
 
 try:
@@ -85,14 +87,26 @@ logger = Logger(
 _entry_counter = 0
 
 
+def _timestamp(value: str) -> datetime:
+    """Parse the client's RFC 3339 timestamp, falling back to the receive time."""
+    if value:
+        try:
+            # fromisoformat handles at most microseconds and, before 3.11, no "Z".
+            value = re.sub(r"(\.\d{6})\d+", r"\1", value.replace("Z", "+00:00"))
+            return datetime.fromisoformat(value)
+        except ValueError:
+            pass
+    return NfoEntry.now()
+
+
 def _store_request(req: nfo_pb2.LogRequest) -> nfo_pb2.LogResponse:
     """Convert a gRPC LogRequest to nfo LogEntry, emit, return response."""
     global _entry_counter
     _entry_counter += 1
 
     entry = NfoEntry(
-        timestamp=NfoEntry.now(),
-        level="INFO" if not req.error else "ERROR",
+        timestamp=_timestamp(req.timestamp),
+        level=req.level.upper() or ("INFO" if not req.error else "ERROR"),
         function_name=req.cmd,
         module=req.language or "unknown",
         args=tuple(req.args),
@@ -100,6 +114,12 @@ def _store_request(req: nfo_pb2.LogRequest) -> nfo_pb2.LogResponse:
             "language": req.language,
             "env": req.env,
             **(dict(req.extra) if req.extra else {}),
+            **({"trace_id": req.trace_id, "span_id": req.span_id} if req.trace_id else {}),
+            **({"correlation_id": req.correlation_id} if req.correlation_id else {}),
+            **({"truncated_bytes": req.truncated_bytes} if req.truncated_bytes else {}),
+            **({"attachments": dict(req.attachments)} if req.attachments else {}),
+            **({"fingerprint": req.fingerprint} if req.fingerprint else {}),
+            **({"repeat_count": req.repeat_count} if req.repeat_count else {}),
         },
         arg_types=[type(a).__name__ for a in req.args],
         kwarg_types={"language": "str", "env": "str"},
@@ -19,15 +19,35 @@ Send logs from any language:
 
     curl http://localhost:8080/logs
     curl http://localhost:8080/logs?language=bash&success=false
+    curl http://localhost:8080/stats?group_by=cmd&env=prod
+
+Store the full text of truncated output (the entry references the returned id):
+    curl -X POST http://localhost:8080/attachments -d '{"name":"output","content":"..."}'
+
+Follow new entries (Server-Sent Events):
+    curl -N http://localhost:8080/logs/stream?min_level=warn
+
+Measure client clock skew against the service clock:
+    curl http://localhost:8080/time
+
+Discover what this service supports (fields, codecs, batch, streaming):
+    curl http://localhost:8080/capabilities
 """
 
 from __future__ import annotations
 
+import ast
+import asyncio
+import gzip
+import json
 import os
 import sqlite3
 import time
+import uuid
+from collections import deque
+from datetime import datetime, timezone
 from pathlib import Path
-from typing import List, Optional
+from typing import Dict, List, Optional
 
 # Load .env if python-dotenv is available (optional)
 try:
@@ -47,15 +67,20 @@ from nfo import Logger, SQLiteSink, CSVSink, JSONSink
 # Try to import FastAPI; provide helpful error if missing
 # ---------------------------------------------------------------------------
 try:
-    from fastapi import FastAPI, Query
-    from fastapi.responses import JSONResponse
-    from pydantic import BaseModel
+    from fastapi import FastAPI, HTTPException, Query, Request
+    from fastapi.responses import JSONResponse, PlainTextResponse, StreamingResponse
+    from pydantic import BaseModel, ValidationError
 except ImportError:
     raise SystemExit(
         "This example requires FastAPI and uvicorn:\n"
         "  pip install fastapi uvicorn\n"
     )
 
+try:
+    import msgpack  # optional: accept application/msgpack bodies
+except ImportError:
+    msgpack = None
+
 # ---------------------------------------------------------------------------
 # Configuration
 # ---------------------------------------------------------------------------
@@ -66,6 +91,7 @@ Path(LOG_DIR).mkdir(parents=True, exist_ok=True)
 DB_PATH = f"{LOG_DIR}/nfo_central.db"
 CSV_PATH = f"{LOG_DIR}/nfo_central.csv"
 JSONL_PATH = f"{LOG_DIR}/nfo_central.jsonl"
+ATTACHMENT_DIR = Path(LOG_DIR) / "attachments"
 
 NFO_HOST = os.environ.get("NFO_HOST", "0.0.0.0")
 NFO_PORT = int(os.environ.get("NFO_PORT", "8080"))
@@ -98,12 +124,25 @@ class LogEntry(BaseModel):
     duration_ms: Optional[float] = None
     output: Optional[str] = None
     error: Optional[str] = None
+    level: Optional[str] = None  # "debug", "info", "warn", "error"
+    timestamp: Optional[datetime] = None  # client-side log time; default: on receipt
+    correlation_id: Optional[str] = None  # groups the entries of one job or request
+    truncated_bytes: Optional[int] = None  # bytes the client cut from oversized values
+    attachments: Optional[Dict[str, str]] = None  # field name -> id of its full text
+    fingerprint: Optional[str] = None  # identifies repeats of the same failure
+    repeat_count: Optional[int] = None  # duplicates a client-side dedup rollup stands for
+    clock_skew_ms: Optional[float] = None  # how far the client clock is ahead of GET /time
 
 
 class LogBatchRequest(BaseModel):
     entries: List[LogEntry]
 
 
+class Attachment(BaseModel):
+    name: str = "output"
+    content: str
+
+
 # ---------------------------------------------------------------------------
 # FastAPI app
 # ---------------------------------------------------------------------------
@@ -115,19 +154,77 @@ app = FastAPI(
 )
 
 
+class GzipRequestMiddleware:
+    """Decompress request bodies sent with Content-Encoding: gzip.
+
+    Clients such as the Go client's WithCompression gzip large batches; the
+    routes below then see the plain body, whatever its Content-Type.
+    """
+
+    def __init__(self, app):
+        self.app = app
+
+    async def __call__(self, scope, receive, send):
+        if scope["type"] != "http":
+            return await self.app(scope, receive, send)
+        headers = dict(scope["headers"])
+        if headers.get(b"content-encoding", b"").strip().lower() != b"gzip":
+            return await self.app(scope, receive, send)
+
+        chunks = []
+        while True:
+            message = await receive()
+            if message["type"] != "http.request":
+                return
+            chunks.append(message.get("body", b""))
+            if not message.get("more_body"):
+                break
+        try:
+            body = gzip.decompress(b"".join(chunks))
+        except (OSError, EOFError) as exc:
+            response = JSONResponse({"detail": f"invalid gzip body: {exc}"}, status_code=400)
+            return await response(scope, receive, send)
+
+        headers.pop(b"content-encoding")
+        headers[b"content-length"] = str(len(body)).encode()
+        scope = dict(scope, headers=list(headers.items()))
+        sent = False
+
+        async def receive_body():
+            nonlocal sent
+            if sent:
+                return await receive()
+            sent = True
+            return {"type": "http.request", "body": body, "more_body": False}
+
+        await self.app(scope, receive_body, send)
+
+
+app.add_middleware(GzipRequestMiddleware)
+
+
 def _store_entry(entry: LogEntry) -> dict:
     """Write a single log entry through nfo and return result."""
     from nfo.models import LogEntry as NfoEntry
 
+    level = (entry.level or ("INFO" if entry.success is not False else "ERROR")).upper()
+    timestamp = entry.timestamp or NfoEntry.now()
     nfo_entry = NfoEntry(
-        timestamp=NfoEntry.now(),
-        level="INFO" if entry.success is not False else "ERROR",
+        timestamp=timestamp,
+        level=level,
         function_name=entry.cmd,
         module=entry.language,
         args=tuple(entry.args),
         kwargs={
             "language": entry.language,
             "env": entry.env,
+            **({"success": entry.success} if entry.success is not None else {}),
+            **({"correlation_id": entry.correlation_id} if entry.correlation_id else {}),
+            **({"truncated_bytes": entry.truncated_bytes} if entry.truncated_bytes else {}),
+            **({"attachments": entry.attachments} if entry.attachments else {}),
+            **({"fingerprint": entry.fingerprint} if entry.fingerprint else {}),
+            **({"repeat_count": entry.repeat_count} if entry.repeat_count else {}),
+            **({"clock_skew_ms": entry.clock_skew_ms} if entry.clock_skew_ms is not None else {}),
         },
         arg_types=[type(a).__name__ for a in entry.args],
         kwarg_types={"language": "str", "env": "str"},
@@ -139,6 +236,7 @@ def _store_entry(entry: LogEntry) -> dict:
         environment=entry.env,
     )
     logger.emit(nfo_entry)
+    _publish(entry, level, timestamp)
 
     return {
         "cmd": entry.cmd,
@@ -147,11 +245,58 @@ def _store_entry(entry: LogEntry) -> dict:
     }
 
 
+# ---------------------------------------------------------------------------
+# Live stream: recent entries are buffered so reconnecting clients can
+# resume from the Last-Event-ID header.
+# ---------------------------------------------------------------------------
+
+_LEVELS = {"DEBUG": 1, "INFO": 2, "WARN": 3, "WARNING": 3, "ERROR": 4}
+_stream_backlog: deque = deque(maxlen=1000)
+_stream_subscribers: set = set()
+_stream_seq = 0
+
+
+def _publish(entry: LogEntry, level: str, timestamp: datetime) -> None:
+    """Hand a stored entry to every /logs/stream subscriber."""
+    global _stream_seq
+    _stream_seq += 1
+    data = {**entry.dict(), "level": level.lower(), "timestamp": timestamp.isoformat()}
+    event = (_stream_seq, data)
+    _stream_backlog.append(event)
+    for queue in list(_stream_subscribers):
+        try:
+            queue.put_nowait(event)
+        except asyncio.QueueFull:
+            pass  # slow subscriber; it can resume from its cursor
+
+
+async def _read_entries(request: Request) -> List[LogEntry]:
+    """Decode a request body in the format named by its Content-Type."""
+    content_type = request.headers.get("content-type", "application/json").split(";")[0].strip()
+    body = await request.body()
+    try:
+        if content_type in ("application/x-ndjson", "application/jsonl"):
+            items = [json.loads(line) for line in body.splitlines() if line.strip()]
+        elif content_type in ("application/msgpack", "application/x-msgpack") and msgpack:
+            items = msgpack.unpackb(body, raw=False)
+        elif content_type == "application/json":
+            items = json.loads(body)
+        else:
+            raise HTTPException(status_code=415, detail=f"unsupported content type {content_type!r}")
+        if isinstance(items, dict):
+            items = [items]
+        return [LogEntry(**item) for item in items]
+    except (ValueError, TypeError, ValidationError) as exc:
+        raise HTTPException(status_code=422, detail=str(exc))
+
+
 @app.post("/log")
-async def log_call(entry: LogEntry):
-    """Log a single call from any language."""
-    result = _store_entry(entry)
-    return result
+async def log_call(request: Request):
+    """Log a single call from any language (JSON, NDJSON or MessagePack)."""
+    entries = await _read_entries(request)
+    if len(entries) != 1:
+        raise HTTPException(status_code=422, detail="expected one entry")
+    return _store_entry(entries[0])
 
 
 @app.post("/log/batch")
@@ -161,35 +306,317 @@ async def log_batch(batch: LogBatchRequest):
     return {"stored": len(results), "results": results}
 
 
+@app.post("/logs/batch")
+async def logs_batch(request: Request):
+    """Log a JSON array, NDJSON lines or a MessagePack array of entries (used by the Go client)."""
+    results = [_store_entry(e) for e in await _read_entries(request)]
+    return {"stored": len(results), "results": results}
+
+
+@app.post("/attachments")
+async def create_attachment(attachment: Attachment):
+    """Store the full text of a value the client truncated."""
+    ATTACHMENT_DIR.mkdir(parents=True, exist_ok=True)
+    attachment_id = uuid.uuid4().hex
+    (ATTACHMENT_DIR / attachment_id).write_text(attachment.content, encoding="utf-8")
+    return {"id": attachment_id, "name": attachment.name, "size": len(attachment.content)}
+
+
+@app.get("/attachments/{attachment_id}", response_class=PlainTextResponse)
+async def get_attachment(attachment_id: str):
+    """Return a stored attachment."""
+    path = ATTACHMENT_DIR / attachment_id
+    if not attachment_id.isalnum() or not path.is_file():
+        raise HTTPException(status_code=404, detail="attachment not found")
+    return path.read_text(encoding="utf-8")
+
+
+# Fields kept in the kwargs column and returned as they were sent.
+_KWARG_FIELDS = (
+    "correlation_id",
+    "truncated_bytes",
+    "attachments",
+    "fingerprint",
+    "repeat_count",
+    "clock_skew_ms",
+)
+
+
+def _literal(text: Optional[str], default=None):
+    """Read back a value the SQLite sink stored as its repr."""
+    if not text:
+        return default
+    try:
+        return ast.literal_eval(text)
+    except (ValueError, SyntaxError):
+        return default
+
+
+def _as_utc(ts: datetime) -> datetime:
+    return ts.replace(tzinfo=timezone.utc) if ts.tzinfo is None else ts
+
+
+def _row_entry(row: sqlite3.Row) -> dict:
+    """Turn a stored row back into the LogEntry JSON clients send."""
+    kwargs = _literal(row["kwargs"], {})
+    if not isinstance(kwargs, dict):
+        kwargs = {}
+    args = _literal(row["args"], ())
+    failed = row["level"] == "ERROR" or bool(row["exception"])
+    entry = {
+        "cmd": row["function_name"],
+        "args": [str(a) for a in args] if isinstance(args, (list, tuple)) else [],
+        "language": kwargs.get("language") or row["module"],
+        "env": row["environment"] or kwargs.get("env", ""),
+        "success": kwargs.get("success", not failed),
+        "level": (row["level"] or "info").lower(),
+        "timestamp": _as_utc(datetime.fromisoformat(row["timestamp"])).isoformat(),
+    }
+    if row["duration_ms"]:
+        entry["duration_ms"] = row["duration_ms"]
+    output = _literal(row["return_value"])
+    if output is not None:
+        entry["output"] = str(output)
+    if row["exception"]:
+        entry["error"] = row["exception"]
+    if row["trace_id"]:
+        entry["trace_id"] = row["trace_id"]
+    for key in _KWARG_FIELDS:
+        if kwargs.get(key) is not None:
+            entry[key] = kwargs[key]
+    return entry
+
+
 @app.get("/logs")
 async def get_logs(
+    cmd: Optional[str] = Query(None),
+    env: Optional[str] = Query(None),
     language: Optional[str] = Query(None),
+    success: Optional[bool] = Query(None),
     level: Optional[str] = Query(None),
+    correlation_id: Optional[str] = Query(None),
+    since: Optional[datetime] = Query(None),
+    until: Optional[datetime] = Query(None),
     limit: int = Query(50, ge=1, le=1000),
+    offset: int = Query(0, ge=0),
 ):
-    """Query stored logs from SQLite."""
+    """Query stored logs, newest first, as LogEntry objects.
+
+    Pages are stable for a fixed store: rows are ordered by timestamp and
+    then by insertion, so offset walks through them without repeats.
+    """
     conn = sqlite3.connect(DB_PATH)
     conn.row_factory = sqlite3.Row
 
     query = "SELECT * FROM logs WHERE 1=1"
     params: list = []
 
+    if cmd:
+        query += " AND function_name = ?"
+        params.append(cmd)
+    if env:
+        query += " AND environment = ?"
+        params.append(env)
+    if language:
+        query += " AND module = ?"
+        params.append(language)
     if level:
         query += " AND level = ?"
         params.append(level.upper())
+    if correlation_id:
+        query += " AND kwargs LIKE ?"
+        params.append(f"%'correlation_id': {correlation_id!r}%")  # kwargs is stored as a repr
+
+    query += " ORDER BY timestamp DESC, id DESC"
+    # success and the time range are checked on the decoded entries, since
+    # neither is stored in a column SQLite can compare.
+    filtered = success is not None or since or until
+    if not filtered:
+        query += " LIMIT ? OFFSET ?"
+        params += [limit, offset]
 
-    query += " ORDER BY timestamp DESC LIMIT ?"
-    params.append(limit)
+    rows = conn.execute(query, params).fetchall()
+    conn.close()
 
+    entries = []
+    for row in rows:
+        entry = _row_entry(row)
+        if success is not None and entry["success"] != success:
+            continue
+        ts = datetime.fromisoformat(entry["timestamp"])
+        if (since and ts < _as_utc(since)) or (until and ts > _as_utc(until)):
+            continue
+        entries.append(entry)
+    return entries[offset : offset + limit] if filtered else entries
+
+
+_STATS_COLUMNS = {
+    "cmd": "function_name",
+    "env": "environment",
+    "language": "module",
+    "level": "level",
+}
+
+
+def _percentile(sorted_values: List[float], p: int) -> float:
+    """Nearest-rank percentile of an ascending list; 0 when empty."""
+    if not sorted_values:
+        return 0.0
+    rank = -(-p * len(sorted_values) // 100)
+    return sorted_values[max(rank - 1, 0)]
+
+
+@app.get("/stats")
+async def get_stats(
+    group_by: str = Query("cmd"),
+    cmd: Optional[str] = Query(None),
+    env: Optional[str] = Query(None),
+    since: Optional[datetime] = Query(None),
+    until: Optional[datetime] = Query(None),
+):
+    """Counts, success rate and duration percentiles per group.
+
+    An entry counts as failed if it is stored at ERROR level or with an
+    exception.
+    """
+    column = _STATS_COLUMNS.get(group_by)
+    if column is None:
+        raise HTTPException(
+            status_code=422, detail=f"group_by must be one of {sorted(_STATS_COLUMNS)}"
+        )
+    conn = sqlite3.connect(DB_PATH)
+    query = (
+        f"SELECT {column}, timestamp, duration_ms, level = 'ERROR' OR exception IS NOT NULL"
+        " FROM logs WHERE 1=1"
+    )
+    params: list = []
+    if cmd:
+        query += " AND function_name = ?"
+        params.append(cmd)
+    if env:
+        query += " AND environment = ?"
+        params.append(env)
     rows = conn.execute(query, params).fetchall()
     conn.close()
 
-    return [dict(row) for row in rows]
+    groups: Dict[str, dict] = {}
+    for key, timestamp, duration_ms, failed in rows:
+        if since or until:
+            ts = datetime.fromisoformat(timestamp)
+            if ts.tzinfo is None and (since or until).tzinfo is not None:
+                ts = ts.replace(tzinfo=(since or until).tzinfo)
+            if (since and ts < since) or (until and ts > until):
+                continue
+        if group_by == "level":
+            key = (key or "").lower()
+        group = groups.setdefault(key, {"count": 0, "failures": 0, "durations": []})
+        group["count"] += 1
+        group["failures"] += int(bool(failed))
+        if duration_ms:
+            group["durations"].append(duration_ms)
+
+    result = []
+    for key, group in groups.items():
+        durations = sorted(group["durations"])
+        result.append({
+            "key": key,
+            "count": group["count"],
+            "failures": group["failures"],
+            "success_rate": (group["count"] - group["failures"]) / group["count"],
+            "duration_p50_ms": _percentile(durations, 50),
+            "duration_p90_ms": _percentile(durations, 90),
+            "duration_p99_ms": _percentile(durations, 99),
+        })
+    result.sort(key=lambda g: (-g["count"], g["key"]))
+    return result
+
+
+@app.get("/logs/stream")
+async def stream_logs(
+    request: Request,
+    cmd: Optional[str] = Query(None),
+    env: Optional[str] = Query(None),
+    success: Optional[bool] = Query(None),
+    min_level: Optional[str] = Query(None),
+):
+    """Follow new entries as Server-Sent Events (used by the Go TailLogs)."""
+    try:
+        last_id = int(request.headers.get("last-event-id") or 0)
+    except ValueError:
+        last_id = 0
+    floor = _LEVELS.get((min_level or "").upper(), 0)
+
+    def wanted(data: dict) -> bool:
+        if cmd and data["cmd"] != cmd:
+            return False
+        if env and data["env"] != env:
+            return False
+        if success is not None and (data["success"] is not False) != success:
+            return False
+        return _LEVELS.get(data["level"].upper(), 0) >= floor
+
+    queue: asyncio.Queue = asyncio.Queue(maxsize=1000)
+    _stream_subscribers.add(queue)
+    backlog = [e for e in _stream_backlog if e[0] > last_id] if last_id else []
+
+    async def events():
+        sent = last_id
+        try:
+            yield "retry: 1000\n\n"
+            for seq, data in backlog:
+                if wanted(data):
+                    yield f"id: {seq}\nevent: log\ndata: {json.dumps(data)}\n\n"
+                sent = seq
+            while True:
+                try:
+                    seq, data = await asyncio.wait_for(queue.get(), timeout=15)
+                except asyncio.TimeoutError:
+                    yield ": keep-alive\n\n"
+                    continue
+                if seq <= sent:
+                    continue
+                sent = seq
+                if wanted(data):
+                    yield f"id: {seq}\nevent: log\ndata: {json.dumps(data)}\n\n"
+        finally:
+            _stream_subscribers.discard(queue)
+
+    return StreamingResponse(
+        events(),
+        media_type="text/event-stream",
+        headers={"Cache-Control": "no-cache"},
+    )
 
 
 @app.get("/health")
 async def health():
-    return {"status": "ok", "db": DB_PATH}
+    return {"status": "ok", "db": DB_PATH, "version": app.version}
+
+
+@app.get("/time")
+async def server_time():
+    """Report the service clock, so clients can measure their skew against it."""
+    return {"time": datetime.now(timezone.utc).isoformat()}
+
+
+API_VERSION = "1"
+
+
+@app.get("/capabilities")
+async def capabilities():
+    """Describe what this service accepts, so clients can downgrade for it."""
+    codecs = ["application/json", "application/x-ndjson"]
+    if msgpack:
+        codecs.append("application/msgpack")
+    return {
+        "api_version": API_VERSION,
+        "fields": list(LogEntry.__fields__),
+        "codecs": codecs,
+        "compression": ["gzip"],  # see GzipRequestMiddleware
+        "batch": True,
+        "query": True,
+        "streaming": True,
+    }
 
 
 # ---------------------------------------------------------------------------